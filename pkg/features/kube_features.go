@@ -209,6 +209,12 @@ const (
 	// based on "structured parameters".
 	DynamicResourceAllocation featuregate.Feature = "DynamicResourceAllocation"
 
+	// owner: @lethezfz
+	//
+	// Enables the DynamicWeight scheduler plugin which scores nodes based on
+	// real-time utilization metrics queried from Prometheus.
+	DynamicWeight featuregate.Feature = "DynamicWeight"
+
 	// owner: @LionelJouin
 	// kep: http://kep.k8s.io/4817
 	//
@@ -22,14 +22,18 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 
 	"k8s.io/klog/v2"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
 	drapbv1alpha4 "k8s.io/kubelet/pkg/apis/dra/v1alpha4"
 	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
 	"k8s.io/kubernetes/pkg/kubelet/metrics"
@@ -52,6 +56,117 @@ func NewDRAPluginClient(pluginName string) (*Plugin, error) {
 	return existingPlugin, nil
 }
 
+// NewDRAPluginClientWithContext is a variant of NewDRAPluginClient for
+// callers which need a plugin whose gRPC connection is known to be usable.
+// It blocks until the connection reaches the Ready state or the context
+// expires, whichever happens first. On timeout it returns an error which
+// wraps the context error.
+func NewDRAPluginClientWithContext(ctx context.Context, pluginName string) (*Plugin, error) {
+	plugin, err := NewDRAPluginClient(pluginName)
+	if err != nil {
+		return nil, err
+	}
+	if err := plugin.waitForConnectionReady(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for gRPC connection of DRA plugin %s: %w", pluginName, err)
+	}
+	return plugin, nil
+}
+
+// waitForConnectionReady blocks until the plugin's gRPC connection reaches
+// the Ready state or the context expires.
+func (p *Plugin) waitForConnectionReady(ctx context.Context) error {
+	conn, err := p.getOrCreateGRPCConn()
+	if err != nil {
+		return err
+	}
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		// Trigger a connection attempt in case the connection is idle,
+		// for example right after creation or after falling back to idle
+		// because of repeated connection failures.
+		conn.Connect()
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("%w: connection did not become ready, last observed state %q: %w", ErrDriverNotReady, state, ctx.Err())
+		}
+	}
+}
+
+// apiVersionCache remembers the DRA API version that actually worked for an
+// endpoint. Keyed by endpoint rather than stored on the Plugin so the result
+// survives connection re-dials and plugin object replacement; without it the
+// Unimplemented probe below would run again after every reconnect.
+var apiVersionCache sync.Map // endpoint -> chosen service
+
+// rememberAPIVersion records the service negotiated for an endpoint. A
+// previously cached entry is only reset when the driver actually reports a
+// different version, e.g. after a driver upgrade re-registers the plugin.
+func rememberAPIVersion(endpoint, service string) {
+	if cached, ok := apiVersionCache.Load(endpoint); ok && cached.(string) == service {
+		return
+	}
+	apiVersionCache.Store(endpoint, service)
+}
+
+// effectiveService returns the service to use for calls, preferring the
+// per-endpoint cache over the service chosen at registration time.
+func (p *Plugin) effectiveService() string {
+	if cached, ok := apiVersionCache.Load(p.endpoint); ok {
+		return cached.(string)
+	}
+	return p.chosenService
+}
+
+// PluginConfig collects the parameters for constructing a Plugin outside of
+// plugin registration.
+type PluginConfig struct {
+	Name          string
+	Endpoint      string
+	ChosenService string
+
+	// ClientCallTimeout falls back to defaultClientCallTimeout when zero.
+	ClientCallTimeout time.Duration
+
+	// DialTimeout falls back to defaultDialTimeout when zero.
+	DialTimeout time.Duration
+
+	// HealthProbeInterval falls back to defaultHealthProbeInterval when
+	// zero.
+	HealthProbeInterval time.Duration
+
+	// Dialer overrides how the endpoint is reached, e.g. to connect to an
+	// in-memory server in tests. The default dials Endpoint as a unix
+	// socket path.
+	Dialer func(ctx context.Context, target string) (net.Conn, error)
+}
+
+// NewPlugin constructs a Plugin without touching the global plugin registry.
+// Production code registers plugins through RegistrationHandler, which also
+// stores them in the registry and starts their background activities; this
+// entry point exists so that the RPC methods can be unit tested in isolation
+// against a local server.
+func NewPlugin(ctx context.Context, config PluginConfig) *Plugin {
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &Plugin{
+		name:                config.Name,
+		backgroundCtx:       ctx,
+		cancel:              cancel,
+		endpoint:            config.Endpoint,
+		chosenService:       config.ChosenService,
+		clientCallTimeout:   config.ClientCallTimeout,
+		dialTimeout:         config.DialTimeout,
+		dialer:              config.Dialer,
+		healthProbeInterval: config.HealthProbeInterval,
+	}
+	if p.clientCallTimeout <= 0 {
+		p.clientCallTimeout = defaultClientCallTimeout
+	}
+	p.healthy.Store(true)
+	return p
+}
+
 type Plugin struct {
 	name          string
 	backgroundCtx context.Context
@@ -62,6 +177,108 @@ type Plugin struct {
 	endpoint          string
 	chosenService     string // e.g. drapbv1beta1.DRAPluginService
 	clientCallTimeout time.Duration
+	dialTimeout       time.Duration
+
+	// dialer overrides how the endpoint is reached. It is nil in
+	// production, where the endpoint is always a unix socket path.
+	dialer func(ctx context.Context, target string) (net.Conn, error)
+
+	// healthProbeInterval determines how often the background liveness
+	// probe runs. Zero falls back to defaultHealthProbeInterval.
+	healthProbeInterval time.Duration
+	// healthy is the result of the most recent health probe. It starts out
+	// true because a freshly registered driver just proved that it is alive
+	// by creating its socket.
+	healthy atomic.Bool
+}
+
+// Healthy reports whether the most recent background health probe of the
+// driver succeeded. Callers can use it to avoid routing work to a driver
+// which is known to be dead.
+func (p *Plugin) Healthy() bool {
+	return p.healthy.Load()
+}
+
+func (p *Plugin) setHealthy(healthy bool) {
+	p.healthy.Store(healthy)
+	value := float64(0)
+	if healthy {
+		value = 1
+	}
+	metrics.DRAGRPCConnectionHealthy.WithLabelValues(p.name).Set(value)
+}
+
+// runHealthProbes periodically checks the driver's gRPC health service and
+// records the result. It runs until backgroundCtx is canceled, i.e. until the
+// plugin gets deregistered or kubelet shuts down.
+func (p *Plugin) runHealthProbes() {
+	interval := p.healthProbeInterval
+	if interval <= 0 {
+		interval = defaultHealthProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.backgroundCtx.Done():
+			return
+		case <-ticker.C:
+			p.setHealthy(p.probeHealth())
+		}
+	}
+}
+
+// probeHealth performs a single health check against the driver. Drivers are
+// not required to implement the gRPC health service; an Unimplemented
+// response still proves that the driver is serving requests and therefore
+// counts as healthy.
+func (p *Plugin) probeHealth() bool {
+	conn, err := p.getOrCreateGRPCConn()
+	if err != nil {
+		return false
+	}
+	p.recordConnectionState(conn.GetState())
+	ctx, cancel := context.WithTimeout(p.backgroundCtx, p.clientCallTimeout)
+	defer cancel()
+	response, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		return true
+	case err != nil:
+		klog.FromContext(p.backgroundCtx).V(4).Info("DRA driver health probe failed", "endpoint", p.endpoint, "err", err)
+		return false
+	default:
+		return response.Status == grpc_health_v1.HealthCheckResponse_SERVING
+	}
+}
+
+// recordConnectionState exposes the connectivity state of the driver's
+// connection as a gauge so that operators can see at a glance which drivers
+// kubelet can't reach.
+func (p *Plugin) recordConnectionState(state connectivity.State) {
+	metrics.DRAGRPCConnectionState.WithLabelValues(p.name).Set(float64(state))
+}
+
+// Close tears down the plugin: it cancels the background context, which
+// stops the health probes and in-flight calls tied to it, and closes the
+// gRPC connection, which aborts any remaining in-flight calls. The plugin
+// remains usable, a subsequent call re-dials the endpoint cleanly.
+func (p *Plugin) Close() error {
+	return p.closeWithCause(errors.New("plugin closed"))
+}
+
+func (p *Plugin) closeWithCause(cause error) error {
+	if p.cancel != nil {
+		p.cancel(cause)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var err error
+	if p.conn != nil {
+		err = p.conn.Close()
+		p.conn = nil
+	}
+	return err
 }
 
 func (p *Plugin) getOrCreateGRPCConn() (*grpc.ClientConn, error) {
@@ -69,112 +286,275 @@ func (p *Plugin) getOrCreateGRPCConn() (*grpc.ClientConn, error) {
 	defer p.mutex.Unlock()
 
 	if p.conn != nil {
-		return p.conn, nil
+		// A cached connection is only usable while it is healthy or can
+		// recover on its own (gRPC re-dials an Idle or Connecting
+		// connection transparently). When the driver pod restarts and its
+		// socket gets recreated, the old connection ends up in
+		// TransientFailure or Shutdown permanently; drop it and dial the
+		// endpoint again instead of failing every call forever.
+		switch state := p.conn.GetState(); state {
+		case connectivity.Ready, connectivity.Idle, connectivity.Connecting:
+			p.recordConnectionState(state)
+			return p.conn, nil
+		default:
+			klog.FromContext(p.backgroundCtx).V(2).Info("Discarding stale gRPC connection", "endpoint", p.endpoint, "state", state)
+			_ = p.conn.Close()
+			p.conn = nil
+		}
 	}
 
 	ctx := p.backgroundCtx
 	logger := klog.FromContext(ctx)
 
 	network := "unix"
+	dialer := p.dialer
+	if dialer == nil {
+		dialer = func(ctx context.Context, target string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, target)
+		}
+	}
 	logger.V(4).Info("Creating new gRPC connection", "protocol", network, "endpoint", p.endpoint)
-	// grpc.Dial is deprecated. grpc.NewClient should be used instead.
-	// For now this gets ignored because this function is meant to establish
-	// the connection, with the one second timeout below. Perhaps that
-	// approach should be reconsidered?
-	//nolint:staticcheck
-	conn, err := grpc.Dial(
-		p.endpoint,
+	// grpc.NewClient does not connect; connection establishment is driven
+	// explicitly below so that callers still get a fast failure within the
+	// dial timeout when the driver socket is absent.
+	// grpc.NewClient defaults to the dns resolver; the passthrough scheme
+	// keeps grpc.Dial's behavior of handing the raw socket path to the
+	// custom dialer below.
+	conn, err := grpc.NewClient(
+		"passthrough:///"+p.endpoint,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithContextDialer(func(ctx context.Context, target string) (net.Conn, error) {
-			return (&net.Dialer{}).DialContext(ctx, network, target)
-		}),
+		grpc.WithContextDialer(dialer),
 		grpc.WithChainUnaryInterceptor(newMetricsInterceptor(p.name)),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	dialTimeout := p.dialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = defaultDialTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
 	defer cancel()
 
-	if ok := conn.WaitForStateChange(ctx, connectivity.Connecting); !ok {
-		return nil, errors.New("timed out waiting for gRPC connection to be ready")
+	// Kick off the first connection attempt and wait until it settles
+	// (Ready or TransientFailure) or the dial timeout expires. As with the
+	// former grpc.Dial call the connection is cached either way: a missing
+	// socket keeps failing fast on each RPC, and waitForConnectionReady
+	// remains available for callers which need a usable connection.
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state != connectivity.Idle && state != connectivity.Connecting {
+			break
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			break
+		}
 	}
 
 	p.conn = conn
+	p.recordConnectionState(conn.GetState())
 	return p.conn, nil
 }
 
-func (p *Plugin) NodePrepareResources(
-	ctx context.Context,
-	req *drapbv1beta1.NodePrepareResourcesRequest,
-	opts ...grpc.CallOption,
-) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+// Sentinel errors which let callers classify failures with [errors.Is]
+// instead of inspecting gRPC status codes themselves. The underlying gRPC
+// error is wrapped, so the original status remains retrievable through
+// [status.FromError].
+var (
+	// ErrDriverNotReady indicates that the driver could not be reached,
+	// e.g. because its socket is gone or the connection did not become
+	// ready in time. Retrying later may succeed.
+	ErrDriverNotReady = errors.New("DRA driver not ready")
+
+	// ErrAPIUnimplemented indicates that the driver implements none of
+	// the supported API versions. Retrying will not help until the
+	// driver re-registers with a different version.
+	ErrAPIUnimplemented = errors.New("DRA driver implements no supported API version")
+
+	// ErrCallTimedOut indicates that the driver did not respond within
+	// the per-call timeout.
+	ErrCallTimedOut = errors.New("DRA driver call timed out")
+)
+
+// classifyRPCError wraps the well-known failure modes of a driver call in
+// the corresponding sentinel error. Other errors pass through unchanged.
+func classifyRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		return fmt.Errorf("%w: %w", ErrAPIUnimplemented, err)
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %w", ErrDriverNotReady, err)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", ErrCallTimedOut, err)
+	}
+	return err
+}
+
+// serviceFallback maps each gRPC service to the next older one to try when
+// the driver reports Unimplemented for a call, i.e. when it advertised a
+// service during registration which it does not actually serve.
+var serviceFallback = map[string]string{
+	drapbv1.DRAPluginService:      drapbv1beta1.DRAPluginService,
+	drapbv1beta1.DRAPluginService: drapbv1alpha4.NodeService,
+}
+
+// rpcCall invokes one specific RPC against one specific gRPC service.
+type rpcCall[Request any, Response any] func(ctx context.Context, conn *grpc.ClientConn, req Request) (Response, error)
+
+// Retry delays for calls which failed with a transient status code. The
+// overall budget remains the per-call timeout through the context deadline,
+// retrying merely rides out short driver restarts within that budget.
+const (
+	initialRetryDelay = 50 * time.Millisecond
+	maxRetryDelay     = 2 * time.Second
+)
+
+// isRetryable reports whether a failed call may be retried: Unavailable
+// (driver mid-restart) and ResourceExhausted are transient. Unimplemented
+// must not be retried because it drives the version fallback, and
+// application errors are final.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// callWithRetry retries a call with exponential backoff for as long as it
+// fails with a transient status code and the context deadline has not been
+// reached. The last error is returned when the budget runs out.
+func callWithRetry[Response any](ctx context.Context, logger klog.Logger, method string, call func() (Response, error)) (Response, error) {
+	delay := initialRetryDelay
+	for {
+		response, err := call()
+		if err == nil || !isRetryable(err) {
+			return response, err
+		}
+		logger.V(3).Info("Retrying rpc after transient failure", "method", method, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return response, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// callWithFallback performs the version negotiation shared by all RPCs: it
+// invokes the RPC against the effective service and, when the driver reports
+// Unimplemented, retries once against the next older service and caches the
+// winner per endpoint. The metrics interceptor is attached to the connection,
+// so timing is recorded for every attempt.
+func callWithFallback[Request any, Response any](ctx context.Context, p *Plugin, method string, req Request, calls map[string]rpcCall[Request, Response]) (Response, error) {
 	logger := klog.FromContext(ctx)
-	logger.V(4).Info("Calling NodePrepareResources rpc", "request", req)
+	logger.V(4).Info("Calling rpc", "method", method, "request", req)
 
+	var response Response
+	// An already canceled caller context would otherwise surface as a
+	// confusing deadline error from the gRPC call below.
+	if err := ctx.Err(); err != nil {
+		return response, err
+	}
 	conn, err := p.getOrCreateGRPCConn()
 	if err != nil {
-		return nil, err
+		return response, err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, p.clientCallTimeout)
 	defer cancel()
 
-	var response *drapbv1beta1.NodePrepareResourcesResponse
-	switch p.chosenService {
-	case drapbv1beta1.DRAPluginService:
-		nodeClient := drapbv1beta1.NewDRAPluginClient(conn)
-		response, err = nodeClient.NodePrepareResources(ctx, req)
-	case drapbv1alpha4.NodeService:
-		nodeClient := drapbv1alpha4.NewNodeClient(conn)
-		response, err = drapbv1alpha4.V1Alpha4ClientWrapper{NodeClient: nodeClient}.NodePrepareResources(ctx, req)
-	default:
+	service := p.effectiveService()
+	call, ok := calls[service]
+	if !ok {
 		// Shouldn't happen, validateSupportedServices should only
 		// return services we support here.
-		return nil, fmt.Errorf("internal error: unsupported chosen service: %q", p.chosenService)
+		return response, fmt.Errorf("internal error: unsupported chosen service: %q", service)
+	}
+	response, err = callWithRetry(ctx, logger, method, func() (Response, error) {
+		return call(ctx, conn, req)
+	})
+	for status.Code(err) == codes.Unimplemented {
+		fallback, haveFallback := serviceFallback[service]
+		if !haveFallback {
+			// The driver serves none of the supported versions;
+			// classifyRPCError turns this into ErrAPIUnimplemented.
+			break
+		}
+		logger.V(3).Info("RPC not implemented, falling back to older API", "method", method, "service", fallback, "endpoint", p.endpoint)
+		rememberAPIVersion(p.endpoint, fallback)
+		service = fallback
+		response, err = callWithRetry(ctx, logger, method, func() (Response, error) {
+			return calls[service](ctx, conn, req)
+		})
 	}
-	logger.V(4).Info("Done calling NodePrepareResources rpc", "response", response, "err", err)
+	err = classifyRPCError(err)
+	logger.V(4).Info("Done calling rpc", "method", method, "response", response, "err", err)
 	return response, err
 }
 
+func (p *Plugin) NodePrepareResources(
+	ctx context.Context,
+	req *drapbv1beta1.NodePrepareResourcesRequest,
+	opts ...grpc.CallOption,
+) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+	return callWithFallback(ctx, p, "NodePrepareResources", req, map[string]rpcCall[*drapbv1beta1.NodePrepareResourcesRequest, *drapbv1beta1.NodePrepareResourcesResponse]{
+		drapbv1.DRAPluginService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+			return drapbv1.NewDRAPluginClient(conn).NodePrepareResources(ctx, req)
+		},
+		drapbv1beta1.DRAPluginService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+			return drapbv1beta1.NewDRAPluginClient(conn).NodePrepareResources(ctx, req)
+		},
+		drapbv1alpha4.NodeService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+			return drapbv1alpha4.V1Alpha4ClientWrapper{NodeClient: drapbv1alpha4.NewNodeClient(conn)}.NodePrepareResources(ctx, req)
+		},
+	})
+}
+
 func (p *Plugin) NodeUnprepareResources(
 	ctx context.Context,
 	req *drapbv1beta1.NodeUnprepareResourcesRequest,
 	opts ...grpc.CallOption,
 ) (*drapbv1beta1.NodeUnprepareResourcesResponse, error) {
-	logger := klog.FromContext(ctx)
-	logger.V(4).Info("Calling NodeUnprepareResource rpc", "request", req)
-
-	conn, err := p.getOrCreateGRPCConn()
-	if err != nil {
-		return nil, err
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, p.clientCallTimeout)
-	defer cancel()
+	return callWithFallback(ctx, p, "NodeUnprepareResources", req, map[string]rpcCall[*drapbv1beta1.NodeUnprepareResourcesRequest, *drapbv1beta1.NodeUnprepareResourcesResponse]{
+		drapbv1.DRAPluginService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodeUnprepareResourcesRequest) (*drapbv1beta1.NodeUnprepareResourcesResponse, error) {
+			return drapbv1.NewDRAPluginClient(conn).NodeUnprepareResources(ctx, req)
+		},
+		drapbv1beta1.DRAPluginService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodeUnprepareResourcesRequest) (*drapbv1beta1.NodeUnprepareResourcesResponse, error) {
+			return drapbv1beta1.NewDRAPluginClient(conn).NodeUnprepareResources(ctx, req)
+		},
+		drapbv1alpha4.NodeService: func(ctx context.Context, conn *grpc.ClientConn, req *drapbv1beta1.NodeUnprepareResourcesRequest) (*drapbv1beta1.NodeUnprepareResourcesResponse, error) {
+			return drapbv1alpha4.V1Alpha4ClientWrapper{NodeClient: drapbv1alpha4.NewNodeClient(conn)}.NodeUnprepareResources(ctx, req)
+		},
+	})
+}
 
-	var response *drapbv1beta1.NodeUnprepareResourcesResponse
-	switch p.chosenService {
-	case drapbv1beta1.DRAPluginService:
-		nodeClient := drapbv1beta1.NewDRAPluginClient(conn)
-		response, err = nodeClient.NodeUnprepareResources(ctx, req)
-	case drapbv1alpha4.NodeService:
-		nodeClient := drapbv1alpha4.NewNodeClient(conn)
-		response, err = drapbv1alpha4.V1Alpha4ClientWrapper{NodeClient: nodeClient}.NodeUnprepareResources(ctx, req)
-	default:
-		// Shouldn't happen, validateSupportedServices should only
-		// return services we support here.
-		return nil, fmt.Errorf("internal error: unsupported chosen service: %q", p.chosenService)
+// messageSize returns the serialized size of a gRPC message in bytes. The
+// generated message types all provide Size; anything else (including nil)
+// counts as zero.
+func messageSize(msg any) float64 {
+	if m, ok := msg.(interface{ Size() int }); ok {
+		return float64(m.Size())
 	}
-	logger.V(4).Info("Done calling NodeUnprepareResources rpc", "response", response, "err", err)
-	return response, err
+	return 0
 }
 
 func newMetricsInterceptor(pluginName string) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
 		start := time.Now()
+		metrics.DRAGRPCMessageSize.WithLabelValues(pluginName, method, "request").Observe(messageSize(req))
 		err := invoker(ctx, method, req, reply, conn, opts...)
+		if err == nil {
+			metrics.DRAGRPCMessageSize.WithLabelValues(pluginName, method, "response").Observe(messageSize(reply))
+		}
 		metrics.DRAGRPCOperationsDuration.WithLabelValues(pluginName, method, status.Code(err).String()).Observe(time.Since(start).Seconds())
 		return err
 	}
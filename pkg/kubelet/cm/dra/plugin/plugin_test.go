@@ -18,6 +18,7 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -25,11 +26,19 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
 	drapbv1alpha4 "k8s.io/kubelet/pkg/apis/dra/v1alpha4"
 	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
+	"k8s.io/kubernetes/pkg/kubelet/metrics"
 	"k8s.io/kubernetes/test/utils/ktesting"
 )
 
@@ -124,12 +133,9 @@ func TestGRPCConnIsReused(t *testing.T) {
 	}
 
 	conn, err := p.getOrCreateGRPCConn()
-	defer func() {
-		err := conn.Close()
-		if err != nil {
-			t.Error(err)
-		}
-	}()
+	// Deregistering the plugin below already closes the connection,
+	// closing it twice reports an error which can be ignored here.
+	defer func() { _ = conn.Close() }()
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -232,6 +238,113 @@ func TestNewDRAPluginClient(t *testing.T) {
 	}
 }
 
+func TestNewDRAPluginClientWithContext(t *testing.T) {
+	service := drapbv1beta1.DRAPluginService
+
+	t.Run("delayed-start", func(t *testing.T) {
+		tCtx := ktesting.Init(t)
+		d, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := os.RemoveAll(d); err != nil {
+				t.Error(err)
+			}
+		}()
+		addr := filepath.Join(d, "server.sock")
+
+		pluginName := "dummy-plugin"
+		p := &Plugin{
+			name:              pluginName,
+			backgroundCtx:     tCtx,
+			endpoint:          addr,
+			chosenService:     service,
+			clientCallTimeout: defaultClientCallTimeout,
+		}
+		draPlugins.add(p)
+		defer draPlugins.delete(pluginName)
+
+		// The socket only starts accepting connections after a delay,
+		// so the client has to wait for the first connection.
+		s := grpc.NewServer()
+		drapbv1beta1.RegisterDRAPluginServer(s, &fakeGRPCServer{})
+		defer s.Stop()
+		listenErr := make(chan error, 1)
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			listener, err := net.Listen("unix", addr)
+			if err != nil {
+				listenErr <- err
+				return
+			}
+			listenErr <- s.Serve(listener)
+		}()
+
+		ctx, cancel := context.WithTimeout(tCtx, 30*time.Second)
+		defer cancel()
+		client, err := NewDRAPluginClientWithContext(ctx, pluginName)
+		if err != nil {
+			select {
+			case err := <-listenErr:
+				t.Fatalf("fake server failed: %v", err)
+			default:
+			}
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := client.conn.Close(); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		// The connection must be usable right away.
+		_, err = client.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		tCtx := ktesting.Init(t)
+		d, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := os.RemoveAll(d); err != nil {
+				t.Error(err)
+			}
+		}()
+
+		// No server ever listens on this endpoint.
+		pluginName := "dummy-plugin"
+		p := &Plugin{
+			name:              pluginName,
+			backgroundCtx:     tCtx,
+			endpoint:          filepath.Join(d, "server.sock"),
+			chosenService:     service,
+			clientCallTimeout: defaultClientCallTimeout,
+		}
+		draPlugins.add(p)
+		defer draPlugins.delete(pluginName)
+		defer func() {
+			p.mutex.Lock()
+			conn := p.conn
+			p.mutex.Unlock()
+			if conn != nil {
+				if err := conn.Close(); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(tCtx, time.Second)
+		defer cancel()
+		client, err := NewDRAPluginClientWithContext(ctx, pluginName)
+		assert.Nil(t, client)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
 func TestGRPCMethods(t *testing.T) {
 	for _, test := range []struct {
 		description   string
@@ -287,12 +400,10 @@ func TestGRPCMethods(t *testing.T) {
 			}
 
 			conn, err := p.getOrCreateGRPCConn()
-			defer func() {
-				err := conn.Close()
-				if err != nil {
-					t.Error(err)
-				}
-			}()
+			// Deregistering the plugin below already closes the
+			// connection, closing it twice reports an error which
+			// can be ignored here.
+			defer func() { _ = conn.Close() }()
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -325,3 +436,989 @@ func assertError(t *testing.T, expectError string, err error) {
 		t.Errorf("Expected error %q, got: %v", expectError, err)
 	}
 }
+
+// startFakeGRPCServerAt serves the v1beta1 DRA service on an existing socket
+// path and returns a function which stops the server abruptly, leaving any
+// established client connections dangling.
+func startFakeGRPCServerAt(addr string) (tearDown, error) {
+	if err := os.RemoveAll(addr); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := grpc.NewServer()
+	drapbv1beta1.RegisterDRAPluginServer(s, &fakeGRPCServer{})
+	go func() {
+		// ErrServerStopped happens when tearDown races with the
+		// server startup, which is fine.
+		if err := s.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			panic(err)
+		}
+	}()
+	return func() {
+		s.Stop()
+		_ = os.RemoveAll(addr)
+	}, nil
+}
+
+func TestGRPCConnReconnectsAfterServerRestart(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	stop, err := startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Plugin{
+		name:              "restarting-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("initial call failed: %v", err)
+	}
+	firstConn := p.conn
+
+	// Kill the server and its socket, then drive the cached connection
+	// into TransientFailure by forcing reconnect attempts against the
+	// removed socket.
+	stop()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		state := firstConn.GetState()
+		if state == connectivity.TransientFailure || state == connectivity.Shutdown {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("connection never became unhealthy, state %q", state)
+		}
+		firstConn.Connect()
+		ctx, cancel := context.WithTimeout(tCtx, time.Second)
+		firstConn.WaitForStateChange(ctx, state)
+		cancel()
+	}
+
+	// Restart the driver on the same endpoint, like a driver pod restart
+	// recreating its socket.
+	stop, err = startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("call after server restart failed: %v", err)
+	}
+	if p.conn == firstConn {
+		t.Error("expected a fresh gRPC connection after the stale one was discarded")
+	}
+	if err := p.conn.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGRPCDialTimeoutConfigurable(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	// Simulate a slow-starting driver: the socket only appears after a
+	// delay longer than the previously hardcoded 1s dial timeout.
+	startDelay := 1500 * time.Millisecond
+	serverUp := make(chan tearDown, 1)
+	go func() {
+		time.Sleep(startDelay)
+		stop, err := startFakeGRPCServerAt(addr)
+		if err != nil {
+			t.Error(err)
+			serverUp <- func() {}
+			return
+		}
+		serverUp <- stop
+	}()
+
+	p := &Plugin{
+		name:              "slow-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+		dialTimeout:       5 * time.Second,
+	}
+
+	if _, err := p.getOrCreateGRPCConn(); err != nil {
+		t.Fatalf("dial with raised timeout failed: %v", err)
+	}
+	// The first RPC only succeeds once the slow driver has come up; with
+	// the raised dial timeout this must not produce a spurious failure.
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	ctx, cancel := context.WithTimeout(tCtx, 10*time.Second)
+	defer cancel()
+	if err := p.waitForConnectionReady(ctx); err != nil {
+		t.Fatalf("connection never became ready: %v", err)
+	}
+	if _, err := p.NodePrepareResources(ctx, req); err != nil {
+		t.Fatalf("call against slow-starting driver failed: %v", err)
+	}
+
+	stop := <-serverUp
+	stop()
+	if err := p.conn.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGRPCConnMissingSocketFailsFast(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A short call timeout keeps the test fast: the call retries
+	// Unavailable until the timeout expires.
+	p := &Plugin{
+		name:              "absent-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          filepath.Join(dir, "does-not-exist.sock"),
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: time.Second,
+		dialTimeout:       time.Second,
+	}
+
+	start := time.Now()
+	if _, err := p.getOrCreateGRPCConn(); err != nil {
+		t.Fatalf("creating the client must not fail even without a socket: %v", err)
+	}
+	// The cached unhealthy connection may get replaced on later calls,
+	// so only the final one needs closing.
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	// The first RPC must fail fast instead of hanging for the full
+	// client call timeout.
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	if _, err := p.NodePrepareResources(tCtx, req); err == nil {
+		t.Fatal("expected the call against a missing socket to fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("failure took %v, expected a fast failure", elapsed)
+	}
+}
+
+// unimplementedV1Beta1Server counts v1beta1 calls and reports them as
+// unimplemented, simulating a driver which advertised a service it does not
+// actually serve.
+type unimplementedV1Beta1Server struct {
+	mutex sync.Mutex
+	calls int
+}
+
+func (f *unimplementedV1Beta1Server) NodePrepareResources(ctx context.Context, in *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls++
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (f *unimplementedV1Beta1Server) NodeUnprepareResources(ctx context.Context, in *drapbv1beta1.NodeUnprepareResourcesRequest) (*drapbv1beta1.NodeUnprepareResourcesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func TestAPIVersionFallbackProbedOnce(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	listener, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	beta := &unimplementedV1Beta1Server{}
+	drapbv1beta1.RegisterDRAPluginServer(s, beta)
+	drapbv1alpha4.RegisterNodeServer(s, drapbv1alpha4.V1Beta1ServerWrapper{DRAPluginServer: &fakeGRPCServer{}})
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			panic(err)
+		}
+	}()
+	defer s.Stop()
+
+	p := &Plugin{
+		name:              "fallback-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+	defer apiVersionCache.Delete(addr)
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+
+	beta.mutex.Lock()
+	calls := beta.calls
+	beta.mutex.Unlock()
+	if calls != 1 {
+		t.Errorf("v1beta1 probed %d times, expected exactly once", calls)
+	}
+
+	// Simulate a connection re-dial: the cached version must survive.
+	p.mutex.Lock()
+	_ = p.conn.Close()
+	p.conn = nil
+	p.mutex.Unlock()
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("call after reconnect failed: %v", err)
+	}
+	beta.mutex.Lock()
+	calls = beta.calls
+	beta.mutex.Unlock()
+	if calls != 1 {
+		t.Errorf("v1beta1 probed %d times after reconnect, expected still once", calls)
+	}
+
+	// A driver re-registering with the same version keeps the cache; a
+	// different version resets it.
+	rememberAPIVersion(addr, drapbv1alpha4.NodeService)
+	if got := p.effectiveService(); got != drapbv1alpha4.NodeService {
+		t.Errorf("effectiveService = %q, expected cached v1alpha4", got)
+	}
+	rememberAPIVersion(addr, drapbv1beta1.DRAPluginService)
+	if got := p.effectiveService(); got != drapbv1beta1.DRAPluginService {
+		t.Errorf("effectiveService = %q, expected reset to v1beta1", got)
+	}
+}
+
+func TestPluginHealthProbe(t *testing.T) {
+	tCtx := ktesting.Init(t)
+	ctx, cancel := context.WithCancel(tCtx)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	teardown, err := startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if teardown != nil {
+			teardown()
+		}
+	}()
+
+	p := &Plugin{
+		name:                "health-plugin",
+		backgroundCtx:       ctx,
+		cancel:              func(error) { cancel() },
+		endpoint:            addr,
+		chosenService:       drapbv1beta1.DRAPluginService,
+		clientCallTimeout:   defaultClientCallTimeout,
+		healthProbeInterval: 20 * time.Millisecond,
+	}
+	p.setHealthy(true)
+	go p.runHealthProbes()
+	defer func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	waitForHealth := func(expected bool, when string) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for p.Healthy() != expected {
+			if time.Now().After(deadline) {
+				t.Fatalf("plugin health did not become %v %s", expected, when)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// The fake server doesn't implement the health service, which still
+	// counts as healthy because the driver is serving requests.
+	waitForHealth(true, "while the server is up")
+
+	teardown()
+	teardown = nil
+	waitForHealth(false, "after the server was stopped")
+
+	teardown, err = startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForHealth(true, "after the server was restarted")
+}
+
+func TestV1OnlyDriver(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	listener, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	// The message types are aliases, so the v1beta1 fake also implements
+	// the v1 service.
+	drapbv1.RegisterDRAPluginServer(s, &fakeGRPCServer{})
+	go func() {
+		if err := s.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			panic(err)
+		}
+	}()
+	defer s.Stop()
+
+	p := &Plugin{
+		name:              "v1-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+	defer apiVersionCache.Delete(addr)
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	if _, err := p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{}); err != nil {
+		t.Fatalf("NodePrepareResources failed: %v", err)
+	}
+	if _, err := p.NodeUnprepareResources(tCtx, &drapbv1beta1.NodeUnprepareResourcesRequest{}); err != nil {
+		t.Fatalf("NodeUnprepareResources failed: %v", err)
+	}
+	if got := p.effectiveService(); got != drapbv1.DRAPluginService {
+		t.Errorf("effectiveService = %q, expected v1 to stick", got)
+	}
+}
+
+func TestV1FallbackToV1Beta1(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	// The driver only serves v1beta1, but registration chose v1.
+	teardown, err := startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	p := &Plugin{
+		name:              "v1beta1-only-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+	defer apiVersionCache.Delete(addr)
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{}); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if got := p.effectiveService(); got != drapbv1beta1.DRAPluginService {
+		t.Errorf("effectiveService = %q, expected cached fallback to v1beta1", got)
+	}
+}
+
+// TestUnprepareFallback verifies that NodeUnprepareResources uses the same
+// negotiation path as NodePrepareResources, including the Unimplemented
+// fallback and the per-endpoint version cache.
+func TestUnprepareFallback(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	// The driver only serves v1alpha4, but registration chose v1beta1.
+	addr, teardown, err := setupFakeGRPCServer(drapbv1alpha4.NodeService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	p := &Plugin{
+		name:              "alpha-only-plugin",
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+	defer apiVersionCache.Delete(addr)
+	defer func() {
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.NodeUnprepareResources(tCtx, &drapbv1beta1.NodeUnprepareResourcesRequest{}); err != nil {
+			t.Fatalf("call %d failed: %v", i, err)
+		}
+	}
+	if got := p.effectiveService(); got != drapbv1alpha4.NodeService {
+		t.Errorf("effectiveService = %q, expected cached fallback to v1alpha4", got)
+	}
+}
+
+// slowGRPCServer delays every response long enough to exceed short per-call
+// timeouts.
+type slowGRPCServer struct {
+	fakeGRPCServer
+	delay time.Duration
+}
+
+func (f *slowGRPCServer) NodePrepareResources(ctx context.Context, in *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(f.delay):
+	}
+	return f.fakeGRPCServer.NodePrepareResources(ctx, in)
+}
+
+func TestErrorClassification(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	newPlugin := func(addr string, callTimeout time.Duration) *Plugin {
+		return &Plugin{
+			name:              "classify-plugin",
+			backgroundCtx:     tCtx,
+			endpoint:          addr,
+			chosenService:     drapbv1.DRAPluginService,
+			clientCallTimeout: callTimeout,
+		}
+	}
+	cleanup := func(p *Plugin) {
+		apiVersionCache.Delete(p.endpoint)
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}
+
+	t.Run("driver-not-ready", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		// No server listens on the socket. The short call timeout
+		// bounds the Unavailable retries.
+		p := newPlugin(filepath.Join(dir, "server.sock"), time.Second)
+		defer cleanup(p)
+		_, err = p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{})
+		if !errors.Is(err, ErrDriverNotReady) {
+			t.Errorf("expected ErrDriverNotReady, got: %v", err)
+		}
+		if code := status.Code(err); code != codes.Unavailable {
+			t.Errorf("expected underlying status %s, got %s: %v", codes.Unavailable, code, err)
+		}
+	})
+
+	t.Run("unimplemented-on-all-versions", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		addr := filepath.Join(dir, "server.sock")
+
+		listener, err := net.Listen("unix", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A server without any registered services rejects every version.
+		s := grpc.NewServer()
+		go func() {
+			if err := s.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+				panic(err)
+			}
+		}()
+		defer s.Stop()
+
+		p := newPlugin(addr, defaultClientCallTimeout)
+		defer cleanup(p)
+		_, err = p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{})
+		if !errors.Is(err, ErrAPIUnimplemented) {
+			t.Errorf("expected ErrAPIUnimplemented, got: %v", err)
+		}
+		if code := status.Code(err); code != codes.Unimplemented {
+			t.Errorf("expected underlying status %s, got %s: %v", codes.Unimplemented, code, err)
+		}
+	})
+
+	t.Run("call-deadline-exceeded", func(t *testing.T) {
+		dir, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		addr := filepath.Join(dir, "server.sock")
+
+		listener, err := net.Listen("unix", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := grpc.NewServer()
+		drapbv1.RegisterDRAPluginServer(s, &slowGRPCServer{delay: 10 * time.Second})
+		go func() {
+			if err := s.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+				panic(err)
+			}
+		}()
+		defer s.Stop()
+
+		p := newPlugin(addr, 100*time.Millisecond)
+		defer cleanup(p)
+		_, err = p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{})
+		if !errors.Is(err, ErrCallTimedOut) {
+			t.Errorf("expected ErrCallTimedOut, got: %v", err)
+		}
+		if code := status.Code(err); code != codes.DeadlineExceeded {
+			t.Errorf("expected underlying status %s, got %s: %v", codes.DeadlineExceeded, code, err)
+		}
+	})
+}
+
+func TestConnectionStateMetric(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	// The gauge is a no-op until it has been registered somewhere.
+	compbasemetrics.NewKubeRegistry().MustRegister(metrics.DRAGRPCConnectionState)
+
+	dir, err := os.MkdirTemp("", "dra_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	addr := filepath.Join(dir, "server.sock")
+
+	teardown, err := startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if teardown != nil {
+			teardown()
+		}
+	}()
+
+	pluginName := "state-metric-plugin"
+	p := &Plugin{
+		name:              pluginName,
+		backgroundCtx:     tCtx,
+		endpoint:          addr,
+		chosenService:     drapbv1beta1.DRAPluginService,
+		clientCallTimeout: defaultClientCallTimeout,
+	}
+	defer func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	gaugeValue := func() float64 {
+		t.Helper()
+		value, err := testutil.GetGaugeMetricValue(metrics.DRAGRPCConnectionState.WithLabelValues(pluginName))
+		if err != nil {
+			t.Fatalf("reading gauge: %v", err)
+		}
+		return value
+	}
+	waitForState := func(expected connectivity.State, when string) {
+		t.Helper()
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			conn, err := p.getOrCreateGRPCConn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// An idle connection does not notice a stopped server by
+			// itself, a connection attempt has to be triggered.
+			conn.Connect()
+			if gaugeValue() == float64(expected) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("gauge did not reach %s %s, last value %v", expected, when, gaugeValue())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(tCtx, 10*time.Second)
+	defer cancel()
+	if err := p.waitForConnectionReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(connectivity.Ready, "while the server is up")
+
+	teardown()
+	teardown = nil
+	waitForState(connectivity.TransientFailure, "after the server was stopped")
+
+	teardown, err = startFakeGRPCServerAt(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForState(connectivity.Ready, "after the server was restarted")
+}
+
+// flakyGRPCServer fails a configurable number of calls with a given status
+// code before succeeding.
+type flakyGRPCServer struct {
+	fakeGRPCServer
+	mutex    sync.Mutex
+	failures int
+	code     codes.Code
+	calls    int
+}
+
+func (f *flakyGRPCServer) NodePrepareResources(ctx context.Context, in *drapbv1beta1.NodePrepareResourcesRequest) (*drapbv1beta1.NodePrepareResourcesResponse, error) {
+	f.mutex.Lock()
+	f.calls++
+	fail := f.calls <= f.failures
+	f.mutex.Unlock()
+	if fail {
+		return nil, status.Error(f.code, "try again")
+	}
+	return f.fakeGRPCServer.NodePrepareResources(ctx, in)
+}
+
+func TestRetryOnUnavailable(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	startServer := func(t *testing.T, server drapbv1beta1.DRAPluginServer) string {
+		t.Helper()
+		dir, err := os.MkdirTemp("", "dra_plugin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		addr := filepath.Join(dir, "server.sock")
+		listener, err := net.Listen("unix", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := grpc.NewServer()
+		drapbv1beta1.RegisterDRAPluginServer(s, server)
+		go func() {
+			if err := s.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+				panic(err)
+			}
+		}()
+		t.Cleanup(s.Stop)
+		return addr
+	}
+	newPlugin := func(t *testing.T, addr string) *Plugin {
+		p := &Plugin{
+			name:              "retry-plugin",
+			backgroundCtx:     tCtx,
+			endpoint:          addr,
+			chosenService:     drapbv1beta1.DRAPluginService,
+			clientCallTimeout: 10 * time.Second,
+		}
+		t.Cleanup(func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if p.conn != nil {
+				_ = p.conn.Close()
+			}
+		})
+		return p
+	}
+
+	t.Run("unavailable-then-success", func(t *testing.T) {
+		server := &flakyGRPCServer{failures: 2, code: codes.Unavailable}
+		p := newPlugin(t, startServer(t, server))
+		if _, err := p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{}); err != nil {
+			t.Fatalf("expected success after retries, got: %v", err)
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		if server.calls != 3 {
+			t.Errorf("expected 3 attempts, got %d", server.calls)
+		}
+	})
+
+	t.Run("application-error-not-retried", func(t *testing.T) {
+		server := &flakyGRPCServer{failures: 1, code: codes.InvalidArgument}
+		p := newPlugin(t, startServer(t, server))
+		if _, err := p.NodePrepareResources(tCtx, &drapbv1beta1.NodePrepareResourcesRequest{}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("expected InvalidArgument, got: %v", err)
+		}
+		server.mutex.Lock()
+		defer server.mutex.Unlock()
+		if server.calls != 1 {
+			t.Errorf("expected 1 attempt, got %d", server.calls)
+		}
+	})
+}
+
+func TestMetricsInterceptorMessageSize(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	// The histogram is a no-op until it has been registered somewhere.
+	compbasemetrics.NewKubeRegistry().MustRegister(metrics.DRAGRPCMessageSize)
+
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	reply := &drapbv1beta1.NodePrepareResourcesResponse{}
+	invoker := func(ctx context.Context, method string, req, reply any, conn *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	interceptor := newMetricsInterceptor("size-plugin")
+	method := "/test.Service/NodePrepareResources"
+	if err := interceptor(tCtx, method, req, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := testutil.GetHistogramMetricValue(metrics.DRAGRPCMessageSize.WithLabelValues("size-plugin", method, "request").(compbasemetrics.ObserverMetric))
+	if err != nil {
+		t.Fatalf("reading histogram: %v", err)
+	}
+	if expected := float64(req.Size()); sum != expected {
+		t.Errorf("expected recorded request size %v, got %v", expected, sum)
+	}
+	if sum <= 0 {
+		t.Errorf("expected a positive request size, got %v", sum)
+	}
+}
+
+// TestNewPlugin exercises the RPC methods on a Plugin constructed directly,
+// without going through registration or the global draPlugins registry, and
+// with an injected dialer so the endpoint name doesn't have to be a real
+// socket path.
+func TestNewPlugin(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	addr, teardown, err := setupFakeGRPCServer(drapbv1beta1.DRAPluginService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	p := NewPlugin(tCtx, PluginConfig{
+		Name:          "standalone-plugin",
+		Endpoint:      "in-memory",
+		ChosenService: drapbv1beta1.DRAPluginService,
+		Dialer: func(ctx context.Context, target string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		},
+	})
+	defer func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	if p.clientCallTimeout != defaultClientCallTimeout {
+		t.Errorf("expected default call timeout, got %v", p.clientCallTimeout)
+	}
+
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("NodePrepareResources failed: %v", err)
+	}
+	if _, err := p.NodeUnprepareResources(tCtx, &drapbv1beta1.NodeUnprepareResourcesRequest{}); err != nil {
+		t.Fatalf("NodeUnprepareResources failed: %v", err)
+	}
+}
+
+func TestCanceledCallerContext(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	addr, teardown, err := setupFakeGRPCServer(drapbv1beta1.DRAPluginService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	p := NewPlugin(tCtx, PluginConfig{
+		Name:          "canceled-plugin",
+		Endpoint:      addr,
+		ChosenService: drapbv1beta1.DRAPluginService,
+	})
+	defer func() {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		if p.conn != nil {
+			_ = p.conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(tCtx)
+	cancel()
+
+	start := time.Now()
+	_, err = p.NodePrepareResources(ctx, &drapbv1beta1.NodePrepareResourcesRequest{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancellation took %v, expected a prompt return", elapsed)
+	}
+
+	// Cancellation during the call must also surface as context.Canceled
+	// instead of being retried.
+	ctx, cancel = context.WithCancel(tCtx)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start = time.Now()
+	_, err = p.NodeUnprepareResources(ctx, &drapbv1beta1.NodeUnprepareResourcesRequest{})
+	// The fake server replies instantly, so the call may win the race and
+	// succeed; only a wrong error classification is a failure.
+	if err != nil && !errors.Is(err, context.Canceled) && status.Code(err) != codes.Canceled {
+		t.Errorf("expected success or context.Canceled, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %v, expected a prompt return", elapsed)
+	}
+}
+
+func TestPluginClose(t *testing.T) {
+	tCtx := ktesting.Init(t)
+
+	addr, teardown, err := setupFakeGRPCServer(drapbv1beta1.DRAPluginService)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer teardown()
+
+	p := NewPlugin(tCtx, PluginConfig{
+		Name:          "close-plugin",
+		Endpoint:      addr,
+		ChosenService: drapbv1beta1.DRAPluginService,
+	})
+	defer func() { _ = p.Close() }()
+
+	req := &drapbv1beta1.NodePrepareResourcesRequest{
+		Claims: []*drapbv1beta1.Claim{
+			{Namespace: "dummy-namespace", UID: "dummy-uid", Name: "dummy-claim"},
+		},
+	}
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("call before Close failed: %v", err)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	p.mutex.Lock()
+	conn := p.conn
+	p.mutex.Unlock()
+	if conn != nil {
+		t.Fatal("expected the connection to be gone after Close")
+	}
+	select {
+	case <-p.backgroundCtx.Done():
+	default:
+		t.Fatal("expected the background context to be canceled after Close")
+	}
+
+	// The plugin re-dials cleanly when used again.
+	if _, err := p.NodePrepareResources(tCtx, req); err != nil {
+		t.Fatalf("call after Close failed: %v", err)
+	}
+
+	// Closing twice is harmless.
+	if err := p.Close(); err != nil {
+		t.Fatalf("second Close failed: %v", err)
+	}
+}
@@ -53,8 +53,8 @@ func (s *pluginsStore) add(p *Plugin) (replacedPlugin *Plugin, replaced bool) {
 	replacedPlugin, exists := s.store[p.name]
 	s.store[p.name] = p
 
-	if replacedPlugin != nil && replacedPlugin.cancel != nil {
-		replacedPlugin.cancel(errors.New("plugin got replaced"))
+	if replacedPlugin != nil {
+		_ = replacedPlugin.closeWithCause(errors.New("plugin got replaced"))
 	}
 
 	return replacedPlugin, exists
@@ -70,9 +70,7 @@ func (s *pluginsStore) delete(pluginName string) *Plugin {
 	if !exists {
 		return nil
 	}
-	if p.cancel != nil {
-		p.cancel(errors.New("plugin got removed"))
-	}
+	_ = p.closeWithCause(errors.New("plugin got removed"))
 	delete(s.store, pluginName)
 
 	return p
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	drapbv1 "k8s.io/kubelet/pkg/apis/dra/v1"
 	drapbv1alpha4 "k8s.io/kubelet/pkg/apis/dra/v1alpha4"
 	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
 	"k8s.io/kubernetes/pkg/kubelet/pluginmanager/cache"
@@ -45,6 +46,17 @@ import (
 // https://github.com/kubernetes/kubernetes/commit/0449cef8fd5217d394c5cd331d852bd50983e6b3).
 const defaultClientCallTimeout = 45 * time.Second
 
+// defaultDialTimeout is the default amount of time that establishing a gRPC
+// connection to a freshly registered plugin may take. A slow-starting driver
+// on a loaded node can exceed one second, so the value is plumbed through
+// Plugin for tests and future configuration rather than hardcoded at the
+// dial site.
+const defaultDialTimeout = time.Second
+
+// defaultHealthProbeInterval is how often the background liveness probe of a
+// registered plugin checks the driver's gRPC health service.
+const defaultHealthProbeInterval = 30 * time.Second
+
 // RegistrationHandler is the handler which is fed to the pluginwatcher API.
 type RegistrationHandler struct {
 	// backgroundCtx is used for all future activities of the handler.
@@ -165,17 +177,20 @@ func (h *RegistrationHandler) RegisterPlugin(pluginName string, endpoint string,
 		timeout = *pluginClientTimeout
 	}
 
-	ctx, cancel := context.WithCancelCause(ctx)
-
-	pluginInstance := &Plugin{
-		name:              pluginName,
-		backgroundCtx:     ctx,
-		cancel:            cancel,
-		conn:              nil,
-		endpoint:          endpoint,
-		chosenService:     chosenService,
-		clientCallTimeout: timeout,
-	}
+	// A re-registering driver may report a different API version (e.g.
+	// after an upgrade); only then is the cached negotiation result reset.
+	rememberAPIVersion(endpoint, chosenService)
+
+	pluginInstance := NewPlugin(ctx, PluginConfig{
+		Name:                pluginName,
+		Endpoint:            endpoint,
+		ChosenService:       chosenService,
+		ClientCallTimeout:   timeout,
+		DialTimeout:         defaultDialTimeout,
+		HealthProbeInterval: defaultHealthProbeInterval,
+	})
+	pluginInstance.setHealthy(true)
+	go pluginInstance.runHealthProbes()
 
 	// Storing endpoint of newly registered DRA Plugin into the map, where plugin name will be the key
 	// all other DRA components will be able to get the actual socket of DRA plugins by its name.
@@ -200,6 +215,7 @@ func (h *RegistrationHandler) validateSupportedServices(pluginName string, suppo
 	chosenService := ""
 	for _, service := range []string{
 		// Sorted by most recent first, oldest last.
+		drapbv1.DRAPluginService,
 		drapbv1beta1.DRAPluginService,
 		drapbv1alpha4.NodeService,
 	} {
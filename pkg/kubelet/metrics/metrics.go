@@ -138,6 +138,9 @@ const (
 	// Metric keys for DRA operations
 	DRAOperationsDurationKey     = "operations_duration_seconds"
 	DRAGRPCOperationsDurationKey = "grpc_operations_duration_seconds"
+	DRAGRPCConnectionHealthyKey  = "grpc_connection_healthy"
+	DRAGRPCConnectionStateKey    = "grpc_connection_state"
+	DRAGRPCMessageSizeKey        = "grpc_message_size_bytes"
 
 	// Values used in metric labels
 	Container          = "container"
@@ -998,6 +1001,46 @@ var (
 		[]string{"driver_name", "method_name", "grpc_status_code"},
 	)
 
+	// DRAGRPCConnectionHealthy reports the result of the most recent health
+	// probe of the gRPC connection to a DRA driver: 1 when healthy, 0 when not.
+	DRAGRPCConnectionHealthy = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      DRASubsystem,
+			Name:           DRAGRPCConnectionHealthyKey,
+			Help:           "Whether the gRPC connection to the DRA driver passed its most recent health probe (1) or not (0).",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+
+	// DRAGRPCMessageSize tracks the serialized size of DRA gRPC request and
+	// response messages. Unusually large ResourceClaim payloads slow down
+	// preparation, this makes them visible.
+	DRAGRPCMessageSize = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      DRASubsystem,
+			Name:           DRAGRPCMessageSizeKey,
+			Help:           "Serialized size in bytes of DRA gRPC request and response messages.",
+			Buckets:        metrics.ExponentialBuckets(64, 4, 8),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name", "method_name", "direction"},
+	)
+
+	// DRAGRPCConnectionState reports the current connectivity state of the
+	// gRPC connection to a DRA driver, using the numeric values of
+	// grpc/connectivity.State: 0 Idle, 1 Connecting, 2 Ready,
+	// 3 TransientFailure, 4 Shutdown.
+	DRAGRPCConnectionState = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      DRASubsystem,
+			Name:           DRAGRPCConnectionStateKey,
+			Help:           "Current connectivity state of the gRPC connection to the DRA driver: 0 Idle, 1 Connecting, 2 Ready, 3 TransientFailure, 4 Shutdown.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"driver_name"},
+	)
+
 	// AdmissionRejectionsTotal tracks the number of failed admission times, currently, just record it for pod additions
 	AdmissionRejectionsTotal = metrics.NewCounterVec(
 		&metrics.CounterOpts{
@@ -1104,6 +1147,9 @@ func Register(collectors ...metrics.StableCollector) {
 		if utilfeature.DefaultFeatureGate.Enabled(features.DynamicResourceAllocation) {
 			legacyregistry.MustRegister(DRAOperationsDuration)
 			legacyregistry.MustRegister(DRAGRPCOperationsDuration)
+			legacyregistry.MustRegister(DRAGRPCConnectionHealthy)
+			legacyregistry.MustRegister(DRAGRPCConnectionState)
+			legacyregistry.MustRegister(DRAGRPCMessageSize)
 		}
 
 		legacyregistry.MustRegister(AdmissionRejectionsTotal)
@@ -0,0 +1,138 @@
+// pkg/scheduler/framework/plugins/dynamicweight/breaker.go
+package dynamicweight
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// 熔断参数：窗口内连续失败达到阈值后打开，冷却期满放行一次探测查询
+// Prometheus故障期间查询要等满超时才失败，熔断后直接短路，
+// 避免后台刷新与批量预取在故障期反复撞超时拖慢调度
+const (
+	breakerFailureThreshold = 5
+	breakerFailureWindow    = time.Minute
+	breakerCooldown         = 30 * time.Second
+)
+
+// 熔断器状态取值（同时作为breakerState仪表的值）
+const (
+	breakerClosed   = 0 // 正常：查询放行
+	breakerOpen     = 1 // 熔断：查询直接短路
+	breakerHalfOpen = 2 // 半开：放行一次探测查询验证恢复
+)
+
+// errBreakerOpen 熔断打开时查询路径返回的哨兵错误
+// 调用方（缓存刷新/批量预取）按普通查询失败降级，Score走中性分兜底
+var errBreakerOpen = errors.New("熔断器打开，跳过Prometheus查询")
+
+// circuitBreaker Prometheus查询熔断器
+// 按查询轮次粒度计数：一轮节点指标拉取整体成功或失败各记一次
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	threshold int           // 连续失败阈值
+	window    time.Duration // 失败计数窗口：超窗后计数清零重新累计
+	cooldown  time.Duration // 熔断冷却时长：冷却期满转半开探测
+
+	// 时钟注入点，测试可注入固定时钟
+	now func() time.Time
+
+	state          int
+	failures       int       // 窗口内的连续失败次数
+	firstFailureAt time.Time // 本轮连续失败的起点
+	openedAt       time.Time // 最近一次打开的时刻
+	probeInFlight  bool      // 半开状态下探测查询是否已放行
+}
+
+// newCircuitBreaker 按默认参数构造熔断器
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		threshold: breakerFailureThreshold,
+		window:    breakerFailureWindow,
+		cooldown:  breakerCooldown,
+		now:       time.Now,
+	}
+}
+
+// allow 查询发起前调用，报告本次查询是否放行
+// 打开状态下冷却期满自动转半开并放行一次探测；半开期间其余查询继续短路
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		// 冷却期满：转半开，放行一次探测
+		b.setState(breakerHalfOpen)
+		b.probeInFlight = true
+		return true
+	default: // breakerHalfOpen
+		if b.probeInFlight {
+			return false // 探测结果未出，其余查询继续短路
+		}
+		b.probeInFlight = true
+		return true
+	}
+}
+
+// onSuccess 查询轮次成功后调用
+// 半开状态的探测成功即认为后端恢复，关闭熔断并清零计数
+func (b *circuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probeInFlight = false
+	if b.state != breakerClosed {
+		b.setState(breakerClosed)
+	}
+}
+
+// onFailure 查询轮次失败后调用
+// 半开状态的探测失败立即重新打开；关闭状态按窗口累计连续失败
+func (b *circuitBreaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if b.state == breakerHalfOpen {
+		b.probeInFlight = false
+		b.openedAt = now
+		b.setState(breakerOpen)
+		return
+	}
+	if b.state != breakerClosed {
+		return
+	}
+
+	// 距上次失败超窗后重新累计，避免零星失败长期累加误熔断
+	if b.failures == 0 || now.Sub(b.firstFailureAt) > b.window {
+		b.failures = 0
+		b.firstFailureAt = now
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = now
+		b.setState(breakerOpen)
+	}
+}
+
+// setState 切换状态并同步仪表；调用方需持有锁
+func (b *circuitBreaker) setState(state int) {
+	b.state = state
+	breakerState.Set(float64(state))
+}
+
+// currentState 读取当前状态（测试与健康检查用）
+func (b *circuitBreaker) currentState() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
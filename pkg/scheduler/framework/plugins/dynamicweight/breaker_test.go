@@ -0,0 +1,173 @@
+// pkg/scheduler/framework/plugins/dynamicweight/breaker_test.go
+package dynamicweight
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// newTestBreaker 构造注入假时钟的熔断器，返回熔断器与拨动时钟的函数
+func newTestBreaker() (*circuitBreaker, func(d time.Duration)) {
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker()
+	b.now = func() time.Time { return now }
+	advance := func(d time.Duration) { now = now.Add(d) }
+	return b, advance
+}
+
+// TestBreakerOpensAfterConsecutiveFailures 窗口内连续失败达到阈值后熔断打开
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b, _ := newTestBreaker()
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		if !b.allow() {
+			t.Fatalf("第%d次失败前查询应放行", i+1)
+		}
+		b.onFailure()
+	}
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("阈值前状态 = %d, 期望保持关闭", got)
+	}
+
+	b.onFailure()
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("达到阈值后状态 = %d, 期望打开", got)
+	}
+	if b.allow() {
+		t.Errorf("熔断打开后冷却期内查询应短路")
+	}
+}
+
+// TestBreakerWindowResetsFailureCount 失败间隔超窗后计数清零，零星失败不应累计成熔断
+func TestBreakerWindowResetsFailureCount(t *testing.T) {
+	b, advance := newTestBreaker()
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		b.onFailure()
+	}
+	// 超窗后再失败：计数应重新从1开始累计
+	advance(breakerFailureWindow + time.Second)
+	b.onFailure()
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("超窗后的失败不应触发熔断, 状态 = %d", got)
+	}
+
+	// 在新窗口内补足阈值才应打开
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		b.onFailure()
+	}
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("新窗口内达到阈值后状态 = %d, 期望打开", got)
+	}
+}
+
+// TestBreakerSuccessResetsFailureCount 成功清零连续失败计数
+func TestBreakerSuccessResetsFailureCount(t *testing.T) {
+	b, _ := newTestBreaker()
+
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		b.onFailure()
+	}
+	b.onSuccess()
+	for i := 0; i < breakerFailureThreshold-1; i++ {
+		b.onFailure()
+	}
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("成功后重新累计未达阈值, 状态 = %d, 期望关闭", got)
+	}
+}
+
+// TestBreakerHalfOpenProbe 冷却期满转半开放行单次探测，探测结果决定关闭或重新打开
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	b, advance := newTestBreaker()
+	for i := 0; i < breakerFailureThreshold; i++ {
+		b.onFailure()
+	}
+
+	// 冷却期内继续短路
+	advance(breakerCooldown - time.Second)
+	if b.allow() {
+		t.Fatalf("冷却期未满查询应短路")
+	}
+
+	// 冷却期满：放行一次探测，探测结果未出时其余查询仍短路
+	advance(2 * time.Second)
+	if !b.allow() {
+		t.Fatalf("冷却期满应放行探测查询")
+	}
+	if got := b.currentState(); got != breakerHalfOpen {
+		t.Fatalf("探测放行后状态 = %d, 期望半开", got)
+	}
+	if b.allow() {
+		t.Errorf("探测结果未出时其余查询应短路")
+	}
+
+	// 探测失败：重新打开并重置冷却
+	b.onFailure()
+	if got := b.currentState(); got != breakerOpen {
+		t.Fatalf("探测失败后状态 = %d, 期望重新打开", got)
+	}
+	if b.allow() {
+		t.Errorf("重新打开后冷却期内查询应短路")
+	}
+
+	// 再次冷却期满，这次探测成功：熔断关闭，查询恢复放行
+	advance(breakerCooldown + time.Second)
+	if !b.allow() {
+		t.Fatalf("第二次冷却期满应放行探测查询")
+	}
+	b.onSuccess()
+	if got := b.currentState(); got != breakerClosed {
+		t.Fatalf("探测成功后状态 = %d, 期望关闭", got)
+	}
+	if !b.allow() {
+		t.Errorf("熔断关闭后查询应放行")
+	}
+}
+
+// TestBreakerStateMetric 状态切换应同步breakerState仪表
+func TestBreakerStateMetric(t *testing.T) {
+	registerMetrics()
+	b, advance := newTestBreaker()
+
+	for i := 0; i < breakerFailureThreshold; i++ {
+		b.onFailure()
+	}
+	if got, err := testutil.GetGaugeMetricValue(breakerState); err != nil || got != float64(breakerOpen) {
+		t.Errorf("打开后仪表值 = %v (err=%v), 期望 %d", got, err, breakerOpen)
+	}
+
+	advance(breakerCooldown + time.Second)
+	b.allow()
+	if got, err := testutil.GetGaugeMetricValue(breakerState); err != nil || got != float64(breakerHalfOpen) {
+		t.Errorf("半开后仪表值 = %v (err=%v), 期望 %d", got, err, breakerHalfOpen)
+	}
+
+	b.onSuccess()
+	if got, err := testutil.GetGaugeMetricValue(breakerState); err != nil || got != float64(breakerClosed) {
+		t.Errorf("关闭后仪表值 = %v (err=%v), 期望 %d", got, err, breakerClosed)
+	}
+}
+
+// TestQueryShortCircuitsWhenOpen 熔断打开时两条查询路径都直接返回哨兵错误，不发起查询
+func TestQueryShortCircuitsWhenOpen(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient:   &fakePromAPI{},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+		breaker:      newCircuitBreaker(),
+	}
+	for i := 0; i < breakerFailureThreshold; i++ {
+		d.breaker.onFailure()
+	}
+
+	if _, _, err := d.queryUsageMetrics(context.Background(), "10.0.0.1", nil, nil); err != errBreakerOpen {
+		t.Errorf("熔断打开时queryUsageMetrics错误 = %v, 期望 %v", err, errBreakerOpen)
+	}
+	if _, err := d.batchQueryNodeUsage(context.Background(), []string{`"10.0.0.1"`}, map[string]string{"10.0.0.1": "node-a"}); err != errBreakerOpen {
+		t.Errorf("熔断打开时batchQueryNodeUsage错误 = %v, 期望 %v", err, errBreakerOpen)
+	}
+}
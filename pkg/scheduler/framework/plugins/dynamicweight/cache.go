@@ -2,63 +2,348 @@
 package dynamicweight
 
 import (
+	"context"
 	"sync"
 	"time"
 )
 
+// 每批删除的过期条目数：分批短暂持锁，避免清理时长时间阻塞Score
+const gcBatchSize = 100
+
 // NodeUsageCache 节点资源使用率缓存结构
 // 目的：缓存节点的实时资源使用率数据，减少对Prometheus的频繁查询，提高调度性能
 // 设计要点：
-//  1. 线程安全：通过读写锁（RWMutex）保障并发访问安全
+//  1. 线程安全：通过互斥锁保障并发访问安全
 //  2. 数据时效性：设置缓存超时时间，避免使用过时数据
-//  3. 内存高效：自动清理过期缓存项
+//  3. 内存有界：超过maxEntries时按LRU淘汰最久未访问的条目
 type NodeUsageCache struct {
-	data    map[string]*NodeUsage // 缓存存储（节点名称 -> 使用率数据）
-	mu      sync.RWMutex          // 读写锁（保障线程安全）
-	timeout time.Duration         // 缓存超时时间（例如5分钟）
+	data       map[string]*cacheEntry // 缓存存储（节点名称 -> 条目）
+	mu         sync.Mutex             // 互斥锁（保障线程安全，Get需更新访问时间）
+	timeout    time.Duration          // 缓存超时时间（例如5分钟）
+	maxEntries int                    // 最大条目数，<=0表示不限制
+}
+
+// cacheEntry 缓存条目：使用率数据 + 最近访问时间（LRU淘汰依据）
+type cacheEntry struct {
+	usage      *NodeUsage
+	lastAccess time.Time
 }
 
 // NewNodeUsageCache 创建新的缓存实例
 // 参数：
 //   - timeout: 缓存有效时长（例如5*time.Minute）
+//   - maxEntries: 最大条目数，超过时按LRU淘汰；<=0表示不限制
 //
 // 返回：
 //   - 初始化后的缓存指针
-func NewNodeUsageCache(timeout time.Duration) *NodeUsageCache {
+func NewNodeUsageCache(timeout time.Duration, maxEntries int) *NodeUsageCache {
 	return &NodeUsageCache{
-		data:    make(map[string]*NodeUsage),
-		timeout: timeout,
+		data:       make(map[string]*cacheEntry),
+		timeout:    timeout,
+		maxEntries: maxEntries,
 	}
 }
 
 // Get 获取指定节点的缓存数据
 // 流程：
-//  1. 加读锁（允许并发读）
+//  1. 加锁（需要更新LRU访问时间，不能用读锁）
 //  2. 检查是否存在未过期的缓存
 //  3. 返回数据或nil
 func (c *NodeUsageCache) Get(node string) *NodeUsage {
-	c.mu.RLock()         // 加读锁
-	defer c.mu.RUnlock() // 函数返回时释放锁
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	if entry, ok := c.data[node]; ok {
 		// 检查缓存是否过期
-		if time.Since(entry.Timestamp) < c.timeout {
-			return entry // 返回未过期的有效数据
+		if time.Since(entry.usage.Timestamp) < c.timeout {
+			entry.lastAccess = time.Now() // 刷新LRU访问时间
+			return entry.usage            // 返回未过期的有效数据
 		}
 	}
 	return nil // 无有效缓存
 }
 
-// Set 更新节点缓存数据（线程安全）
+// Set 更新节点缓存数据（线程安全，整条覆盖）
+// 写入方提供了按维度采样时刻时沿用，否则统一打当前时间戳
+// 按维度的增量刷新见UpdateResources
 // 流程：
-//  1. 加写锁（阻塞其他读写操作）
+//  1. 加锁
 //  2. 更新数据并记录当前时间戳
+//  3. 超出容量上限时淘汰最久未访问的条目
 func (c *NodeUsageCache) Set(node string, usage *NodeUsage) {
-	c.mu.Lock()         // 加写锁
-	defer c.mu.Unlock() // 函数返回时释放锁
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(node, usage)
+}
+
+// SetIfOlder 仅当节点无缓存条目、或条目数据早于cutoff时写入
+// 批量预热（WarmAll）落盘时使用：预热查询期间逐节点路径可能已写入
+// 更新鲜的数据，不应被批量结果覆盖。返回是否实际写入
+func (c *NodeUsageCache) SetIfOlder(node string, usage *NodeUsage, cutoff time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.data[node]; ok && !entry.usage.Timestamp.Before(cutoff) {
+		return false // 已有cutoff之后写入的数据，保留
+	}
+	c.setLocked(node, usage)
+	return true
+}
+
+// setLocked 写入条目并维护时间戳/LRU/容量上限（调用方必须持有锁）
+// 各维度时间戳的来源分三种情况：
+//   - 写入方未提供采样时刻（旧式整条写入）：全部维度统一打当前时间
+//   - 写入方提供了采样时刻的维度：沿用各自的采样完成时刻
+//   - 有采样时刻映射但该维度缺席（查询失败被降级默认值顶替）：直接
+//     标记为已过期，由下一轮刷新的增量路径优先重查
+func (c *NodeUsageCache) setLocked(node string, usage *NodeUsage) {
+	now := time.Now()
+	usage.Timestamp = now // 记录更新时间戳
+	if usage.Metrics != nil {
+		provided := usage.ResourceTimestamps
+		usage.ResourceTimestamps = make(map[string]time.Time, len(usage.Metrics))
+		for res := range usage.Metrics {
+			switch {
+			case len(provided) == 0:
+				usage.ResourceTimestamps[res] = now
+			case !provided[res].IsZero():
+				usage.ResourceTimestamps[res] = provided[res]
+			default:
+				usage.ResourceTimestamps[res] = now.Add(-c.timeout)
+			}
+		}
+	}
+	c.data[node] = &cacheEntry{usage: usage, lastAccess: now}
+
+	// 超出上限时按LRU淘汰（新写入的条目不会被淘汰）
+	for c.maxEntries > 0 && len(c.data) > c.maxEntries {
+		c.evictOldestLocked()
+	}
+	cacheSize.Set(float64(len(c.data)))
+}
+
+// GetWithStale 返回节点的缓存数据及其中已过期的资源维度
+// 与Get不同：只要还有未过期的维度就返回数据，由调用方只重查过期维度
+// 返回(nil, nil)表示无条目或全部维度过期（需整条重建）
+func (c *NodeUsageCache) GetWithStale(node string) (*NodeUsage, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.data[node]
+	if !ok {
+		return nil, nil
+	}
+	now := time.Now()
+
+	// 无按维度时间戳（旧式整条写入）：退化为整条过期判断
+	if len(entry.usage.ResourceTimestamps) == 0 {
+		if now.Sub(entry.usage.Timestamp) >= c.timeout {
+			return nil, nil
+		}
+		entry.lastAccess = now
+		return entry.usage, nil
+	}
 
-	usage.Timestamp = time.Now() // 记录更新时间戳
-	c.data[node] = usage         // 存储或更新数据
+	var stale []string
+	for res, ts := range entry.usage.ResourceTimestamps {
+		if now.Sub(ts) >= c.timeout {
+			stale = append(stale, res)
+		}
+	}
+	if len(stale) == len(entry.usage.ResourceTimestamps) {
+		return nil, nil // 全部过期，整条重建
+	}
+	entry.lastAccess = now
+	return entry.usage, stale
+}
+
+// UpdateResources 将指定维度的新值合并进已有条目（按维度刷新时间戳）
+// sampledAt为各维度的采样完成时刻：缺席的维度（重查仍未采到样本，
+// 降级值顶替）保持过期标记，下一轮继续重试；nil表示旧式调用，统一
+// 按合并时刻打时间戳。条目不存在（已被删除/淘汰）时返回nil，调用方
+// 应整条重建
+//
+// 写时复制：Get/GetWithStale把usage指针交出后由Score等路径无锁读取，
+// 原地改写映射会与读者竞态；合并进全新副本后整体替换条目指针
+func (c *NodeUsageCache) UpdateResources(node string, values map[string]float64, sampledAt map[string]time.Time) *NodeUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.data[node]
+	if !ok {
+		return nil
+	}
+	now := time.Now()
+	old := entry.usage
+	usage := *old
+	usage.Metrics = make(map[string]float64, len(old.Metrics)+len(values))
+	for res, v := range old.Metrics {
+		usage.Metrics[res] = v
+	}
+	usage.ResourceTimestamps = make(map[string]time.Time, len(old.ResourceTimestamps)+len(values))
+	for res, ts := range old.ResourceTimestamps {
+		usage.ResourceTimestamps[res] = ts
+	}
+	for res, v := range values {
+		usage.Metrics[res] = v
+		usage.setNamed(res, v)
+		switch {
+		case sampledAt == nil:
+			usage.ResourceTimestamps[res] = now
+		case !sampledAt[res].IsZero():
+			usage.ResourceTimestamps[res] = sampledAt[res]
+		default:
+			usage.ResourceTimestamps[res] = now.Add(-c.timeout)
+		}
+	}
+
+	// 任一网络方向更新后重算合计值，采样时刻取两方向中较早者
+	if rx, ok := usage.Metrics["netrx"]; ok {
+		if tx, ok := usage.Metrics["nettx"]; ok {
+			usage.Metrics["netio"] = rx + tx
+			usage.Network = rx + tx
+			rxTs, txTs := usage.ResourceTimestamps["netrx"], usage.ResourceTimestamps["nettx"]
+			if txTs.Before(rxTs) {
+				usage.ResourceTimestamps["netio"] = txTs
+			} else {
+				usage.ResourceTimestamps["netio"] = rxTs
+			}
+		}
+	}
+
+	// 条目整体时间戳取最老的未过期维度，整条过期判断保持保守；
+	// 已标记过期的降级维度由增量刷新持续重试，不把整条拖成过期
+	oldest := now
+	for _, ts := range usage.ResourceTimestamps {
+		if now.Sub(ts) >= c.timeout {
+			continue
+		}
+		if ts.Before(oldest) {
+			oldest = ts
+		}
+	}
+	usage.Timestamp = oldest
+	entry.usage = &usage
+	entry.lastAccess = now
+	return &usage
+}
+
+// Snapshot 返回全部缓存条目的拷贝（调试端点用）
+// 不刷新LRU访问时间，也不过滤过期条目：排障时过期数据同样有参考价值
+func (c *NodeUsageCache) Snapshot() map[string]*NodeUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]*NodeUsage, len(c.data))
+	for node, entry := range c.data {
+		copied := *entry.usage
+		if entry.usage.Metrics != nil {
+			copied.Metrics = make(map[string]float64, len(entry.usage.Metrics))
+			for res, v := range entry.usage.Metrics {
+				copied.Metrics[res] = v
+			}
+		}
+		if entry.usage.ResourceTimestamps != nil {
+			copied.ResourceTimestamps = make(map[string]time.Time, len(entry.usage.ResourceTimestamps))
+			for res, ts := range entry.usage.ResourceTimestamps {
+				copied.ResourceTimestamps[res] = ts
+			}
+		}
+		snapshot[node] = &copied
+	}
+	return snapshot
+}
+
+// Delete 删除指定节点的缓存条目（节点下线时由informer回调触发）
+func (c *NodeUsageCache) Delete(node string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, node)
+	cacheSize.Set(float64(len(c.data)))
+}
+
+// Invalidate 立即作废指定节点的缓存条目，不等TTL自然过期
+// 节点封锁/排空或指标抓取目标变化时旧数据已不可信，由informer回调触发
+func (c *NodeUsageCache) Invalidate(node string) {
+	c.Delete(node)
+}
+
+// InvalidateAll 清空全部缓存条目
+// 查询语义整体变化（如指标来源切换）时使用，下一轮评分按需回源重建
+func (c *NodeUsageCache) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data = make(map[string]*cacheEntry)
+	cacheSize.Set(0)
+}
+
+// Len 返回当前缓存条目数
+func (c *NodeUsageCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.data)
+}
+
+// StartGC 启动后台清理协程，定期删除已过期的缓存条目
+// Get只在读取时惰性判断过期，少被调度的节点条目会常驻内存，
+// 由本协程兜底回收；ctx取消（插件关闭）时协程退出
+func (c *NodeUsageCache) StartGC(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.sweep()
+			}
+		}
+	}()
+}
+
+// sweep 扫描并删除过期条目
+// 先在锁内快速收集过期键，再分批删除，每批短暂持锁
+func (c *NodeUsageCache) sweep() {
+	// 第一步：收集过期键
+	c.mu.Lock()
+	var expired []string
+	for node, entry := range c.data {
+		if time.Since(entry.usage.Timestamp) >= c.timeout {
+			expired = append(expired, node)
+		}
+	}
+	c.mu.Unlock()
+
+	// 第二步：分批删除；重新检查时间戳，防止收集后条目被刷新
+	for start := 0; start < len(expired); start += gcBatchSize {
+		end := min(start+gcBatchSize, len(expired))
+		c.mu.Lock()
+		for _, node := range expired[start:end] {
+			if entry, ok := c.data[node]; ok && time.Since(entry.usage.Timestamp) >= c.timeout {
+				delete(c.data, node)
+			}
+		}
+		cacheSize.Set(float64(len(c.data)))
+		c.mu.Unlock()
+	}
+}
+
+// evictOldestLocked 淘汰最久未访问的条目（调用方必须持有锁）
+func (c *NodeUsageCache) evictOldestLocked() {
+	var oldestNode string
+	var oldestAccess time.Time
+	for node, entry := range c.data {
+		if oldestNode == "" || entry.lastAccess.Before(oldestAccess) {
+			oldestNode = node
+			oldestAccess = entry.lastAccess
+		}
+	}
+	if oldestNode != "" {
+		delete(c.data, oldestNode)
+	}
 }
 
 // NodeUsage 节点资源使用率数据结构
@@ -66,12 +351,74 @@ func (c *NodeUsageCache) Set(node string, usage *NodeUsage) {
 //   - CPU:    节点CPU使用率（0.0-1.0）
 //   - Memory: 节点内存使用率（0.0-1.0）
 //   - DiskIO: 磁盘IO使用率（0.0-1.0）
-//   - Network:网络带宽使用率（0.0-1.0）
+//   - Network:网络带宽使用率（收发合计，保持向后兼容）
+//   - NetworkRx: 网络接收方向带宽使用率
+//   - NetworkTx: 网络发送方向带宽使用率
+//   - GPU:    GPU使用率（0.0-1.0，无GPU节点为降级默认值）
 //   - Timestamp: 数据采集时间（用于判断缓存有效性）
 type NodeUsage struct {
 	CPU       float64   // CPU使用率
 	Memory    float64   // 内存使用率
 	DiskIO    float64   // 磁盘IO使用率
-	Network   float64   // 网络使用率
+	Network   float64   // 网络使用率（收发合计）
+	NetworkRx float64   // 网络接收方向使用率
+	NetworkTx float64   // 网络发送方向使用率
+	GPU       float64   // GPU使用率
 	Timestamp time.Time // 数据采集时间戳
+
+	// Metrics 按资源维度名的使用率映射（含上述内置维度与自定义指标）
+	// 新增指标只需配置QueryTemplates即可参与评分，无需改代码
+	Metrics map[string]float64
+
+	// ResourceTimestamps 各维度各自的采集时间
+	// 支持按维度过期与增量刷新；为nil时整条按Timestamp过期
+	ResourceTimestamps map[string]time.Time
+}
+
+// setNamed 同步更新内置命名字段（保持既有访问方式兼容）
+func (u *NodeUsage) setNamed(resource string, value float64) {
+	switch resource {
+	case "cpu":
+		u.CPU = value
+	case "memory":
+		u.Memory = value
+	case "diskio":
+		u.DiskIO = value
+	case "netio":
+		u.Network = value
+	case "netrx":
+		u.NetworkRx = value
+	case "nettx":
+		u.NetworkTx = value
+	case "gpu":
+		u.GPU = value
+	}
+}
+
+// Get 按资源维度名返回使用率
+// Metrics映射存在时以其为准：未出现在映射中的维度视为本轮未采集到
+// （查询失败且无降级值），由评分逻辑剔除；映射为nil时回退内置命名字段，
+// 保证直接构造命名字段的旧用法（测试/兼容路径）仍可评分
+func (u *NodeUsage) Get(resource string) (float64, bool) {
+	if u.Metrics != nil {
+		v, ok := u.Metrics[resource]
+		return v, ok
+	}
+	switch resource {
+	case "cpu":
+		return u.CPU, true
+	case "memory":
+		return u.Memory, true
+	case "diskio":
+		return u.DiskIO, true
+	case "netio":
+		return u.Network, true
+	case "netrx":
+		return u.NetworkRx, true
+	case "nettx":
+		return u.NetworkTx, true
+	case "gpu":
+		return u.GPU, true
+	}
+	return 0, false
 }
@@ -0,0 +1,260 @@
+// pkg/scheduler/framework/plugins/dynamicweight/cache_test.go
+package dynamicweight
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 验证超过maxEntries时按LRU淘汰最久未访问的条目
+func TestCacheLRUEviction(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 2)
+
+	c.Set("node-1", &NodeUsage{CPU: 0.1})
+	c.Set("node-2", &NodeUsage{CPU: 0.2})
+
+	// 访问node-1，使node-2成为最久未访问的条目
+	if c.Get("node-1") == nil {
+		t.Fatal("node-1应命中缓存")
+	}
+
+	// 写入第三个条目触发淘汰
+	c.Set("node-3", &NodeUsage{CPU: 0.3})
+
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, 期望 2", c.Len())
+	}
+	if c.Get("node-2") != nil {
+		t.Error("最久未访问的node-2应被淘汰")
+	}
+	if c.Get("node-1") == nil || c.Get("node-3") == nil {
+		t.Error("node-1与node-3应保留")
+	}
+}
+
+// 验证maxEntries<=0时不限制容量
+func TestCacheUnbounded(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 0)
+	for i := 0; i < 100; i++ {
+		c.Set(fmt.Sprintf("node-%d", i), &NodeUsage{})
+	}
+	if c.Len() != 100 {
+		t.Errorf("Len() = %d, 期望 100", c.Len())
+	}
+}
+
+// 验证sweep删除过期条目、保留未过期条目
+func TestCacheSweep(t *testing.T) {
+	c := NewNodeUsageCache(50*time.Millisecond, 0)
+	c.Set("stale-1", &NodeUsage{})
+	c.Set("stale-2", &NodeUsage{})
+
+	time.Sleep(60 * time.Millisecond)
+	c.Set("fresh", &NodeUsage{})
+
+	c.sweep()
+
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, 期望仅保留1个未过期条目", c.Len())
+	}
+	if c.Get("fresh") == nil {
+		t.Error("未过期的条目不应被清理")
+	}
+}
+
+// 验证StartGC随ctx取消退出，且运行期间清理过期条目
+func TestCacheStartGC(t *testing.T) {
+	c := NewNodeUsageCache(10*time.Millisecond, 0)
+	c.Set("stale", &NodeUsage{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.StartGC(ctx, 20*time.Millisecond)
+
+	// 等待清理协程至少执行一轮
+	deadline := time.Now().Add(time.Second)
+	for c.Len() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, 期望过期条目被后台清理", c.Len())
+	}
+}
+
+// 验证Delete清理指定节点的条目（节点下线场景）
+func TestCacheDelete(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 0)
+	c.Set("node-1", &NodeUsage{})
+	c.Delete("node-1")
+	if c.Get("node-1") != nil {
+		t.Error("删除后不应命中缓存")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, 期望 0", c.Len())
+	}
+	// 删除不存在的条目不应panic
+	c.Delete("node-x")
+}
+
+// 验证Invalidate立即作废条目，InvalidateAll清空全部
+func TestCacheInvalidate(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 0)
+	c.Set("node-1", &NodeUsage{CPU: 0.5})
+	c.Set("node-2", &NodeUsage{CPU: 0.6})
+
+	c.Invalidate("node-1")
+	if c.Get("node-1") != nil {
+		t.Error("Invalidate后不应命中缓存")
+	}
+	if c.Get("node-2") == nil {
+		t.Error("其他节点的条目不应受影响")
+	}
+
+	c.InvalidateAll()
+	if c.Get("node-2") != nil {
+		t.Error("InvalidateAll后不应命中任何条目")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, 期望 0", c.Len())
+	}
+}
+
+// 验证按维度过期：只要还有未过期维度就返回数据并报告过期维度列表
+func TestCachePartialExpiry(t *testing.T) {
+	c := NewNodeUsageCache(50*time.Millisecond, 0)
+	c.Set("node-1", &NodeUsage{Metrics: map[string]float64{"cpu": 0.5, "diskio": 0.3}})
+
+	// 回拨diskio的采集时间，使其单独过期
+	c.mu.Lock()
+	c.data["node-1"].usage.ResourceTimestamps["diskio"] = time.Now().Add(-time.Minute)
+	c.mu.Unlock()
+
+	usage, stale := c.GetWithStale("node-1")
+	if usage == nil {
+		t.Fatal("仍有未过期维度时应返回缓存数据")
+	}
+	if len(stale) != 1 || stale[0] != "diskio" {
+		t.Errorf("stale = %v, 期望 [diskio]", stale)
+	}
+
+	// 增量刷新过期维度后不应再报告过期
+	if c.UpdateResources("node-1", map[string]float64{"diskio": 0.9}, nil) == nil {
+		t.Fatal("UpdateResources不应返回nil")
+	}
+	usage, stale = c.GetWithStale("node-1")
+	if usage == nil || len(stale) != 0 {
+		t.Fatalf("刷新后 stale = %v, 期望为空", stale)
+	}
+	if v, _ := usage.Get("diskio"); v != 0.9 {
+		t.Errorf("diskio = %v, 期望刷新为 0.9", v)
+	}
+	if v, _ := usage.Get("cpu"); v != 0.5 {
+		t.Errorf("cpu = %v, 期望保留 0.5", v)
+	}
+
+	// 全部维度过期时返回(nil, nil)，由调用方整条重建
+	c.mu.Lock()
+	for res := range c.data["node-1"].usage.ResourceTimestamps {
+		c.data["node-1"].usage.ResourceTimestamps[res] = time.Now().Add(-time.Minute)
+	}
+	c.mu.Unlock()
+	if usage, stale = c.GetWithStale("node-1"); usage != nil || stale != nil {
+		t.Error("全部维度过期时应返回(nil, nil)")
+	}
+
+	// 条目不存在时UpdateResources返回nil
+	if c.UpdateResources("node-x", map[string]float64{"cpu": 0.1}, nil) != nil {
+		t.Error("条目不存在时UpdateResources应返回nil")
+	}
+}
+
+// 验证写入方提供的采样时刻被沿用，缺采样时刻的降级维度立即标记过期
+func TestSetRespectsSampledTimestamps(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 0)
+	sampled := time.Now().Add(-time.Minute)
+	c.Set("node-1", &NodeUsage{
+		Metrics:            map[string]float64{"cpu": 0.5, "diskio": 0.3},
+		ResourceTimestamps: map[string]time.Time{"cpu": sampled},
+	})
+
+	usage, stale := c.GetWithStale("node-1")
+	if usage == nil || len(stale) != 1 || stale[0] != "diskio" {
+		t.Fatalf("缺采样时刻的维度应立即过期, stale = %v", stale)
+	}
+	if !usage.ResourceTimestamps["cpu"].Equal(sampled) {
+		t.Errorf("cpu采样时刻 = %v, 期望沿用写入方提供的 %v",
+			usage.ResourceTimestamps["cpu"], sampled)
+	}
+	if c.Get("node-1") == nil {
+		t.Error("仍有未过期维度的条目应可整条读取")
+	}
+}
+
+// 验证增量合并走写时复制：已交给读者的旧对象不被改写
+func TestUpdateResourcesCopyOnWrite(t *testing.T) {
+	c := NewNodeUsageCache(5*time.Minute, 0)
+	c.Set("node-1", &NodeUsage{Metrics: map[string]float64{"cpu": 0.5, "diskio": 0.3}})
+
+	before := c.Get("node-1")
+	after := c.UpdateResources("node-1", map[string]float64{"diskio": 0.9}, nil)
+	if after == before {
+		t.Fatal("UpdateResources应返回新副本而非原对象")
+	}
+	if v := before.Metrics["diskio"]; v != 0.3 {
+		t.Errorf("旧对象被原地改写: diskio = %v, 期望保持 0.3", v)
+	}
+	if got := c.Get("node-1"); got != after || got.Metrics["diskio"] != 0.9 {
+		t.Errorf("缓存应返回合并后的新副本, got = %+v", got)
+	}
+}
+
+// 验证无按维度时间戳的旧式条目退化为整条过期判断
+func TestCacheGetWithStaleLegacyEntry(t *testing.T) {
+	c := NewNodeUsageCache(50*time.Millisecond, 0)
+	c.Set("node-1", &NodeUsage{CPU: 0.5})
+
+	if usage, stale := c.GetWithStale("node-1"); usage == nil || stale != nil {
+		t.Errorf("未过期的旧式条目应整条返回, usage=%v stale=%v", usage, stale)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if usage, stale := c.GetWithStale("node-1"); usage != nil || stale != nil {
+		t.Error("过期的旧式条目应返回(nil, nil)")
+	}
+}
+
+// 验证缓存精确遵守配置的TTL边界
+func TestCacheRespectsConfiguredTTL(t *testing.T) {
+	ttl := 100 * time.Millisecond
+	cache := NewNodeUsageCache(ttl, 0)
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.3)
+	cache.Set("node-1", usage)
+
+	// TTL内可读
+	if got := cache.Get("node-1"); got == nil {
+		t.Fatal("TTL内应命中缓存")
+	}
+
+	// 手动把时间戳拨到恰好过期边界之外
+	entry := cache.Get("node-1")
+	entry.Timestamp = time.Now().Add(-ttl)
+	for res := range entry.ResourceTimestamps {
+		entry.ResourceTimestamps[res] = entry.Timestamp
+	}
+	if got := cache.Get("node-1"); got != nil {
+		t.Errorf("超过TTL后应返回nil, 实际 %+v", got)
+	}
+
+	// 边界内1ms仍有效
+	entry.Timestamp = time.Now().Add(-ttl + 10*time.Millisecond)
+	for res := range entry.ResourceTimestamps {
+		entry.ResourceTimestamps[res] = entry.Timestamp
+	}
+	if got := cache.Get("node-1"); got == nil {
+		t.Error("未到TTL边界的数据应仍然有效")
+	}
+}
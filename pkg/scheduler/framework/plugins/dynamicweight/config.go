@@ -3,12 +3,66 @@ package dynamicweight
 
 import (
 	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// 最小rate查询窗口：窗口低于Prometheus抓取间隔时rate()无法计算出结果
+// 按常见的抓取间隔配置（30s）设定下限
+const minRateWindow = 30 * time.Second
+
+// 权重之和允许的浮点误差：配置通常手写（如0.1+0.2+0.7），
+// 允许小幅偏差，但拒绝明显不等于1.0的配置
+const weightSumEpsilon = 0.01
+
+// 权重配置来源后端的取值
+const (
+	weightSourceConfigMap = "configmap"
+	weightSourceCRD       = "crd"
+)
+
+// HTTP头名称的合法字符集（RFC 7230 token的常用子集）
+var validHeaderName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-_]*$`)
+
+// 调度策略的取值（见Strategy）
+const (
+	strategySpread  = "spread"
+	strategyBinpack = "binpack"
+)
+
+// 评分曲线的取值（见ScoringCurve）
+const (
+	scoringCurveLinear      = "linear"
+	scoringCurveExponential = "exponential"
+	scoringCurveSigmoid     = "sigmoid"
+)
+
+// 缓存陈旧度衰减曲线的取值（见StalenessDecay）
+const (
+	stalenessDecayNone   = "none"
+	stalenessDecayLinear = "linear"
+)
+
+// instance标识来源的取值（见InstanceLabelSource）
+const (
+	instanceSourceInternalIP = "InternalIP"
+	instanceSourceHostname   = "Hostname"
+	instanceSourceNodeName   = "NodeName"
 )
 
+// 地址类型回退链中额外支持的类型（见AddressTypePreference）
+const addressTypeExternalIP = "ExternalIP"
+
 var scheme = runtime.NewScheme()
 var codecs = serializer.NewCodecFactory(scheme)
 
@@ -24,11 +78,42 @@ func init() {
 		schema.GroupVersion{Group: "kubescheduler.config.k8s.io", Version: "v1"},
 		&DynamicWeightArgs{},
 	)
+
+	// 注册默认值填充函数：scheme.Default按类型分发到SetDefaults_DynamicWeightArgs
+	scheme.AddTypeDefaultingFunc(&DynamicWeightArgs{}, func(obj interface{}) {
+		SetDefaults_DynamicWeightArgs(obj.(*DynamicWeightArgs))
+	})
+}
+
+// SetDefaults_DynamicWeightArgs 填充未配置字段的默认值
+// 命名遵循Kubernetes的SetDefaults_<Type>惯例，便于后续接入自动生成的defaulter
+func SetDefaults_DynamicWeightArgs(args *DynamicWeightArgs) {
+	// 未配置默认权重时四个内置维度均分
+	if len(args.DefaultWeights) == 0 {
+		args.DefaultWeights = map[string]float64{
+			"cpu":    0.25,
+			"memory": 0.25,
+			"diskio": 0.25,
+			"netio":  0.25,
+		}
+	}
+	if args.LabelWeights == nil {
+		args.LabelWeights = make(map[string]map[string]float64)
+	}
+	if args.FailOpen == nil {
+		failOpen := true
+		args.FailOpen = &failOpen
+	}
+	if args.NeutralScore == 0 {
+		args.NeutralScore = neutralScore
+	}
 }
 
 // 定义插件的配置参数结构体
 // 作用：存储从ConfigMap读取的权重配置
 type DynamicWeightArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
 	// 默认权重：当Pod没有指定资源偏好标签时使用
 	// 键值对格式：资源类型 -> 权重值
 	// 示例：{"cpu":0.25, "memory":0.25, "diskio":0.25, "netio":0.25}
@@ -38,6 +123,359 @@ type DynamicWeightArgs struct {
 	// 键值对格式：标签名称 -> 资源权重配置
 	// 示例："cpu-prefer"标签对应{"cpu":0.7, "memory":0.1, ...}
 	LabelWeights map[string]map[string]float64 `json:"labelWeights"`
+
+	// 命名空间权重：按Pod所在命名空间匹配的权重配置
+	// 键值对格式：命名空间名称 -> 资源权重配置
+	// 由管理员按团队/租户设置，优先级高于Pod自带的resource-prefer标签
+	// 完整优先级：NamespaceWeights > LabelWeights > DefaultWeights
+	NamespaceWeights map[string]map[string]float64 `json:"namespaceWeights,omitempty"`
+
+	// ScheduledWeights：按时段生效的基础权重（可选）
+	// 当前时刻命中某个窗口时，该窗口的权重表替代DefaultWeights作为
+	// 基础权重（NamespaceWeights/LabelWeights的优先级不变）；
+	// 窗口重叠时列表序在前者优先，全部未命中时回退DefaultWeights
+	// 适用于白天偏CPU、夜间偏IO之类的分时调度策略
+	ScheduledWeights []ScheduledWeight `json:"scheduledWeights,omitempty"`
+
+	// DiskDevice：磁盘IO查询匹配的设备名（支持正则，如"nvme.*"）
+	// 默认为"sdb"，可被节点标签覆盖（见resolveDiskDevice）
+	DiskDevice string `json:"diskDevice,omitempty"`
+
+	// NetworkInterface：网络查询匹配的网卡名（支持正则，如"ens.*"）
+	// 默认为"eth0"，可被节点标签覆盖（见resolveNetworkInterface）
+	NetworkInterface string `json:"networkInterface,omitempty"`
+
+	// RefreshInterval：后台缓存刷新协程的执行间隔，默认30s
+	// 刷新协程定期为所有节点预取指标，Score只读缓存
+	RefreshInterval metav1.Duration `json:"refreshInterval,omitempty"`
+
+	// RateWindow：rate类查询使用的时间窗口（如"1m"、"10m"）
+	// 窗口越短对突发负载反应越快，越长对噪声越稳定；默认5m
+	// 不允许短于minRateWindow（Prometheus抓取间隔下限）
+	RateWindow metav1.Duration `json:"rateWindow,omitempty"`
+
+	// FallbackUsage：按资源维度配置查询失败时的降级使用率
+	// 覆盖内置默认值（diskio:0.3等）；配1.0可强力惩罚指标缺失的节点
+	// 未配置且无内置默认值的维度在失败时被剔除出加权和并重归一化
+	FallbackUsage map[string]float64 `json:"fallbackUsage,omitempty"`
+
+	// MaxUsage：按资源维度的硬性使用率上限（0-1），用于Filter阶段
+	// 任一监控维度超过上限的节点直接判为Unschedulable，不再参与评分
+	// 示例：{"cpu":0.95, "memory":0.9}；未配置的维度不做过滤（等价上限1.0）
+	MaxUsage map[string]float64 `json:"maxUsage,omitempty"`
+
+	// QueryTimeout：单轮指标拉取（一个节点的全部维度或一次批量预取）的总超时
+	// 防止Prometheus挂起时长时间占用调度器线程；超时按降级处理并计数
+	// 默认0表示不额外限时（沿用调用方ctx）
+	QueryTimeout metav1.Duration `json:"queryTimeout,omitempty"`
+
+	// ScoreQuantization：得分量化桶宽（0-100分制下的分数）
+	// NormalizeScore把各节点得分圆整到最近的桶宽倍数，抑制指标
+	// 细微抖动导致的榜首翻转；默认0不量化
+	ScoreQuantization int64 `json:"scoreQuantization,omitempty"`
+
+	// ExplainScores：把各资源维度对最终得分的贡献写入CycleState
+	// 调试开关：下游报告机制可经ScoreBreakdown取回得分构成
+	// 每次评分多一次状态写入，默认关闭
+	ExplainScores bool `json:"explainScores,omitempty"`
+
+	// MetricsServerFallback：启用metrics-server备用指标源
+	// Prometheus整轮查询失败时改从metrics-server的节点指标API取数
+	// （仅CPU/内存两维，按Allocatable折算），保证故障期基础评分仍可用
+	// 默认关闭
+	MetricsServerFallback bool `json:"metricsServerFallback,omitempty"`
+
+	// WarmupPeriod：调度器启动后的预热宽限期
+	// 期间Score统一返回中性分（后台照常填充缓存），避免缓存为空、
+	// Prometheus的rate窗口未填满时的早期得分影响放置决策
+	// 默认0表示不预热，启动即按真实指标评分
+	WarmupPeriod metav1.Duration `json:"warmupPeriod,omitempty"`
+
+	// ResourceQueryTimeouts：按资源维度覆盖单条查询的超时
+	// 磁盘IO等重聚合查询可以单独给更长的时限，而不必放宽整轮预算；
+	// 单维度超时只影响该维度（走降级路径），不中止整轮拉取
+	// 示例：{"diskio":"3s", "cpu":"500ms"}；未配置的维度沿用整轮超时
+	ResourceQueryTimeouts map[string]metav1.Duration `json:"resourceQueryTimeouts,omitempty"`
+
+	// SmoothingEnabled：启用区间平滑查询
+	// 用QueryRange在短回溯窗口内取多个采样点求平均，降低单次坏抓取的毛刺影响
+	// 默认关闭（保持瞬时查询）
+	SmoothingEnabled bool `json:"smoothingEnabled,omitempty"`
+
+	// SmoothingWindow：平滑查询的回溯窗口时长，默认2m
+	SmoothingWindow metav1.Duration `json:"smoothingWindow,omitempty"`
+
+	// QueryMaxAttempts：单次指标查询的最大尝试次数（含首次），默认1（不重试）
+	// 配合QueryRetryBaseDelay做有界指数退避，吸收瞬时网络抖动
+	QueryMaxAttempts int `json:"queryMaxAttempts,omitempty"`
+
+	// QueryRetryBaseDelay：重试退避的基础等待时长，每次翻倍，默认100ms
+	QueryRetryBaseDelay metav1.Duration `json:"queryRetryBaseDelay,omitempty"`
+
+	// 查询模板覆盖：按资源维度覆盖内置的PromQL查询模板
+	// 键值对格式：资源类型（cpu/memory/diskio/netrx/nettx） -> PromQL模板
+	// 模板中的{instance}占位符在查询时替换为节点IP
+	// 未覆盖的资源维度继续使用内置模板
+	QueryTemplates map[string]string `json:"queryTemplates,omitempty"`
+
+	// ExtendedResources：Kubernetes扩展资源名到PromQL查询模板的映射（可选）
+	// 键为合法的扩展资源标识（如"nvidia.com/gpu"、"example.com/nvme"），
+	// 值为该资源实时使用率（0~1）的查询模板，占位符与QueryTemplates相同
+	// 查询结果并入节点使用率映射，各权重表用同名键即可为其配权，
+	// 自定义硬件维度无需改代码即可参与评分
+	ExtendedResources map[string]string `json:"extendedResources,omitempty"`
+
+	// InstanceMatcher：内置查询模板中节点序列匹配表达式的覆盖
+	// 默认按instance标签匹配：instance=~"{value}(:.*)?"；
+	// Prometheus Operator的ServiceMonitor惯例下节点指标常按node/nodename
+	// 标签键控，可配置如 node="{value}"（{value}替换为InstanceLabelSource
+	// 解析出的节点标识，通常配合NodeName使用）
+	// 仅作用于内置模板；自定义QueryTemplates由运维自行编写匹配表达式
+	InstanceMatcher string `json:"instanceMatcher,omitempty"`
+
+	// AddressTypePreference：解析节点地址时按序尝试的地址类型列表
+	// 部分云/边缘节点不上报InternalIP，按此顺序回退可继续参与评分
+	// 可选类型：InternalIP/Hostname/ExternalIP
+	// 默认 ["InternalIP","Hostname","ExternalIP"]
+	AddressTypePreference []string `json:"addressTypePreference,omitempty"`
+
+	// InstanceLabelSource：查询模板中{instance}取用的节点标识来源
+	// 可选InternalIP（默认）/Hostname/NodeName，
+	// 与node-exporter暴露的instance标签形式保持一致
+	InstanceLabelSource string `json:"instanceLabelSource,omitempty"`
+
+	// ScoringCurve：使用率到得分的映射曲线，默认linear
+	// linear：weight × (1 - usage)，均匀偏好
+	// exponential：低负载节点得分衰减更快，强化分散（bin-spreading）
+	// sigmoid：中段陡峭，近空/近满节点得分区分度高，利于装箱决策
+	ScoringCurve string `json:"scoringCurve,omitempty"`
+
+	// Strategy：负载分布策略，默认spread
+	// spread：低负载节点得分高，分散负载
+	// binpack：高负载节点得分高，把Pod聚拢到已忙节点上，便于空节点缩容
+	Strategy string `json:"strategy,omitempty"`
+
+	// PackingBias：spread与binpack的连续混合系数（[-1, 1]）
+	// -1为纯spread，+1为纯binpack，0为中性（各节点贡献分相同）；
+	// 配置后优先于Strategy/ResourceStrategies的硬开关，
+	// 供运维在"聚拢便于缩容"与"分散均衡负载"之间细粒度调节
+	PackingBias *float64 `json:"packingBias,omitempty"`
+
+	// ResourceStrategies：按资源维度覆盖Strategy
+	// 示例：{"cpu":"binpack"}表示CPU维度装箱、其余维度沿用全局策略，
+	// 可实现不同资源各自聚拢或分散
+	ResourceStrategies map[string]string `json:"resourceStrategies,omitempty"`
+
+	// PredictedUsageThreshold：放置后预测使用率的惩罚阈值（0-1）
+	// 将Pod聚合requests按节点Allocatable折算为使用率增量，叠加到实时
+	// 使用率上；任一维度预测值越过阈值的节点该维度得分记零，
+	// 避免大Pod落到"看似空闲、放上即满"的节点。0表示关闭
+	PredictedUsageThreshold float64 `json:"predictedUsageThreshold,omitempty"`
+
+	// CacheTTL：节点使用率缓存条目的有效期，默认5m
+	// 与RefreshInterval独立：TTL决定"多旧的数据还可用于评分"，
+	// 刷新间隔决定后台拉取的频率；调短TTL用更多Prometheus查询
+	// 换取更新鲜的调度决策
+	CacheTTL metav1.Duration `json:"cacheTTL,omitempty"`
+
+	// MaxDataAge：Prometheus样本自身时间戳允许的最大年龄，0表示关闭
+	// CacheTTL只约束查询结果在本插件内的复用时长；node-exporter停止
+	// 上报时Prometheus仍会返回最后一次抓取的样本（带旧时间戳），
+	// 超龄样本按缺失处理，走降级默认值/中性分路径
+	MaxDataAge metav1.Duration `json:"maxDataAge,omitempty"`
+
+	// StalenessDecay：缓存条目老化时向中性值衰减的曲线，默认none
+	// none：TTL内的缓存值全额采信（保持既有行为）
+	// linear：按条目年龄占TTL的比例向中性使用率0.5线性混合，
+	// 临近过期的数据对评分的影响逐渐减弱
+	StalenessDecay string `json:"stalenessDecay,omitempty"`
+
+	// ReloadDebounce：配置热更新的去抖窗口，默认1s
+	// 窗口内的多次ConfigMap变更合并为一次重载，重载读取最新内容（最终状态生效）
+	ReloadDebounce metav1.Duration `json:"reloadDebounce,omitempty"`
+
+	// ResyncPeriod：配置informer的全量重同步周期，默认5m
+	// 重同步会触发一次UpdateFunc回调，周期过短会放大apiserver压力
+	ResyncPeriod metav1.Duration `json:"resyncPeriod,omitempty"`
+
+	// ResyncJitterFactor：重同步周期与后台刷新间隔的抖动系数（0-1]，默认0.1
+	// 每轮在基准周期上叠加[0, factor×周期)的随机量，
+	// 多副本调度器部署时错开各副本的重同步/刷新时刻，避免惊群
+	ResyncJitterFactor float64 `json:"resyncJitterFactor,omitempty"`
+
+	// DebugAddress：调试HTTP端点的监听地址（如"127.0.0.1:10261"）
+	// 在/debug/dynamicweight路径输出当前生效配置与节点使用率缓存快照；
+	// 端点暴露集群内部状态，默认为空（关闭），建议只监听回环地址
+	DebugAddress string `json:"debugAddress,omitempty"`
+
+	// WeightSource：权重配置的来源后端
+	// "configmap"（默认）从ConfigMap读取；"crd"从DynamicWeightPolicy自定义资源读取
+	// 为空且未配置ConfigMapName时直接使用本args中的静态配置
+	WeightSource string `json:"weightSource,omitempty"`
+
+	// PolicyNamespace/PolicyName：WeightSource为"crd"时自定义资源的位置
+	// 未配置时沿用默认值（kube-system/dynamic-weight-policy）
+	PolicyNamespace string `json:"policyNamespace,omitempty"`
+	PolicyName      string `json:"policyName,omitempty"`
+
+	// ConfigMapNamespace/ConfigMapName：权重配置ConfigMap的位置
+	// 未配置时沿用默认值（kube-system/dynamic-weight-config）
+	// 多租户场景下不同调度Profile可各自指向独立的ConfigMap
+	ConfigMapNamespace string `json:"configMapNamespace,omitempty"`
+	ConfigMapName      string `json:"configMapName,omitempty"`
+
+	// FailOpen：指标不可用时的兜底策略
+	// 开启（默认）时Score对无法取得指标的节点返回中性分并放行，
+	// 关闭时返回错误（可能中断该Pod的整轮评分）；本插件属尽力而为的
+	// 打分插件，监控故障不应阻塞调度，故默认开启
+	FailOpen *bool `json:"failOpen,omitempty"`
+
+	// NeutralScore：兜底时使用的中性分（0-100），默认50
+	NeutralScore int64 `json:"neutralScore,omitempty"`
+
+	// ObserveOnly：观察模式
+	// 开启后Score照常计算得分并记录日志/指标，但对调度决策恒返回
+	// 中性分（等效无操作插件），用于上线前安全地验证PromQL与权重配置
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+
+	// StrictLabelValidation：严格校验Pod的resource-prefer标签取值
+	// 开启后PreFilter直接拒绝取值未匹配任何LabelWeights条目的Pod
+	// 默认关闭：仅发Warning事件提示，保持原有宽松行为
+	StrictLabelValidation bool `json:"strictLabelValidation,omitempty"`
+
+	// ExcludeNodeSelector：排除在实时负载评分之外的节点标签选择器
+	// 控制面/专用节点池不应被使用率评分左右；命中的节点Score恒返回
+	// 中性分，后台刷新与批量预取也跳过它们，省去无意义的Prometheus查询
+	ExcludeNodeSelector *metav1.LabelSelector `json:"excludeNodeSelector,omitempty"`
+
+	// FilterRules：DynamicWeightPodFilter使用的过滤规则列表
+	// 命中PodLabel（及可选PodValue）的Pod只能调度到带NodeLabel=NodeValue的节点
+	// Pod命中多条规则时节点必须同时满足全部要求；未配置时使用内置的RDMA规则
+	FilterRules []PodFilterRule `json:"filterRules,omitempty"`
+
+	// MetricScale：各资源维度原始查询值的缩放系数
+	// 不同exporter的单位口径不一（bit/byte、百分比/小数），与0-1
+	// 使用率口径不符时用系数修正，免改PromQL：如0.01把百分比折算为小数
+	// 系数在clamp截断之前生效；未配置的维度按1.0处理（不缩放）
+	MetricScale map[string]float64 `json:"metricScale,omitempty"`
+
+	// PrometheusAddress：Prometheus服务地址
+	// 支持os.ExpandEnv风格的环境变量引用（如"$PROMETHEUS_URL"），
+	// 模板化部署可由Pod spec注入环境变量而无需改动配置文件；
+	// 引用在New时一次性展开，默认为集群内Prometheus Operator惯例地址
+	PrometheusAddress string `json:"prometheusAddress,omitempty"`
+
+	// QueryHeaders：附加到每个Prometheus查询请求的HTTP头
+	// 用于Thanos Query/Cortex等联邦后端的路由与鉴权（如租户ID）
+	QueryHeaders map[string]string `json:"queryHeaders,omitempty"`
+
+	// TenantHeader：多租户后端的租户标识，通过标准的X-Scope-OrgID头发送
+	// 需要自定义头名时改用QueryHeaders直接配置
+	TenantHeader string `json:"tenantHeader,omitempty"`
+
+	// CAFile：自定义CA证书文件路径（PEM格式）
+	// 用于校验HTTPS Prometheus的服务端证书，可指向挂载Secret的路径
+	CAFile string `json:"caFile,omitempty"`
+
+	// InsecureSkipVerify：跳过服务端证书校验（仅建议测试环境使用）
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ClientCertFile/ClientKeyFile：双向TLS认证时的客户端证书与私钥路径
+	// 两者必须成对配置，否则初始化时报错
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+	ClientKeyFile  string `json:"clientKeyFile,omitempty"`
+}
+
+// expandEnv 展开运维侧字符串字段中的$VAR/${VAR}环境变量引用
+// 覆盖Prometheus地址、租户头、附加查询头及TLS文件路径，
+// New时调用一次即定型；未定义的变量按os.ExpandEnv语义展开为空串
+func (args *DynamicWeightArgs) expandEnv() {
+	args.PrometheusAddress = os.ExpandEnv(args.PrometheusAddress)
+	args.TenantHeader = os.ExpandEnv(args.TenantHeader)
+	for name, value := range args.QueryHeaders {
+		args.QueryHeaders[name] = os.ExpandEnv(value)
+	}
+	args.CAFile = os.ExpandEnv(args.CAFile)
+	args.ClientCertFile = os.ExpandEnv(args.ClientCertFile)
+	args.ClientKeyFile = os.ExpandEnv(args.ClientKeyFile)
+}
+
+// PodFilterRule 一条Pod过滤规则：类亲和性约束
+// 带PodLabel标签（PodValue非空时还需取值相等）的Pod
+// 只能调度到带NodeLabel=NodeValue标签的节点
+type PodFilterRule struct {
+	// PodLabel：触发规则的Pod标签名（必填）
+	PodLabel string `json:"podLabel"`
+	// PodValue：触发规则要求的标签取值，为空表示只要求标签存在
+	PodValue string `json:"podValue,omitempty"`
+	// NodeLabel/NodeValue：节点必须具备的标签及取值（必填）
+	NodeLabel string `json:"nodeLabel"`
+	NodeValue string `json:"nodeValue"`
+}
+
+// ScheduledWeight 一个按时段生效的权重窗口
+type ScheduledWeight struct {
+	// StartHour/EndHour 每日生效窗口（24小时制，[StartHour, EndHour)）
+	// EndHour小于StartHour表示跨午夜窗口（如22点至次日6点）
+	StartHour int `json:"startHour"`
+	EndHour   int `json:"endHour"`
+	// Days 生效的星期（0=周日 … 6=周六），为空表示每天
+	// 跨午夜窗口按换算后各时刻自身的星期判断
+	Days []int `json:"days,omitempty"`
+	// TimeZone IANA时区名（如"Asia/Shanghai"）；为空使用调度器本地时区
+	// 小时换算在该时区内进行，夏令时偏移由时区数据处理
+	TimeZone string `json:"timeZone,omitempty"`
+	// Weights 窗口内使用的权重表，校验规则与defaultWeights相同
+	Weights map[string]float64 `json:"weights"`
+}
+
+// active 判断时刻now是否落在窗口内
+func (sw *ScheduledWeight) active(now time.Time) bool {
+	if sw.TimeZone != "" {
+		loc, err := loadLocationCached(sw.TimeZone)
+		if err != nil {
+			return false // Validate已拦截非法时区，此处防御
+		}
+		now = now.In(loc)
+	}
+	if len(sw.Days) > 0 {
+		matched := false
+		for _, day := range sw.Days {
+			if int(now.Weekday()) == day {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	h := now.Hour()
+	if sw.StartHour < sw.EndHour {
+		return h >= sw.StartHour && h < sw.EndHour
+	}
+	// 跨午夜：起点之后或终点之前
+	return h >= sw.StartHour || h < sw.EndHour
+}
+
+// 时区解析缓存：time.LoadLocation每次读盘，评分路径按名缓存结果
+var (
+	tzCacheMu sync.Mutex
+	tzCache   = map[string]*time.Location{}
+)
+
+func loadLocationCached(name string) (*time.Location, error) {
+	tzCacheMu.Lock()
+	defer tzCacheMu.Unlock()
+	if loc, ok := tzCache[name]; ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	tzCache[name] = loc
+	return loc, nil
 }
 
 // Name 必须实现PluginFactory接口
@@ -45,6 +483,196 @@ func (d *DynamicWeightArgs) Name() string {
 	return "DynamicWeight"
 }
 
+// Validate 校验配置参数的合法性
+// 在New()与配置热更新路径中调用，拒绝非法配置
+func (args *DynamicWeightArgs) Validate() error {
+	if args.RateWindow.Duration != 0 && args.RateWindow.Duration < minRateWindow {
+		return fmt.Errorf("rateWindow %v 小于Prometheus抓取间隔下限 %v",
+			args.RateWindow.Duration, minRateWindow)
+	}
+	if err := validateWeights("defaultWeights", args.DefaultWeights); err != nil {
+		return err
+	}
+	for label, weights := range args.LabelWeights {
+		if err := validateWeights(fmt.Sprintf("labelWeights[%s]", label), weights); err != nil {
+			return err
+		}
+	}
+	if args.QueryMaxAttempts < 0 {
+		return fmt.Errorf("queryMaxAttempts %d 不能为负数", args.QueryMaxAttempts)
+	}
+	for ns, weights := range args.NamespaceWeights {
+		if err := validateWeights(fmt.Sprintf("namespaceWeights[%s]", ns), weights); err != nil {
+			return err
+		}
+	}
+	for name, template := range args.ExtendedResources {
+		if errs := validation.IsQualifiedName(name); len(errs) > 0 {
+			return fmt.Errorf("extendedResources[%s]: 不是合法的资源标识: %s",
+				name, strings.Join(errs, "; "))
+		}
+		if strings.TrimSpace(template) == "" {
+			return fmt.Errorf("extendedResources[%s]: 查询模板不能为空", name)
+		}
+	}
+	for i, sw := range args.ScheduledWeights {
+		if sw.StartHour < 0 || sw.StartHour > 23 || sw.EndHour < 0 || sw.EndHour > 23 {
+			return fmt.Errorf("scheduledWeights[%d]: 小时应在[0, 23]区间", i)
+		}
+		if sw.StartHour == sw.EndHour {
+			return fmt.Errorf("scheduledWeights[%d]: startHour与endHour相同，窗口为空", i)
+		}
+		for _, day := range sw.Days {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("scheduledWeights[%d]: 星期 %d 应在[0, 6]区间", i, day)
+			}
+		}
+		if sw.TimeZone != "" {
+			if _, err := loadLocationCached(sw.TimeZone); err != nil {
+				return fmt.Errorf("scheduledWeights[%d]: 无效时区 %q: %v", i, sw.TimeZone, err)
+			}
+		}
+		if err := validateWeights(fmt.Sprintf("scheduledWeights[%d]", i), sw.Weights); err != nil {
+			return err
+		}
+	}
+	if m := args.InstanceMatcher; m != "" && !strings.Contains(m, "{value}") {
+		return fmt.Errorf("instanceMatcher: %q 必须包含{value}占位符", m)
+	}
+	for _, addrType := range args.AddressTypePreference {
+		switch addrType {
+		case instanceSourceInternalIP, instanceSourceHostname, addressTypeExternalIP:
+		default:
+			return fmt.Errorf("addressTypePreference: 地址类型 %q 不受支持（可选: %s/%s/%s）",
+				addrType, instanceSourceInternalIP, instanceSourceHostname, addressTypeExternalIP)
+		}
+	}
+	switch args.InstanceLabelSource {
+	case "", instanceSourceInternalIP, instanceSourceHostname, instanceSourceNodeName:
+	default:
+		return fmt.Errorf("instanceLabelSource %q 不受支持（可选: %s/%s/%s）",
+			args.InstanceLabelSource, instanceSourceInternalIP, instanceSourceHostname, instanceSourceNodeName)
+	}
+	switch args.ScoringCurve {
+	case "", scoringCurveLinear, scoringCurveExponential, scoringCurveSigmoid:
+	default:
+		return fmt.Errorf("scoringCurve %q 不受支持（可选: %s/%s/%s）",
+			args.ScoringCurve, scoringCurveLinear, scoringCurveExponential, scoringCurveSigmoid)
+	}
+	switch args.StalenessDecay {
+	case "", stalenessDecayNone, stalenessDecayLinear:
+	default:
+		return fmt.Errorf("stalenessDecay %q 不受支持（可选: %s/%s）",
+			args.StalenessDecay, stalenessDecayNone, stalenessDecayLinear)
+	}
+	switch args.Strategy {
+	case "", strategySpread, strategyBinpack:
+	default:
+		return fmt.Errorf("strategy %q 不受支持（可选: %s/%s）",
+			args.Strategy, strategySpread, strategyBinpack)
+	}
+	if b := args.PackingBias; b != nil && (*b < -1.0 || *b > 1.0) {
+		return fmt.Errorf("packingBias: %v 应在[-1.0, 1.0]区间", *b)
+	}
+	for resource, strategy := range args.ResourceStrategies {
+		switch strategy {
+		case strategySpread, strategyBinpack:
+		default:
+			return fmt.Errorf("resourceStrategies[%s]: %q 不受支持（可选: %s/%s）",
+				resource, strategy, strategySpread, strategyBinpack)
+		}
+	}
+	switch args.WeightSource {
+	case "", weightSourceConfigMap, weightSourceCRD:
+	default:
+		return fmt.Errorf("weightSource %q 不受支持（可选: %s/%s）",
+			args.WeightSource, weightSourceConfigMap, weightSourceCRD)
+	}
+	if sel := args.ExcludeNodeSelector; sel != nil {
+		selector, err := metav1.LabelSelectorAsSelector(sel)
+		if err != nil {
+			return fmt.Errorf("excludeNodeSelector: %v", err)
+		}
+		if selector.Empty() {
+			return fmt.Errorf("excludeNodeSelector: 不能为空选择器（会排除所有节点）")
+		}
+	}
+	for i, rule := range args.FilterRules {
+		if rule.PodLabel == "" || rule.NodeLabel == "" {
+			return fmt.Errorf("filterRules[%d]: podLabel与nodeLabel不能为空", i)
+		}
+	}
+	for resource, limit := range args.MaxUsage {
+		if limit <= 0 || limit > 1.0 {
+			return fmt.Errorf("maxUsage: 资源 %s 的上限 %v 应在(0, 1.0]区间", resource, limit)
+		}
+	}
+	if t := args.PredictedUsageThreshold; t < 0 || t > 1.0 {
+		return fmt.Errorf("predictedUsageThreshold: %v 应在[0, 1.0]区间", t)
+	}
+	for name := range args.QueryHeaders {
+		if !validHeaderName.MatchString(name) {
+			return fmt.Errorf("queryHeaders: 非法的HTTP头名称 %q", name)
+		}
+	}
+	if args.CacheTTL.Duration < 0 {
+		return fmt.Errorf("cacheTTL: %v 不能为负", args.CacheTTL.Duration)
+	}
+	if args.MaxDataAge.Duration < 0 {
+		return fmt.Errorf("maxDataAge: %v 不能为负", args.MaxDataAge.Duration)
+	}
+	if args.ResyncPeriod.Duration < 0 {
+		return fmt.Errorf("resyncPeriod: %v 不能为负", args.ResyncPeriod.Duration)
+	}
+	if args.WarmupPeriod.Duration < 0 {
+		return fmt.Errorf("warmupPeriod: %v 不能为负", args.WarmupPeriod.Duration)
+	}
+	for res, timeout := range args.ResourceQueryTimeouts {
+		if timeout.Duration <= 0 {
+			return fmt.Errorf("resourceQueryTimeouts[%s]: %v 应为正数", res, timeout.Duration)
+		}
+		// 单维度时限不能超过整轮预算，否则配置无法生效（父ctx先到期）
+		if overall := args.QueryTimeout.Duration; overall > 0 && timeout.Duration > overall {
+			return fmt.Errorf("resourceQueryTimeouts[%s]: %v 不能超过整轮超时 queryTimeout %v", res, timeout.Duration, overall)
+		}
+	}
+	if f := args.ResyncJitterFactor; f < 0 || f > 1.0 {
+		return fmt.Errorf("resyncJitterFactor: %v 应在[0, 1.0]区间", f)
+	}
+	if args.NeutralScore < 0 || args.NeutralScore > 100 {
+		return fmt.Errorf("neutralScore: %d 应在[0, 100]区间", args.NeutralScore)
+	}
+	if q := args.ScoreQuantization; q < 0 || q > 100 {
+		return fmt.Errorf("scoreQuantization: %d 应在[0, 100]区间", q)
+	}
+	for res, scale := range args.MetricScale {
+		if scale <= 0 {
+			return fmt.Errorf("metricScale[%s]: %v 应为正数", res, scale)
+		}
+	}
+	return nil
+}
+
+// validateWeights 校验单个权重表：各项非负且总和约等于1.0
+// 权重和偏离1.0会使得分整体偏移出0-100区间，被调度器截断后失去区分度
+// 空表跳过校验（表示沿用默认权重）
+func validateWeights(field string, weights map[string]float64) error {
+	if len(weights) == 0 {
+		return nil
+	}
+	var sum float64
+	for resource, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("%s: 资源 %s 的权重 %v 不能为负数", field, resource, w)
+		}
+		sum += w
+	}
+	if sum < 1.0-weightSumEpsilon || sum > 1.0+weightSumEpsilon {
+		return fmt.Errorf("%s: 权重之和 %v 应约等于1.0", field, sum)
+	}
+	return nil
+}
+
 // 实现 runtime.Object 接口
 // 添加 DeepCopyObject 方法实现
 func (in *DynamicWeightArgs) DeepCopyObject() runtime.Object {
@@ -56,7 +684,7 @@ func (in *DynamicWeightArgs) DeepCopyObject() runtime.Object {
 
 // 实现 GetObjectKind 方法（必需接口）
 func (in *DynamicWeightArgs) GetObjectKind() schema.ObjectKind {
-	return schema.EmptyObjectKind // 非版本化对象返回空
+	return &in.TypeMeta
 }
 
 // 添加 DeepCopy 方法
@@ -89,33 +717,31 @@ func (in *DynamicWeightArgs) GetObjectKind() schema.ObjectKind {
 // 输入：Kubernetes API对象（包含从ConfigMap读取的原始数据）
 // 输出：初始化后的DynamicWeightArgs指针和错误信息
 func NewDynamicWeightArgs(obj runtime.Object) (*DynamicWeightArgs, error) {
-	// 步骤1：设置默认配置
-	args := &DynamicWeightArgs{
-		DefaultWeights: map[string]float64{
-			"cpu":    0.25,
-			"memory": 0.25,
-			"diskio": 0.25,
-			"netio":  0.25,
-		},
-		LabelWeights: make(map[string]map[string]float64),
-	}
+	args := &DynamicWeightArgs{}
 
-	// 步骤2：如果有输入配置，则解析覆盖默认值
+	// 步骤1：如果有输入配置，则走标准的runtime.Object解码路径
 	if obj != nil {
-		// 将Kubernetes的原始数据转换为结构体
-		// 类似JSON反序列化过程
-		raw := obj.(*runtime.Unknown).Raw
-		decoded, _, err := codecs.UniversalDeserializer().Decode(raw, nil, nil)
-		if err != nil {
-			return nil, fmt.Errorf("解码失败: %v", err)
-		}
-
-		var ok bool
-		args, ok = decoded.(*DynamicWeightArgs)
-		if !ok {
-			return nil, fmt.Errorf("无效的配置类型: %T", decoded)
+		switch t := obj.(type) {
+		case *runtime.Unknown:
+			// KubeSchedulerConfiguration传入的原始字节，经scheme反序列化
+			decoded, _, err := codecs.UniversalDeserializer().Decode(t.Raw, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("解码失败: %v", err)
+			}
+			var ok bool
+			args, ok = decoded.(*DynamicWeightArgs)
+			if !ok {
+				return nil, fmt.Errorf("无效的配置类型: %T", decoded)
+			}
+		case *DynamicWeightArgs:
+			// 已是结构化配置（测试/程序内构造）直接使用
+			args = t
+		default:
+			return nil, fmt.Errorf("无效的配置类型: %T", obj)
 		}
 	}
 
+	// 步骤2：按scheme注册的默认值填充未配置字段
+	scheme.Default(args)
 	return args, nil
 }
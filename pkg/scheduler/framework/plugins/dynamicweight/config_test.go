@@ -0,0 +1,149 @@
+// pkg/scheduler/framework/plugins/dynamicweight/config_test.go
+package dynamicweight
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// 验证SetDefaults填充0.25均分默认权重，且不覆盖已配置的值
+func TestSetDefaultsDynamicWeightArgs(t *testing.T) {
+	args := &DynamicWeightArgs{}
+	SetDefaults_DynamicWeightArgs(args)
+	want := map[string]float64{"cpu": 0.25, "memory": 0.25, "diskio": 0.25, "netio": 0.25}
+	if !reflect.DeepEqual(args.DefaultWeights, want) {
+		t.Errorf("DefaultWeights = %v, 期望 %v", args.DefaultWeights, want)
+	}
+	if args.LabelWeights == nil {
+		t.Error("LabelWeights应初始化为空映射")
+	}
+
+	// 已配置的权重不被默认值覆盖
+	custom := &DynamicWeightArgs{DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5}}
+	SetDefaults_DynamicWeightArgs(custom)
+	if custom.DefaultWeights["cpu"] != 0.5 {
+		t.Errorf("已配置的DefaultWeights被覆盖: %v", custom.DefaultWeights)
+	}
+}
+
+// 验证无配置输入时NewDynamicWeightArgs返回默认配置
+func TestNewDynamicWeightArgsDefaults(t *testing.T) {
+	args, err := NewDynamicWeightArgs(nil)
+	if err != nil {
+		t.Fatalf("NewDynamicWeightArgs失败: %v", err)
+	}
+	if args.DefaultWeights["cpu"] != 0.25 {
+		t.Errorf("DefaultWeights = %v, 期望填充0.25默认值", args.DefaultWeights)
+	}
+}
+
+// 验证配置经JSON序列化再走标准解码路径后字段保持一致（round-trip）
+func TestDynamicWeightArgsRoundTrip(t *testing.T) {
+	failOpen := false
+	in := &DynamicWeightArgs{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "kubescheduler.config.k8s.io/v1",
+			Kind:       "DynamicWeightArgs",
+		},
+		DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+		LabelWeights: map[string]map[string]float64{
+			"cpu-prefer": {"cpu": 0.7, "memory": 0.3},
+		},
+		DiskDevice:       "nvme.*",
+		NetworkInterface: "ens.*",
+		RateWindow:       metav1.Duration{Duration: time.Minute},
+		MaxUsage:         map[string]float64{"cpu": 0.95},
+		QueryMaxAttempts: 3,
+		FilterRules: []PodFilterRule{
+			{PodLabel: "gpu-workload", NodeLabel: "accelerator", NodeValue: "nvidia"},
+		},
+		StrictLabelValidation: true,
+		FailOpen:              &failOpen,
+		NeutralScore:          40,
+	}
+
+	raw, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	out, err := NewDynamicWeightArgs(&runtime.Unknown{Raw: raw})
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round-trip后配置不一致:\n输入 %+v\n输出 %+v", in, out)
+	}
+}
+
+// 验证已结构化的配置对象直接透传并补默认值
+func TestNewDynamicWeightArgsStructured(t *testing.T) {
+	in := &DynamicWeightArgs{DiskDevice: "vda"}
+	out, err := NewDynamicWeightArgs(in)
+	if err != nil {
+		t.Fatalf("NewDynamicWeightArgs失败: %v", err)
+	}
+	if out.DiskDevice != "vda" {
+		t.Errorf("DiskDevice = %s, 期望 vda", out.DiskDevice)
+	}
+	if len(out.DefaultWeights) == 0 {
+		t.Error("结构化输入也应填充默认权重")
+	}
+}
+
+// 验证StalenessDecay校验
+func TestStalenessDecayValidation(t *testing.T) {
+	for _, valid := range []string{"", stalenessDecayNone, stalenessDecayLinear} {
+		args := &DynamicWeightArgs{StalenessDecay: valid}
+		if err := args.Validate(); err != nil {
+			t.Errorf("stalenessDecay=%q 不应校验失败: %v", valid, err)
+		}
+	}
+	args := &DynamicWeightArgs{StalenessDecay: "exponential"}
+	if err := args.Validate(); err == nil {
+		t.Error("未支持的stalenessDecay应校验失败")
+	}
+}
+
+// 验证CacheTTL校验
+func TestCacheTTLValidation(t *testing.T) {
+	args := &DynamicWeightArgs{CacheTTL: metav1.Duration{Duration: -time.Second}}
+	if err := args.Validate(); err == nil {
+		t.Error("负的cacheTTL应校验失败")
+	}
+	args = &DynamicWeightArgs{CacheTTL: metav1.Duration{Duration: 30 * time.Second}}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法cacheTTL不应校验失败: %v", err)
+	}
+}
+
+// 验证环境变量展开：$VAR引用解析为实际取值，无引用的字段原样保留
+func TestExpandEnvInConfig(t *testing.T) {
+	t.Setenv("DW_PROM_URL", "http://prom.example:9090")
+	t.Setenv("DW_TENANT", "team-a")
+	args := &DynamicWeightArgs{
+		PrometheusAddress: "$DW_PROM_URL",
+		TenantHeader:      "${DW_TENANT}",
+		QueryHeaders:      map[string]string{"Authorization": "Bearer $DW_TENANT"},
+		CAFile:            "/etc/certs/ca.pem",
+	}
+
+	args.expandEnv()
+
+	if args.PrometheusAddress != "http://prom.example:9090" {
+		t.Errorf("PrometheusAddress = %s, 期望展开为环境变量取值", args.PrometheusAddress)
+	}
+	if args.TenantHeader != "team-a" {
+		t.Errorf("TenantHeader = %s, 期望 team-a", args.TenantHeader)
+	}
+	if args.QueryHeaders["Authorization"] != "Bearer team-a" {
+		t.Errorf("QueryHeaders[Authorization] = %s, 期望 Bearer team-a", args.QueryHeaders["Authorization"])
+	}
+	if args.CAFile != "/etc/certs/ca.pem" {
+		t.Errorf("无引用的字段不应改变: %s", args.CAFile)
+	}
+}
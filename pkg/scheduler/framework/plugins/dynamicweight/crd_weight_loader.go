@@ -0,0 +1,169 @@
+// pkg/scheduler/framework/plugins/dynamicweight/crd_weight_loader.go
+package dynamicweight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// 默认的DynamicWeightPolicy自定义资源位置，可通过DynamicWeightArgs覆盖
+const (
+	policyNamespace = "kube-system"
+	policyName      = "dynamic-weight-policy"
+)
+
+// DynamicWeightPolicy自定义资源的GVR
+// 资源的spec字段与DynamicWeightArgs的JSON结构一致（结构化字段，非内嵌JSON字符串）
+var dynamicWeightPolicyGVR = schema.GroupVersionResource{
+	Group:    "scheduling.dynamicweight.io",
+	Version:  "v1alpha1",
+	Resource: "dynamicweightpolicies",
+}
+
+// crdWeightLoader 从DynamicWeightPolicy自定义资源读取权重配置
+// 相比ConfigMap内嵌JSON，自定义资源有结构化字段，便于GitOps管理与API层校验
+type crdWeightLoader struct {
+	client    dynamic.Interface // 动态客户端（访问自定义资源）
+	namespace string            // 自定义资源所在命名空间
+	name      string            // 自定义资源名称
+	// 当前生效的配置：重载时整体原子替换，读取方拿到的指针指向不可变内容
+	args atomic.Pointer[DynamicWeightArgs]
+	// 最近一次策略应用是否成功（供健康检查与告警仪表读取）
+	healthy atomic.Bool
+}
+
+// NewCRDWeightLoader 创建基于自定义资源的配置加载器
+// namespace/name指定DynamicWeightPolicy的位置，为空时使用默认值
+// ctx由插件生命周期持有，取消时后台监听协程随之退出
+func NewCRDWeightLoader(ctx context.Context, client dynamic.Interface, namespace, name string) (WeightLoader, error) {
+	if namespace == "" {
+		namespace = policyNamespace
+	}
+	if name == "" {
+		name = policyName
+	}
+	wl := &crdWeightLoader{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	// 初始加载配置：与ConfigMap加载器一致，仅首次失败视为致命错误
+	if err := wl.loadPolicy(); err != nil {
+		return nil, fmt.Errorf("failed to load initial policy: %v", err)
+	}
+
+	// 启动后台监听协程
+	go wl.startInformer(ctx)
+
+	return wl, nil
+}
+
+// 获取当前配置（线程安全，只读）
+// 配置在两次重载之间不可变：直接返回原子指针，省去逐调用的深拷贝
+func (wl *crdWeightLoader) GetWeights() *DynamicWeightArgs {
+	return wl.args.Load()
+}
+
+// 加载自定义资源并解析spec为配置
+func (wl *crdWeightLoader) loadPolicy() error {
+	obj, err := wl.client.Resource(dynamicWeightPolicyGVR).Namespace(wl.namespace).Get(
+		context.Background(),
+		wl.name,
+		metav1.GetOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("error fetching DynamicWeightPolicy %s/%s: %v",
+			wl.namespace, wl.name, err)
+	}
+	return wl.applyPolicy(obj)
+}
+
+// applyPolicy 解析spec字段并在校验通过后替换当前配置
+// spec字段名与DynamicWeightArgs的JSON标签一致，经JSON转一道完成映射，
+// 校验逻辑与ConfigMap路径共享（Validate）
+func (wl *crdWeightLoader) applyPolicy(obj *unstructured.Unstructured) error {
+	spec, found, err := unstructured.NestedMap(obj.Object, "spec")
+	if err != nil {
+		return fmt.Errorf("error reading policy spec: %v", err)
+	}
+	if !found {
+		return fmt.Errorf("DynamicWeightPolicy %s/%s missing spec", wl.namespace, wl.name)
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("error marshaling policy spec: %v", err)
+	}
+	newArgs := &DynamicWeightArgs{}
+	if err := json.Unmarshal(raw, newArgs); err != nil {
+		return fmt.Errorf("error unmarshaling policy spec: %v", err)
+	}
+
+	if err := newArgs.Validate(); err != nil {
+		return fmt.Errorf("invalid policy: %v", err)
+	}
+
+	// 原子替换整份配置：旧指针的持有者继续读旧内容，无需加锁
+	wl.args.Store(newArgs)
+	wl.healthy.Store(true)
+	configHealthy.Set(1)
+	return nil
+}
+
+// Healthy 报告最近一次策略应用是否成功
+func (wl *crdWeightLoader) Healthy() bool {
+	return wl.healthy.Load()
+}
+
+// 启动监听自定义资源变更的后台协程
+// ctx取消（插件关闭）时informer停止、本协程退出，不再泄漏
+func (wl *crdWeightLoader) startInformer(ctx context.Context) {
+	args := wl.GetWeights()
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		wl.client,
+		jitteredPeriod(args.ResyncPeriod.Duration, args.ResyncJitterFactor),
+		wl.namespace,
+		func(options *metav1.ListOptions) {
+			options.FieldSelector = "metadata.name=" + wl.name
+		},
+	)
+
+	// 注册变更回调
+	informer := factory.ForResource(dynamicWeightPolicyGVR).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPolicy := oldObj.(*unstructured.Unstructured)
+			newPolicy := newObj.(*unstructured.Unstructured)
+			if oldPolicy.GetResourceVersion() == newPolicy.GetResourceVersion() {
+				return
+			}
+			klog.InfoS("DynamicWeightPolicy updated, reloading weights")
+			if err := wl.applyPolicy(newPolicy); err != nil {
+				// 热更新失败不影响运行：未通过校验不替换wl.args，
+				// 继续使用上一份有效配置，仅记录失败指标供告警
+				configReloadFailures.Inc()
+				wl.healthy.Store(false)
+				configHealthy.Set(0)
+				klog.ErrorS(err, "Failed to reload policy, keeping last-good config")
+			}
+		},
+	})
+
+	// 启动监听：informer的生命周期挂在插件ctx上
+	factory.Start(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+
+	<-ctx.Done() // 阻塞直到插件关闭
+}
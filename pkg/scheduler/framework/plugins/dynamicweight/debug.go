@@ -0,0 +1,99 @@
+// pkg/scheduler/framework/plugins/dynamicweight/debug.go
+package dynamicweight
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// debugNodeEntry 调试端点中单个节点的缓存快照
+// AgeSeconds按资源维度给出数据年龄，排障时可直接看出哪个维度的数据陈旧
+type debugNodeEntry struct {
+	Usage      map[string]float64 `json:"usage"`
+	AgeSeconds map[string]float64 `json:"ageSeconds"`
+}
+
+// debugDump 调试端点的响应结构
+// 当前生效配置 + 缓存全量快照，生产排障不再靠猜测插件内部状态
+type debugDump struct {
+	Config        *DynamicWeightArgs        `json:"config"`
+	ConfigHealthy bool                      `json:"configHealthy"`
+	Nodes         map[string]debugNodeEntry `json:"nodes"`
+}
+
+// debugHandler 返回输出当前权重配置与缓存快照的HTTP处理器
+func (d *DynamicWeight) debugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := d.now()
+		dump := debugDump{
+			Nodes: map[string]debugNodeEntry{},
+		}
+		if d.weightLoader != nil {
+			dump.Config = d.weightLoader.GetWeights()
+			dump.ConfigHealthy = d.weightLoader.Healthy()
+		}
+		if d.cache != nil {
+			for node, usage := range d.cache.Snapshot() {
+				entry := debugNodeEntry{
+					Usage:      map[string]float64{},
+					AgeSeconds: map[string]float64{},
+				}
+				if usage.Metrics != nil {
+					for res, v := range usage.Metrics {
+						entry.Usage[res] = v
+						ts := usage.Timestamp
+						if t, ok := usage.ResourceTimestamps[res]; ok {
+							ts = t
+						}
+						entry.AgeSeconds[res] = now.Sub(ts).Seconds()
+					}
+				} else {
+					// 旧式整条写入：输出内置维度，年龄统一取条目时间戳
+					age := now.Sub(usage.Timestamp).Seconds()
+					for res, v := range map[string]float64{
+						"cpu": usage.CPU, "memory": usage.Memory, "diskio": usage.DiskIO,
+						"netio": usage.Network, "netrx": usage.NetworkRx, "nettx": usage.NetworkTx,
+						"gpu": usage.GPU,
+					} {
+						entry.Usage[res] = v
+						entry.AgeSeconds[res] = age
+					}
+				}
+				dump.Nodes[node] = entry
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(dump); err != nil {
+			klog.V(2).InfoS("调试端点写出响应失败", "err", err)
+		}
+	})
+}
+
+// startDebugServer 在配置的地址上启动调试HTTP服务
+// 端点暴露集群内部状态（配置与各节点负载），默认关闭；
+// 建议只监听本机回环地址。ctx取消（插件关闭）时服务随之退出
+func (d *DynamicWeight) startDebugServer(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/dynamicweight", d.debugHandler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		klog.InfoS("DynamicWeight调试端点已启动", "addr", addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			klog.ErrorS(err, "DynamicWeight调试端点退出")
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+}
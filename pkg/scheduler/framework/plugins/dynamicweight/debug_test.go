@@ -0,0 +1,81 @@
+// pkg/scheduler/framework/plugins/dynamicweight/debug_test.go
+package dynamicweight
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestDebugHandler 验证调试端点输出当前配置与缓存快照的JSON结构
+func TestDebugHandler(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 0.6, "memory": 0.4},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", &NodeUsage{
+		CPU:     0.3,
+		Metrics: map[string]float64{"cpu": 0.3, "memory": 0.7},
+	})
+
+	recorder := httptest.NewRecorder()
+	d.debugHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/dynamicweight", nil))
+
+	if recorder.Code != 200 {
+		t.Fatalf("状态码 = %d, 期望 200", recorder.Code)
+	}
+	if ct := recorder.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, 期望 application/json", ct)
+	}
+
+	var dump struct {
+		Config *struct {
+			DefaultWeights map[string]float64 `json:"defaultWeights"`
+		} `json:"config"`
+		ConfigHealthy bool `json:"configHealthy"`
+		Nodes         map[string]struct {
+			Usage      map[string]float64 `json:"usage"`
+			AgeSeconds map[string]float64 `json:"ageSeconds"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &dump); err != nil {
+		t.Fatalf("响应不是合法JSON: %v\n%s", err, recorder.Body.String())
+	}
+
+	if dump.Config == nil || dump.Config.DefaultWeights["cpu"] != 0.6 {
+		t.Errorf("config.defaultWeights = %+v, 期望含cpu:0.6", dump.Config)
+	}
+	if !dump.ConfigHealthy {
+		t.Error("configHealthy应为true")
+	}
+	entry, ok := dump.Nodes["node-1"]
+	if !ok {
+		t.Fatalf("nodes缺少node-1: %+v", dump.Nodes)
+	}
+	if entry.Usage["cpu"] != 0.3 || entry.Usage["memory"] != 0.7 {
+		t.Errorf("node-1 usage = %+v, 期望cpu:0.3 memory:0.7", entry.Usage)
+	}
+	age, ok := entry.AgeSeconds["cpu"]
+	if !ok || age < 0 || age > 60 {
+		t.Errorf("node-1 cpu数据年龄 = %v (存在=%v), 期望刚写入的小值", age, ok)
+	}
+}
+
+// TestDebugHandlerEmptyState 验证无配置加载器/空缓存时端点仍可用
+func TestDebugHandlerEmptyState(t *testing.T) {
+	d := &DynamicWeight{}
+
+	recorder := httptest.NewRecorder()
+	d.debugHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/dynamicweight", nil))
+
+	if recorder.Code != 200 {
+		t.Fatalf("状态码 = %d, 期望 200", recorder.Code)
+	}
+	var dump map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &dump); err != nil {
+		t.Fatalf("响应不是合法JSON: %v", err)
+	}
+}
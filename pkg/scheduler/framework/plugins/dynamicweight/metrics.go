@@ -0,0 +1,201 @@
+// pkg/scheduler/framework/plugins/dynamicweight/metrics.go
+package dynamicweight
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// 插件指标的子系统名称
+const metricsSubsystem = "dynamicweight"
+
+var (
+	// cacheSize 当前节点使用率缓存条目数
+	// 运维可据此观察缓存是否频繁淘汰（抖动）
+	cacheSize = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_size",
+			Help:           "Current number of entries in the node usage cache.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// cacheHits/cacheMisses 节点使用率缓存的命中/未命中计数
+	// 两者比值反映缓存是否真正减少了Prometheus查询
+	cacheHits = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_hits_total",
+			Help:           "Total number of node usage cache hits.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	cacheMisses = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "cache_misses_total",
+			Help:           "Total number of node usage cache misses.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// queryTimeouts 指标拉取超时次数
+	// 超时不报错（按降级/中性分处理），该计数是发现Prometheus变慢的唯一信号
+	queryTimeouts = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "prometheus_query_timeouts_total",
+			Help:           "Total number of metric fetch rounds aborted by the query timeout.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// configReloadFailures 配置热更新失败次数
+	// 持续增长说明ConfigMap被改坏（JSON语法或校验错误），当前仍在用旧配置
+	configReloadFailures = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "config_reload_failures_total",
+			Help:           "Total number of failed weight config reloads; the last-good config stays active.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// configGeneration/configLastReload 配置生效确认仪表
+	// generation在每次成功加载/重载时加一，时间戳记录最近一次成功时刻；
+	// 与重载失败计数配合，运维编辑ConfigMap后可确认改动是否已生效
+	configGeneration = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "config_generation",
+			Help:           "Number of successful weight config loads since scheduler start; increments when an edited config takes effect.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	configLastReload = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "config_last_reload_timestamp_seconds",
+			Help:           "Unix timestamp of the last successful weight config load.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// queryDuration Prometheus查询时延直方图（按资源维度）
+	// 用于将调度Score耗时与慢PromQL查询关联
+	queryDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "prometheus_query_duration_seconds",
+			Help:           "Duration of Prometheus queries, by resource dimension.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 12),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"resource"},
+	)
+
+	// nodeScore 节点最终得分分布（按resource-prefer标签取值）
+	// 用于观察各偏好下得分是否扎堆（区分度不足）或长期偏低（集群整体繁忙）
+	nodeScore = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_score",
+			Help:           "Distribution of node scores produced by the plugin, by resource-prefer label value.",
+			Buckets:        metrics.LinearBuckets(0, 10, 11),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"prefer"},
+	)
+
+	// warmupComplete 预热宽限期是否结束（1=已按真实指标评分，0=预热中）
+	// 启动后长期为0说明warmupPeriod配置过长或时钟异常
+	warmupComplete = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "warmup_complete",
+			Help:           "Whether the warm-up grace period has completed (1) and real scoring is active.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// weightSourceTotal 各权重来源被评分决策选用的次数
+	// （default/scheduled/label/namespace/node-annotation）
+	// 排查"Pod为何落到某节点"时可直接看出权重取自哪条配置路径
+	weightSourceTotal = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "weight_source_total",
+			Help:           "Total number of scoring decisions by selected weight source.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"source"},
+	)
+
+	// scoringErrors 评分失败次数（仅fail-closed模式会走到错误路径）
+	scoringErrors = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "scoring_errors_total",
+			Help:           "Total number of scoring failures, by resource-prefer label value.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"prefer"},
+	)
+
+	// configHealthy/prometheusHealthy 健康状态仪表（1健康/0异常）
+	// 配置重载失败或Prometheus持续查询失败都会让插件静默退化为
+	// 全降级值评分，该仪表是运维告警的入口
+	configHealthy = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "config_healthy",
+			Help:           "Whether the last weight config reload succeeded (1) or failed (0).",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+	prometheusHealthy = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "prometheus_healthy",
+			Help:           "Whether the most recent Prometheus query succeeded within the freshness window (1) or not (0).",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	// breakerState Prometheus查询熔断器状态（0关闭/1打开/2半开）
+	// 长时间处于1说明监控侧故障，插件正在用中性分快速放行
+	breakerState = metrics.NewGauge(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "circuit_breaker_state",
+			Help:           "State of the Prometheus query circuit breaker (0=closed, 1=open, 2=half-open).",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+
+	registerMetricsOnce sync.Once
+)
+
+// registerMetrics 将插件指标注册到调度器的指标注册表（幂等）
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		legacyregistry.MustRegister(cacheSize)
+		legacyregistry.MustRegister(cacheHits)
+		legacyregistry.MustRegister(cacheMisses)
+		legacyregistry.MustRegister(configReloadFailures)
+		legacyregistry.MustRegister(configGeneration)
+		legacyregistry.MustRegister(configLastReload)
+		legacyregistry.MustRegister(queryTimeouts)
+		legacyregistry.MustRegister(queryDuration)
+		legacyregistry.MustRegister(nodeScore)
+		legacyregistry.MustRegister(warmupComplete)
+		legacyregistry.MustRegister(weightSourceTotal)
+		legacyregistry.MustRegister(scoringErrors)
+		legacyregistry.MustRegister(configHealthy)
+		legacyregistry.MustRegister(prometheusHealthy)
+		legacyregistry.MustRegister(breakerState)
+	})
+}
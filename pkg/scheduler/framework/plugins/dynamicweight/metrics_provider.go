@@ -0,0 +1,84 @@
+// pkg/scheduler/framework/plugins/dynamicweight/metrics_provider.go
+package dynamicweight
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
+)
+
+// MetricsProvider 节点使用率数据源的抽象
+// Prometheus为主源（覆盖全部维度），metrics-server为备源（仅CPU/内存）；
+// 主源整轮失败时updateNodeUsage按备源能提供的维度降级补数，
+// 保证Prometheus故障期间CPU/内存评分仍然可用
+type MetricsProvider interface {
+	// NodeUsage 返回节点各维度的0-1使用率及各维度的采样完成时刻
+	// 数据源不支持或暂无数据的维度从返回值中缺席，由评分时重归一化兜底；
+	// 降级默认值顶替的维度有使用率而无采样时刻，缓存据此将其标记为
+	// 已过期，驱动增量刷新持续重查
+	NodeUsage(ctx context.Context, node *v1.Node) (map[string]float64, map[string]time.Time, error)
+}
+
+// prometheusProvider 主数据源：复用插件既有的PromQL逐维度查询路径
+type prometheusProvider struct {
+	d *DynamicWeight
+}
+
+func (p *prometheusProvider) NodeUsage(ctx context.Context, node *v1.Node) (map[string]float64, map[string]time.Time, error) {
+	nodeIP, err := p.d.nodeInstanceAddress(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.d.queryUsageMetrics(ctx, nodeIP, node, nil)
+}
+
+// metricsServerProvider 备数据源：经metrics-server的节点指标API取数
+// 只有CPU/内存两个维度，使用量按节点Allocatable折算为0-1使用率
+type metricsServerProvider struct {
+	client resourceclient.NodeMetricsesGetter
+}
+
+func (p *metricsServerProvider) NodeUsage(ctx context.Context, node *v1.Node) (map[string]float64, map[string]time.Time, error) {
+	nm, err := p.client.NodeMetricses().Get(ctx, node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取节点指标失败: %v", err)
+	}
+
+	sampled := time.Now()
+	usage := make(map[string]float64, 2)
+	sampledAt := make(map[string]time.Time, 2)
+	if alloc := node.Status.Allocatable.Cpu().AsApproximateFloat64(); alloc > 0 {
+		usage["cpu"] = clampUsage(nm.Usage.Cpu().AsApproximateFloat64() / alloc)
+		sampledAt["cpu"] = sampled
+	}
+	if alloc := node.Status.Allocatable.Memory().AsApproximateFloat64(); alloc > 0 {
+		usage["memory"] = clampUsage(nm.Usage.Memory().AsApproximateFloat64() / alloc)
+		sampledAt["memory"] = sampled
+	}
+	if len(usage) == 0 {
+		return nil, nil, fmt.Errorf("节点 %s 无可折算的Allocatable容量", node.Name)
+	}
+	return usage, sampledAt, nil
+}
+
+// usageFromMetrics 把维度使用率映射组装为NodeUsage
+// 同步填充命名字段，保持既有访问方式兼容；sampledAt为各维度的采样
+// 完成时刻，随条目写入缓存后驱动按维度过期与增量刷新
+func usageFromMetrics(usageMetrics map[string]float64, sampledAt map[string]time.Time) *NodeUsage {
+	return &NodeUsage{
+		CPU:       usageMetrics["cpu"],
+		Memory:    usageMetrics["memory"],
+		DiskIO:    usageMetrics["diskio"],
+		Network:   usageMetrics["netio"],
+		NetworkRx: usageMetrics["netrx"],
+		NetworkTx: usageMetrics["nettx"],
+		GPU:       usageMetrics["gpu"],
+		Metrics:   usageMetrics,
+
+		ResourceTimestamps: sampledAt,
+	}
+}
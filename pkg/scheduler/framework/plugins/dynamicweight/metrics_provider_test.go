@@ -0,0 +1,163 @@
+// pkg/scheduler/framework/plugins/dynamicweight/metrics_provider_test.go
+package dynamicweight
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	metricsfake "k8s.io/metrics/pkg/client/clientset/versioned/fake"
+)
+
+// fakeSecondaryProvider 备用数据源桩：返回预设值或错误，并记录调用次数
+type fakeSecondaryProvider struct {
+	values map[string]float64
+	err    error
+	calls  int
+}
+
+// 备源桩不提供采样时刻：走旧式整条时间戳路径
+func (f *fakeSecondaryProvider) NodeUsage(ctx context.Context, node *v1.Node) (map[string]float64, map[string]time.Time, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	return f.values, nil, nil
+}
+
+func newProviderTestNode(name string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+}
+
+// 验证主源正常时不触碰备源
+func TestUpdateNodeUsagePrimaryOnly(t *testing.T) {
+	secondary := &fakeSecondaryProvider{values: map[string]float64{"cpu": 0.9}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient: &fakePromAPI{values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		}},
+		cache:     NewNodeUsageCache(5*time.Minute, 0),
+		secondary: secondary,
+	}
+
+	usage, err := d.updateNodeUsage(context.Background(), newProviderTestNode("node-1"))
+	if err != nil {
+		t.Fatalf("updateNodeUsage失败: %v", err)
+	}
+	if usage.CPU != 0.5 {
+		t.Errorf("cpu = %v, 期望主源的 0.5", usage.CPU)
+	}
+	if secondary.calls != 0 {
+		t.Errorf("主源正常时不应查询备源，实际调用 %d 次", secondary.calls)
+	}
+}
+
+// 验证主源失败时退到备源：仅CPU/内存维度可用，结果写入缓存
+func TestUpdateNodeUsageSecondaryFallback(t *testing.T) {
+	secondary := &fakeSecondaryProvider{values: map[string]float64{"cpu": 0.3, "memory": 0.6}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient:   &fakePromAPI{}, // 无预设值：任何查询都失败
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+		secondary:    secondary,
+	}
+
+	usage, err := d.updateNodeUsage(context.Background(), newProviderTestNode("node-1"))
+	if err != nil {
+		t.Fatalf("备源可用时不应报错: %v", err)
+	}
+	if usage.CPU != 0.3 || usage.Memory != 0.6 {
+		t.Errorf("cpu/memory = %v/%v, 期望备源的 0.3/0.6", usage.CPU, usage.Memory)
+	}
+	if _, ok := usage.Get("diskio"); ok {
+		t.Error("备源不支持的维度不应出现在结果中")
+	}
+	if d.cache.Get("node-1") == nil {
+		t.Error("备源结果应写入缓存")
+	}
+	if secondary.calls != 1 {
+		t.Errorf("备源调用次数 = %d, 期望 1", secondary.calls)
+	}
+}
+
+// 验证主备均不可用时维持主源错误（调用方按既有降级路径处理）
+func TestUpdateNodeUsageBothProvidersDown(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient:   &fakePromAPI{},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+		secondary:    &fakeSecondaryProvider{err: errors.New("metrics-server不可达")},
+	}
+
+	if _, err := d.updateNodeUsage(context.Background(), newProviderTestNode("node-1")); err == nil {
+		t.Fatal("主备均失败应返回错误")
+	}
+	if d.cache.Get("node-1") != nil {
+		t.Error("主备均失败不应写入缓存")
+	}
+}
+
+// 验证metrics-server实现按Allocatable把使用量折算为0-1使用率
+func TestMetricsServerProvider(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("4"),
+				v1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+	}
+	// NodeMetrics的资源名是"nodes"，与GVK推断的复数形式不一致，
+	// 须经tracker按显式GVR注入，NewSimpleClientset直接传对象读不回来
+	client := metricsfake.NewSimpleClientset()
+	err := client.Tracker().Create(
+		metricsv1beta1.SchemeGroupVersion.WithResource("nodes"),
+		&metricsv1beta1.NodeMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Usage: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("2"),
+				v1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		}, "")
+	if err != nil {
+		t.Fatalf("注入NodeMetrics失败: %v", err)
+	}
+	provider := &metricsServerProvider{client: client.MetricsV1beta1()}
+
+	usage, sampledAt, err := provider.NodeUsage(context.Background(), node)
+	if err != nil {
+		t.Fatalf("NodeUsage失败: %v", err)
+	}
+	if usage["cpu"] != 0.5 {
+		t.Errorf("cpu = %v, 期望 2/4 = 0.5", usage["cpu"])
+	}
+	if usage["memory"] != 0.5 {
+		t.Errorf("memory = %v, 期望 4Gi/8Gi = 0.5", usage["memory"])
+	}
+	for res := range usage {
+		if sampledAt[res].IsZero() {
+			t.Errorf("维度%s缺少采样时刻", res)
+		}
+	}
+
+	// 节点指标缺失：返回错误，由updateNodeUsage维持主源错误
+	if _, _, err := provider.NodeUsage(context.Background(), newProviderTestNode("node-x")); err == nil {
+		t.Error("无指标的节点应返回错误")
+	}
+}
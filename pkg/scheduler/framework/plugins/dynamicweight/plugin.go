@@ -3,21 +3,40 @@ package dynamicweight
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+
+	"strings"
+
 	"github.com/prometheus/common/model"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/names"
-	//"strings"
-	//"sync"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	prometheus "github.com/prometheus/client_golang/api"
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	clientgocache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
-	//"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
 )
 
 const (
@@ -30,8 +49,90 @@ type DynamicWeight struct {
 	handle       framework.Handle // 调度器上下文，提供集群状态访问
 	weightLoader WeightLoader     // 配置加载器（从ConfigMap读取）
 	//metrics      *MetricsClient
-	promClient promv1.API      // Prometheus查询客户端
-	cache      *NodeUsageCache // 节点资源使用率缓存
+	promClient promv1.API             // Prometheus查询客户端
+	cache      *NodeUsageCache        // 节点资源使用率缓存
+	nodeLister corelisters.NodeLister // 节点Lister（后台刷新缓存用）
+
+	recorder events.EventRecorder // 事件记录器（指标降级告知运维）
+
+	// 指标降级状态：节点名 -> 是否处于降级中
+	// 只在状态翻转时发事件（每节点限流），避免Prometheus故障时刷屏
+	degradedMu    sync.Mutex
+	degradedNodes map[string]bool
+
+	// instance标识缓存：节点名 -> 已解析的instance地址
+	// 节点地址在生命周期内基本不变，避免逐次扫描Status.Addresses；
+	// InstanceLabelSource热更新时整体失效，节点下线时逐项清理
+	instanceMu         sync.Mutex
+	instanceAddrs      map[string]string
+	instanceAddrSource string
+
+	// Prometheus查询健康状态：是否查询过、最近一次是否成功及成功时刻
+	// 供Healthy()判断插件是否已静默退化为全降级值评分
+	queriedOnce          atomic.Bool
+	lastQueryOK          atomic.Bool
+	lastQuerySuccessNano atomic.Int64
+
+	// 时钟注入点：时段权重按它取当前时间，测试可注入固定时钟
+	timeNow func() time.Time
+
+	// 插件启动时刻：预热期判定基准，零值视为无预热（测试直接构造结构体）
+	startedAt time.Time
+
+	// Prometheus查询熔断器：故障期短路查询，保护调度吞吐
+	breaker *circuitBreaker
+
+	// 备用指标数据源（metrics-server）：主源整轮失败时按其支持的维度补数
+	// 未配置时为nil，主源失败直接走既有降级路径
+	secondary MetricsProvider
+
+	// 在途Pod预留账本：节点名 -> Pod UID -> 预留记录
+	// Reserve登记、Unreserve（绑定失败）回退；真实指标大约在一个
+	// 刷新周期加rate窗口后才反映新Pod负载，条目在此之前参与评分
+	reservedMu   sync.Mutex
+	reservedPods map[string]map[types.UID]podReservation
+}
+
+// podReservation 单个在途Pod的预留记录
+type podReservation struct {
+	// 各维度按节点Allocatable折算的使用率增量
+	fractions map[string]float64
+	// 登记时刻，超过保留时长后视为真实指标已赶上，不再计入
+	at time.Time
+}
+
+// 缓存未命中时返回的中性分，避免在调度关键路径上同步查询Prometheus
+const neutralScore = 50
+
+// 后台缓存刷新的默认间隔
+const defaultRefreshInterval = 30 * time.Second
+
+// 缓存条目数上限，超过时按LRU淘汰
+const defaultCacheMaxEntries = 5000
+
+// 缓存条目的默认有效期（可用CacheTTL覆盖）
+const defaultCacheTTL = 5 * time.Minute
+
+// 缓存过期条目清理协程的执行间隔
+const defaultCacheGCInterval = time.Minute
+
+// newDynamicWeight 组装插件实例
+// 独立于New的装配函数：promClient等依赖均由参数注入，
+// 测试可传入桩实现离线运行评分逻辑，不触达真实Prometheus
+func newDynamicWeight(h framework.Handle, loader WeightLoader, promClient promv1.API,
+	cache *NodeUsageCache, nodeLister corelisters.NodeLister, recorder events.EventRecorder) *DynamicWeight {
+	return &DynamicWeight{
+		handle:        h,
+		weightLoader:  loader,
+		promClient:    promClient,
+		cache:         cache,
+		nodeLister:    nodeLister,
+		recorder:      recorder,
+		degradedNodes: make(map[string]bool),
+		timeNow:       time.Now,
+		startedAt:     time.Now(),
+		breaker:       newCircuitBreaker(),
+	}
 }
 
 // Name 必须实现framework.Plugin接口
@@ -41,14 +142,347 @@ func (d *DynamicWeight) Name() string {
 }
 
 // 实现所有必需接口方法
-var _ framework.ScorePlugin = &DynamicWeight{} // 实现评分插件接口
-var _ framework.Plugin = &DynamicWeight{}      // 实现基础插件接口
+var _ framework.ScorePlugin = &DynamicWeight{}     // 实现评分插件接口
+var _ framework.PreScorePlugin = &DynamicWeight{}  // 实现预评分插件接口（批量预取指标）
+var _ framework.FilterPlugin = &DynamicWeight{}    // 实现过滤插件接口（硬性使用率上限）
+var _ framework.PreFilterPlugin = &DynamicWeight{} // 实现预过滤插件接口（资源偏好标签校验）
+var _ framework.ReservePlugin = &DynamicWeight{}   // 实现预留插件接口（在途Pod计入有效使用率）
+var _ framework.Plugin = &DynamicWeight{}          // 实现基础插件接口
 
-// ScoreExtensions 实现Score扩展接口
+// ScoreExtensions 实现Score扩展接口（得分量化）
 func (d *DynamicWeight) ScoreExtensions() framework.ScoreExtensions {
+	return d
+}
+
+// NormalizeScore 按配置的桶宽量化各节点得分
+// 指标的细微抖动会让榜首节点在调度周期间来回翻转，放置模式不稳定；
+// 得分圆整到最近的ScoreQuantization倍数后，小幅使用率变化不再
+// 改变节点排序。桶宽0（默认）或1不做量化
+func (d *DynamicWeight) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	var bucket int64
+	if d.weightLoader != nil {
+		bucket = d.weightLoader.GetWeights().ScoreQuantization
+	}
+	if bucket <= 1 {
+		return nil
+	}
+	for i := range scores {
+		quantized := (scores[i].Score + bucket/2) / bucket * bucket
+		if quantized > framework.MaxNodeScore {
+			quantized = framework.MaxNodeScore
+		}
+		scores[i].Score = quantized
+	}
+	return nil
+}
+
+// CycleState中批量预取结果的键名（调度框架插件惯例：PreScore+插件名）
+const preScoreStateKey = "PreScore" + Name
+
+// CycleState中预过滤诊断信息的键
+const preFilterStateKey = "PreFilter" + Name
+
+// 评分解释在CycleState中的键前缀：Score按节点并行执行，按节点名分键
+// 避免并发写同一条目
+const scoreBreakdownKeyPrefix = "ScoreBreakdown" + Name + "/"
+
+// scoreBreakdownStateKey 返回指定节点的评分解释状态键
+func scoreBreakdownStateKey(nodeName string) framework.StateKey {
+	return framework.StateKey(scoreBreakdownKeyPrefix + nodeName)
+}
+
+// preFilterState 资源偏好标签校验的诊断结果，供排障时读取
+type preFilterState struct {
+	unrecognized []string // 未匹配任何LabelWeights条目的标签取值
+}
+
+// Clone 实现framework.StateData接口；结果只读，直接返回自身
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// preScoreState PreScore批量查询的结果，供同一调度周期内的Score读取
+type preScoreState struct {
+	usages map[string]*NodeUsage // 节点名 -> 使用率
+}
+
+// Clone 实现framework.StateData接口；结果只读，直接返回自身
+func (s *preScoreState) Clone() framework.StateData {
+	return s
+}
+
+// scoreBreakdownState 单节点的得分构成：资源维度 -> 对最终得分的贡献（0-100分制）
+// ExplainScores开启时由Score写入，经ScoreBreakdown读取
+type scoreBreakdownState struct {
+	contributions map[string]float64
+}
+
+// Clone 实现framework.StateData接口；结果只读，直接返回自身
+func (s *scoreBreakdownState) Clone() framework.StateData {
+	return s
+}
+
+// 批量查询模板：一次查询覆盖全部候选节点，按instance分组返回
+// {instances}占位符替换为候选节点IP的正则交替组（如"(ip1|ip2)(:.*)?"）
+var batchQueryTemplates = map[string]string{
+	"cpu": `sum by (instance) (rate(node_cpu_seconds_total{mode!="idle", instance=~"{instances}"}[{window}]))
+                            / count by (instance) (node_cpu_seconds_total{mode="user", instance=~"{instances}"})`,
+
+	"memory": `(node_memory_MemTotal_bytes{instance=~"{instances}"}
+                            - node_memory_MemAvailable_bytes{instance=~"{instances}"})
+                            / node_memory_MemTotal_bytes{instance=~"{instances}"}`,
+
+	"diskio": `sum by (instance) (rate(node_disk_io_time_seconds_total{device=~"{device}", instance=~"{instances}"}[{window}]))`,
+
+	"netrx": `sum by (instance) (rate(node_network_receive_bytes_total{device=~"{iface}", instance=~"{instances}"}[{window}]) * 8
+                            / (node_network_speed_bytes{device=~"{iface}", instance=~"{instances}"}))`,
+
+	"nettx": `sum by (instance) (rate(node_network_transmit_bytes_total{device=~"{iface}", instance=~"{instances}"}[{window}]) * 8
+                            / (node_network_speed_bytes{device=~"{iface}", instance=~"{instances}"}))`,
+
+	"gpu": `avg by (instance) (DCGM_FI_DEV_GPU_UTIL{instance=~"{instances}"}) / 100`,
+}
+
+// PreScore 批量预取全部候选节点的使用率，写入CycleState
+// 每个资源维度只发一次按instance分组的聚合查询，避免大集群下
+// Score逐节点逐维度地发起大量PromQL查询
+// 批量查询失败不阻塞调度：返回成功，Score回退到逐节点缓存路径
+func (d *DynamicWeight) PreScore(ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod,
+	nodes []*framework.NodeInfo) *framework.Status {
+
+	// 批量路径不适用（自定义查询模板/匹配表达式）时直接跳过，
+	// Score走逐节点缓存路径
+	if !d.batchQueryUsable() {
+		return nil
+	}
+
+	// 收集候选节点的instance标识（来源由InstanceLabelSource配置）
+	// 命中排除选择器的节点不查询（Score对其恒返回中性分）
+	var exclude labels.Selector
+	if d.weightLoader != nil {
+		exclude = excludeSelector(d.weightLoader.GetWeights())
+	}
+	ipToName := make(map[string]string, len(nodes))
+	quotedIPs := make([]string, 0, len(nodes))
+	for _, nodeInfo := range nodes {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		if exclude != nil && exclude.Matches(labels.Set(node.Labels)) {
+			continue
+		}
+		instance, err := d.nodeInstanceAddress(node)
+		if err != nil {
+			klog.V(4).InfoS("节点缺少instance标识，跳过批量预取", "node", node.Name, "err", err)
+			continue
+		}
+		ipToName[instance] = node.Name
+		quotedIPs = append(quotedIPs, regexp.QuoteMeta(instance))
+	}
+	if len(quotedIPs) == 0 {
+		return nil
+	}
+
+	usages, err := d.batchQueryNodeUsage(ctx, quotedIPs, ipToName)
+	if err != nil {
+		// 回退：不写CycleState，Score走逐节点缓存路径
+		klog.V(2).InfoS("批量预取指标失败，回退到逐节点路径", "err", err)
+		return nil
+	}
+
+	state.Write(preScoreStateKey, &preScoreState{usages: usages})
 	return nil
 }
 
+// preScoredUsage 读取PreScore写入CycleState的批量预取结果
+// 无状态（未执行PreScore/批量失败/节点无样本）时返回nil，由调用方回退
+func (d *DynamicWeight) preScoredUsage(state *framework.CycleState, nodeName string) *NodeUsage {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(preScoreStateKey)
+	if err != nil {
+		return nil
+	}
+	s, ok := data.(*preScoreState)
+	if !ok {
+		return nil
+	}
+	return s.usages[nodeName]
+}
+
+// batchQueryUsable 判断批量查询路径是否适用
+// 自定义查询模板没有按instance分组的批量形式；自定义的instance匹配
+// 表达式（InstanceMatcher）同样套不进按instance标签硬匹配的批量模板，
+// 按它配置的标签键发出的批量查询永远匹配不到样本。两者任一配置时
+// 改走逐节点查询路径，保证查询语义一致
+func (d *DynamicWeight) batchQueryUsable() bool {
+	if d.weightLoader == nil {
+		return true
+	}
+	args := d.weightLoader.GetWeights()
+	return len(args.QueryTemplates) == 0 && args.InstanceMatcher == ""
+}
+
+// batchQueryNodeUsage 对全部候选节点执行各维度的批量查询
+// 核心维度（cpu/memory）查询失败返回错误；可选维度失败时整体取降级默认值
+func (d *DynamicWeight) batchQueryNodeUsage(ctx context.Context, quotedIPs []string, ipToName map[string]string) (map[string]*NodeUsage, error) {
+	// 批量预取与逐节点查询共用熔断器：故障期整轮短路，计数同样按轮次
+	if d.breaker != nil && !d.breaker.allow() {
+		return nil, errBreakerOpen
+	}
+	usages, err := d.doBatchQueryNodeUsage(ctx, quotedIPs, ipToName)
+	if d.breaker != nil {
+		if err != nil {
+			d.breaker.onFailure()
+		} else {
+			d.breaker.onSuccess()
+		}
+	}
+	return usages, err
+}
+
+// doBatchQueryNodeUsage 批量查询轮次的实际执行体
+func (d *DynamicWeight) doBatchQueryNodeUsage(ctx context.Context, quotedIPs []string, ipToName map[string]string) (map[string]*NodeUsage, error) {
+	ctx, cancel := d.withQueryTimeout(ctx)
+	defer cancel()
+
+	instances := "(" + strings.Join(quotedIPs, "|") + ")(:.*)?"
+	diskDevice := d.resolveDiskDevice(nil)
+	netInterface := d.resolveNetworkInterface(nil)
+	rateWindow := d.resolveRateWindow()
+
+	// 资源维度 -> 节点名 -> 使用率
+	perResource := make(map[string]map[string]float64, len(batchQueryTemplates))
+	// 资源维度 -> 该维度批量查询的完成时刻（维度内所有节点共用）
+	sampleDone := make(map[string]time.Time, len(batchQueryTemplates))
+	for res, template := range batchQueryTemplates {
+		query := strings.ReplaceAll(template, "{instances}", instances)
+		query = strings.ReplaceAll(query, "{device}", diskDevice)
+		query = strings.ReplaceAll(query, "{iface}", netInterface)
+		query = strings.ReplaceAll(query, "{window}", rateWindow)
+
+		values, err := d.batchQuery(ctx, res, query, ipToName)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				queryTimeouts.Inc()
+				return nil, fmt.Errorf("批量指标拉取超时: %v", err)
+			}
+			if requiredResources[res] {
+				return nil, fmt.Errorf("批量%s查询失败: %v", res, err)
+			}
+			klog.V(4).InfoS("批量查询维度不可用，使用降级默认值", "resource", res, "err", err)
+			continue
+		}
+		perResource[res] = values
+		sampleDone[res] = time.Now()
+	}
+
+	// 按节点组装NodeUsage；缺少核心维度样本的节点跳过（Score回退）
+	usages := make(map[string]*NodeUsage, len(ipToName))
+	for _, nodeName := range ipToName {
+		usageMetrics := make(map[string]float64, len(batchQueryTemplates)+1)
+		sampledAt := make(map[string]time.Time, len(batchQueryTemplates)+1)
+		missingRequired := false
+		for res := range batchQueryTemplates {
+			if value, ok := perResource[res][nodeName]; ok {
+				usageMetrics[res] = clampUsage(value)
+				sampledAt[res] = sampleDone[res]
+				continue
+			}
+			if requiredResources[res] {
+				missingRequired = true
+				break
+			}
+			if fallback, ok := d.resolveFallbackUsage(res); ok {
+				usageMetrics[res] = fallback
+				// 与逐节点路径一致：gpu无样本属预期，降级中性值视为
+				// 有效采样；其余降级维度不记采样时刻，等待增量重查
+				if res == "gpu" {
+					sampledAt[res] = time.Now()
+				}
+			}
+		}
+		if missingRequired {
+			continue
+		}
+		if rx, ok := usageMetrics["netrx"]; ok {
+			if tx, ok := usageMetrics["nettx"]; ok {
+				usageMetrics["netio"] = rx + tx
+				if rxTs, ok := sampledAt["netrx"]; ok {
+					if txTs, ok := sampledAt["nettx"]; ok {
+						if txTs.Before(rxTs) {
+							sampledAt["netio"] = txTs
+						} else {
+							sampledAt["netio"] = rxTs
+						}
+					}
+				}
+			}
+		}
+		usages[nodeName] = &NodeUsage{
+			CPU:       usageMetrics["cpu"],
+			Memory:    usageMetrics["memory"],
+			DiskIO:    usageMetrics["diskio"],
+			Network:   usageMetrics["netio"],
+			NetworkRx: usageMetrics["netrx"],
+			NetworkTx: usageMetrics["nettx"],
+			GPU:       usageMetrics["gpu"],
+			Metrics:   usageMetrics,
+
+			ResourceTimestamps: sampledAt,
+			Timestamp:          time.Now(),
+		}
+	}
+	return usages, nil
+}
+
+// batchQuery 执行按instance分组的批量查询，按节点名返回各实例的值
+// 样本的instance标签可能携带端口（如"10.0.0.1:9100"、"[fd00::1]:9100"），
+// 解析出主机部分后匹配节点
+func (d *DynamicWeight) batchQuery(ctx context.Context, resource, query string, ipToName map[string]string) (map[string]float64, error) {
+	start := time.Now()
+	result, warnings, err := d.promClient.Query(ctx, query, time.Now())
+	queryDuration.WithLabelValues(resource).Observe(time.Since(start).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if len(warnings) > 0 {
+		klog.V(2).InfoS("Prometheus批量查询返回警告", "resource", resource, "warnings", warnings)
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("无效的查询结果格式")
+	}
+
+	maxAge := d.resolveMaxDataAge()
+	values := make(map[string]float64, len(vector))
+	for _, sample := range vector {
+		// 超龄样本跳过：该节点此维度按无样本处理（见queryPrometheusOnce）
+		if maxAge > 0 && time.Since(sample.Timestamp.Time()) > maxAge {
+			klog.V(4).InfoS("批量查询样本超龄，按缺失处理",
+				"resource", resource, "instance", sample.Metric["instance"])
+			continue
+		}
+		instance := string(sample.Metric["instance"])
+		if host, _, err := net.SplitHostPort(instance); err == nil {
+			instance = host
+		} else {
+			// 无端口形式：IPv6字面量可能仍带方括号，去掉后与
+			// nodeInstanceAddress返回的裸地址对齐
+			instance = strings.Trim(instance, "[]")
+		}
+		if nodeName, ok := ipToName[instance]; ok {
+			values[nodeName] = clampUsage(d.scaleMetric(resource, float64(sample.Value)))
+		}
+	}
+	return values, nil
+}
+
 // Score 核心评分逻辑：计算节点得分
 // 输入：
 //   - ctx: 上下文（用于超时控制）
@@ -59,18 +493,134 @@ func (d *DynamicWeight) ScoreExtensions() framework.ScoreExtensions {
 // 输出：
 //   - 节点得分（0-100分）
 //   - 错误状态（成功时为nil）
+//
+// PreFilter 提前校验Pod的resource-prefer标签取值
+// 标签值拼写错误时Score会静默回退默认权重，用户难以察觉；
+// 宽松模式（默认）对未识别的取值发Warning事件并写入CycleState诊断信息，
+// 严格模式（StrictLabelValidation）直接拒绝该Pod，暴露配置错误
+func (d *DynamicWeight) PreFilter(ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+
+	labelValue, exists := pod.Labels["resource-prefer"]
+	if !exists {
+		return nil, nil
+	}
+
+	args := d.weightLoader.GetWeights()
+	var unrecognized []string
+	for _, label := range strings.Split(labelValue, "_") {
+		if _, ok := args.LabelWeights[label]; !ok {
+			unrecognized = append(unrecognized, label)
+		}
+	}
+	if len(unrecognized) == 0 {
+		return nil, nil
+	}
+
+	// 诊断信息写入CycleState，后续扩展点与排障工具可读取
+	state.Write(preFilterStateKey, &preFilterState{unrecognized: unrecognized})
+
+	if args.StrictLabelValidation {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable,
+			fmt.Sprintf("resource-prefer标签包含未识别的取值 %v", unrecognized))
+	}
+	if d.recorder != nil {
+		d.recorder.Eventf(pod, nil, v1.EventTypeWarning, "UnknownResourcePreference", "Scheduling",
+			"resource-prefer values %v match no LabelWeights entry; falling back to default weights", unrecognized)
+	}
+	return nil, nil
+}
+
+// PreFilterExtensions 本插件的预过滤结果与Pod增删无关，无需增量更新
+func (d *DynamicWeight) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// Filter 按MaxUsage配置的硬性上限过滤过载节点
+// 任一监控维度超过上限即判为Unschedulable，避免把Pod调度到已经接近打满的节点
+// 只读PreScore/后台刷新填充的缓存，不在过滤路径上发起查询；
+// 缓存未命中或维度缺失时放行（与未配置该维度上限等价），保持默认行为不变
+func (d *DynamicWeight) Filter(ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod,
+	nodeInfo *framework.NodeInfo) *framework.Status {
+
+	maxUsage := d.weightLoader.GetWeights().MaxUsage
+	if len(maxUsage) == 0 {
+		return nil // 未配置上限：不过滤
+	}
+
+	nodeName := nodeInfo.Node().Name
+	usage := d.preScoredUsage(state, nodeName)
+	if usage == nil {
+		usage = d.cache.Get(nodeName)
+	}
+	if usage == nil {
+		return nil
+	}
+
+	for res, limit := range maxUsage {
+		if value, ok := usage.Get(res); ok && value > limit {
+			klog.V(4).InfoS("节点使用率超过硬性上限，过滤",
+				"pod", pod.Name, "node", nodeName,
+				"resource", res, "usage", value, "limit", limit)
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("节点 %s 的 %s 使用率 %.2f 超过上限 %.2f", nodeName, res, value, limit))
+		}
+	}
+	return nil
+}
+
 func (d *DynamicWeight) Score(ctx context.Context,
 	state *framework.CycleState,
 	pod *v1.Pod,
 	nodeName string) (int64, *framework.Status) {
 
+	// 上下文日志器：沿用调度周期携带的trace上下文，统一附加pod/node键值
+	logger := klog.LoggerWithValues(klog.FromContext(ctx), "pod", klog.KObj(pod), "node", nodeName)
+
 	// 1. 获取权重配置
 	args := d.weightLoader.GetWeights()
+	preferLabel := pod.Labels["resource-prefer"]
 
-	// 2. 获取节点实时指标
-	usage, err := d.getRealNodeUsage(nodeName)
-	if err != nil {
-		return 0, framework.AsStatus(fmt.Errorf("获取节点指标失败: %v", err))
+	// 1.2 预热期：启动初期缓存为空、Prometheus的rate窗口可能尚未填满，
+	// 此时的得分不可靠，统一给中性分，后台刷新照常填充缓存
+	if d.inWarmup(args) {
+		return resolveNeutralScore(args), nil
+	}
+
+	// 1.5 排除节点：命中ExcludeNodeSelector的节点不参与实时负载评分
+	// （控制面/专用节点池），直接返回中性分，连缓存也不读取
+	if selector := excludeSelector(args); selector != nil {
+		if node := d.scoredNode(nodeName); node != nil && selector.Matches(labels.Set(node.Labels)) {
+			neutral := resolveNeutralScore(args)
+			logger.V(4).Info("节点命中排除选择器，返回中性分", "score", neutral)
+			return neutral, nil
+		}
+	}
+
+	// 2. 读取节点实时指标
+	// 优先使用PreScore批量预取写入CycleState的结果（一次查询覆盖全部候选节点），
+	// 其次读后台协程定期刷新的缓存；Score不直接访问Prometheus，
+	// 避免在调度关键路径上引入查询延迟
+	usage := d.preScoredUsage(state, nodeName)
+	if usage == nil {
+		// 缓存命中的条目按年龄做陈旧度衰减（见StalenessDecay）；
+		// PreScore结果是本周期刚查询的，不存在陈旧问题
+		usage = d.applyStalenessDecay(d.cache.Get(nodeName))
+	}
+	if usage == nil {
+		// 指标不可用时按FailOpen决定兜底策略：
+		// 开启（默认）返回中性分放行，关闭则报错走失败路径
+		if !resolveFailOpen(args) {
+			scoringErrors.WithLabelValues(preferLabel).Inc()
+			return 0, framework.AsStatus(fmt.Errorf("节点 %s 指标不可用", nodeName))
+		}
+		neutral := resolveNeutralScore(args)
+		logger.V(2).Info("节点指标不可用，按fail-open返回中性分", "score", neutral)
+		nodeScore.WithLabelValues(preferLabel).Observe(float64(neutral))
+		return neutral, nil
 	}
 
 	// 3. 解析Pod标签,确定资源权重
@@ -82,185 +632,1741 @@ func (d *DynamicWeight) Score(ctx context.Context,
 	//			break
 	//		}
 	//	}
-	labelValue, exists := pod.Labels["resource-prefer"]
+	// 权重优先级：命名空间权重 > resource-prefer标签权重 > 默认权重
+	// 命名空间权重由管理员按团队设置，不应被Pod自带标签绕过
+	// resource-prefer支持下划线分隔的多个偏好（如"cpu_netio"），取各匹配权重表的平均
+	// 权重优先级（低到高）：DefaultWeights/时段权重 < resource-prefer标签
+	// < NamespaceWeights < 节点注解覆盖；节点注解由运维按节点池粒度设置
+	// （如存储节点弱化网络维度），对该节点的评分拥有最终决定权
+	// 记录最终选中的权重来源及其键，写入日志与指标便于排查配置路径
 	weights := args.DefaultWeights
-	if exists {
-		if w, ok := args.LabelWeights[labelValue]; ok {
-			weights = w
+	weightSource, weightKey := "default", ""
+	// 时段权重：当前时刻命中配置的窗口时替代默认权重作为基础权重
+	if w := activeScheduledWeights(args, d.now()); w != nil {
+		weights, weightSource, weightKey = w, "scheduled", ""
+	}
+	if preferLabel != "" {
+		if w := composeLabelWeights(preferLabel, args.LabelWeights); w != nil {
+			weights, weightSource, weightKey = w, "label", preferLabel
 		}
 	}
+	if w, ok := args.NamespaceWeights[pod.Namespace]; ok {
+		weights, weightSource, weightKey = w, "namespace", pod.Namespace
+	}
+	if w := d.nodeWeightOverride(nodeName); w != nil {
+		weights, weightSource, weightKey = w, "node-annotation", nodeName
+	}
+	weightSourceTotal.WithLabelValues(weightSource).Inc()
+
+	// 3.5 预测放置后使用率：把Pod聚合requests折算为各维度的使用率增量
+	// 仅在配置了惩罚阈值时启用，避免无谓的快照查询
+	var requestFractions map[string]float64
+	if args.PredictedUsageThreshold > 0 {
+		requestFractions = d.podRequestFractions(pod, nodeName)
+	}
+
+	// 3.6 在途Pod预留：同一调度突发内先行Pod抬高本节点的有效使用率
+	reserved := d.reservedUsage(nodeName)
+
+	// 3.7 评分解释：开关开启时逐维度记录贡献，评分后写入CycleState
+	var breakdown map[string]float64
+	if args.ExplainScores && state != nil {
+		breakdown = make(map[string]float64, len(weights))
+	}
 
 	// 4. 计算加权得分
 	//score := calculateScore(weights, usage)
 	score := 0.0
+	weightUsed := 0.0
 	for res, weight := range weights {
 		// 计算各资源维度贡献分：权重 × (1 - 使用率)
-		//score += weight * (1 - usage.Get(res))
-		switch res {
-		case "cpu":
-			score += weight * (1 - usage.CPU)
-		case "memory":
-			score += weight * (1 - usage.Memory)
-		case "diskio":
-			score += weight * (1 - usage.DiskIO)
-		case "netio":
-			score += weight * (1 - usage.Network)
+		// 维度名直接对照使用率映射，自定义指标与内置维度走同一评分路径
+		if value, ok := usage.Get(res); ok {
+			// 叠加在途Pod的预留增量（真实指标尚未反映的部分）
+			if inc := reserved[res]; inc > 0 {
+				value = clampUsage(value + inc)
+			}
+			// 叠加Pod自身的预测增量；越过阈值的维度记零分
+			if frac, hasReq := requestFractions[res]; hasReq {
+				value += frac
+				if value > args.PredictedUsageThreshold {
+					weightUsed += weight
+					continue
+				}
+			}
+			contribution := curveScore(args.ScoringCurve, value)
+			if bias, ok := resolvePackingBias(args); ok {
+				// 连续混合：bias=-1纯spread，+1纯binpack，0中性
+				spreadShare := (1 - bias) / 2
+				contribution = spreadShare*contribution + (1-spreadShare)*(1-contribution)
+			} else if resolveStrategy(args, res) == strategyBinpack {
+				// binpack策略取曲线得分的补值：高负载节点贡献分更高
+				contribution = 1 - contribution
+			}
+			score += weight * contribution
+			weightUsed += weight
+			if breakdown != nil {
+				breakdown[res] = weight * contribution
+			}
+			logger.V(5).Info("资源维度贡献分", "resource", res,
+				"usage", value, "weight", weight, "contribution", weight*contribution)
+		}
+	}
+	// 重归一化：本轮被剔除的维度（查询失败且无降级值）不应拉低总分
+	// 全部维度缺席时退回中性分
+	if weightUsed <= 0 {
+		neutral := resolveNeutralScore(args)
+		nodeScore.WithLabelValues(preferLabel).Observe(float64(neutral))
+		return neutral, nil
+	}
+	score /= weightUsed
+
+	// 4.5 评分解释：各维度贡献换算到0-100分制后写入CycleState，
+	// 下游报告机制（或测试）可经ScoreBreakdown取回得分构成
+	if breakdown != nil {
+		contributions := make(map[string]float64, len(breakdown))
+		for res, part := range breakdown {
+			contributions[res] = part / weightUsed * 100
 		}
+		state.Write(scoreBreakdownStateKey(nodeName), &scoreBreakdownState{contributions: contributions})
 	}
 
 	// 5. 记录日志
-	klog.V(4).InfoS("节点评分结果",
-		"pod", pod.Name,
-		"node", nodeName,
+	logger.V(4).Info("节点评分结果",
 		"score", score,
+		"weightSource", weightSource,
+		"weightKey", weightKey,
 		"cpuUsage", usage.CPU,
 		"memUsage", usage.Memory,
 		"diskioUsage", usage.DiskIO, //新增
 		"netioUsage", usage.Network, //新增
 	)
 
-	// 步骤5：转换为0-100分制
-	return int64(score * 100), nil
+	// 步骤5：转换为0-100分制，并收敛到调度框架的合法得分区间
+	// 自定义查询模板/降级值配置不当时计算结果可能越界甚至NaN，
+	// 越界分会被调度框架判为插件错误，必须在这里兜住
+	finalScore := int64(score * 100)
+	if math.IsNaN(score) || math.IsInf(score, 0) {
+		finalScore = resolveNeutralScore(args)
+	} else if finalScore > framework.MaxNodeScore {
+		finalScore = framework.MaxNodeScore
+	} else if finalScore < framework.MinNodeScore {
+		finalScore = framework.MinNodeScore
+	}
+	nodeScore.WithLabelValues(preferLabel).Observe(float64(finalScore))
+
+	// 观察模式：真实得分仅进日志与指标，决策上恒返回中性分
+	if args.ObserveOnly {
+		logger.V(2).Info("观察模式：计算得分不参与决策", "computedScore", finalScore)
+		return resolveNeutralScore(args), nil
+	}
+	return finalScore, nil
 }
 
-// 初始化Prometheus客户端
-func initPrometheusClient() (promv1.API, error) {
-	client, err := prometheus.NewClient(prometheus.Config{
-		Address: "http://prometheus-operated.monitoring.svc:9090",
-	})
-	if err != nil {
-		return nil, err
+// curveScore 按配置的评分曲线把使用率映射为[0,1]得分，使用率越低得分越高
+// linear：1-usage，均匀偏好
+// exponential：归一化的exp(-k·usage)，低负载区间斜率大，强化向空节点分散
+// sigmoid：以0.5为中心的S型曲线，近空与近满节点的得分差距被拉开
+func curveScore(curve string, usage float64) float64 {
+	switch curve {
+	case scoringCurveExponential:
+		const k = 4.0
+		return (math.Exp(-k*usage) - math.Exp(-k)) / (1 - math.Exp(-k))
+	case scoringCurveSigmoid:
+		const steepness = 10.0
+		raw := 1 / (1 + math.Exp(steepness*(usage-0.5)))
+		low := 1 / (1 + math.Exp(steepness*0.5))
+		high := 1 / (1 + math.Exp(-steepness*0.5))
+		// 归一化到[0,1]：usage=0得1分，usage=1得0分
+		return (raw - low) / (high - low)
+	default:
+		return 1 - usage
 	}
-	return promv1.NewAPI(client), nil
 }
 
-// NodeUsage 节点资源使用率数据结构,在cache中已定义
-//type NodeUsage struct {
-//	CPU     float64
-//	Memory  float64
-//	DiskIO  float64
-//	Network float64
-//}
+// podRequestFractions 把Pod的聚合requests按节点Allocatable折算为使用率增量
+// 仅覆盖能从requests推导的cpu/memory维度；快照缺失或Allocatable为零时
+// 返回nil，调用方按未启用处理
+func (d *DynamicWeight) podRequestFractions(pod *v1.Pod, nodeName string) map[string]float64 {
+	if d.handle == nil || pod == nil {
+		return nil
+	}
+	lister := d.handle.SnapshotSharedLister()
+	if lister == nil {
+		return nil
+	}
+	nodeInfo, err := lister.NodeInfos().Get(nodeName)
+	if err != nil || nodeInfo == nil || nodeInfo.Allocatable == nil {
+		return nil
+	}
 
-// 获取节点真实资源使用率
-func (d *DynamicWeight) getRealNodeUsage(nodeName string) (*NodeUsage, error) {
-	// 尝试从缓存获取
-	if cached := d.cache.Get(nodeName); cached != nil {
-		return cached, nil
+	var cpuMilli, memBytes int64
+	for _, container := range pod.Spec.Containers {
+		cpuMilli += container.Resources.Requests.Cpu().MilliValue()
+		memBytes += container.Resources.Requests.Memory().Value()
 	}
-	// 1. 获取节点对象
-	node, err := d.handle.ClientSet().CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("获取节点信息失败: %v", err)
+
+	fractions := make(map[string]float64, 2)
+	if alloc := nodeInfo.Allocatable.MilliCPU; alloc > 0 && cpuMilli > 0 {
+		fractions["cpu"] = float64(cpuMilli) / float64(alloc)
+	}
+	if alloc := nodeInfo.Allocatable.Memory; alloc > 0 && memBytes > 0 {
+		fractions["memory"] = float64(memBytes) / float64(alloc)
+	}
+	return fractions
+}
+
+// clampUsage 把查询得到的使用率收敛到[0,1]
+// 网络利用率等比值型查询在配置不当时可能超过100%，NaN/Inf
+// （除零或空速率窗口）按中性值0.5处理，避免污染评分
+// scaleMetric 按配置的缩放系数修正维度的原始查询值
+// 在clamp截断之前调用，让百分比口径的exporter数据先折算回0-1小数；
+// 未配置系数的维度原值返回
+func (d *DynamicWeight) scaleMetric(resource string, value float64) float64 {
+	if d.weightLoader == nil {
+		return value
+	}
+	args := d.weightLoader.GetWeights()
+	if args == nil {
+		return value
 	}
+	if scale, ok := args.MetricScale[resource]; ok && scale > 0 {
+		return value * scale
+	}
+	return value
+}
 
-	// 2. 提取节点的内部IP
-	var nodeIP string
-	for _, addr := range node.Status.Addresses {
-		if addr.Type == v1.NodeInternalIP {
-			nodeIP = addr.Address
-			break
-		}
+func clampUsage(value float64) float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0.5
 	}
-	if nodeIP == "" {
-		return nil, fmt.Errorf("节点 %s 无内部IP地址", nodeName)
+	if value < 0 {
+		return 0
 	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
 
-	// 定义Prometheus查询模板
-	const (
-		cpuQueryTemplate = `sum(rate(node_cpu_seconds_total{mode!="idle", instance=~"%s(:.*)?"}[5m])) 
-                            / count(node_cpu_seconds_total{mode="user", instance=~"%s(:.*)?"})`
+// inWarmup 判断插件是否仍处于配置的预热期内，并同步预热完成仪表
+// 未配置预热期或未记录启动时刻时视为已完成
+func (d *DynamicWeight) inWarmup(args *DynamicWeightArgs) bool {
+	period := args.WarmupPeriod.Duration
+	if period <= 0 || d.startedAt.IsZero() {
+		warmupComplete.Set(1)
+		return false
+	}
+	if d.now().Sub(d.startedAt) < period {
+		warmupComplete.Set(0)
+		return true
+	}
+	warmupComplete.Set(1)
+	return false
+}
 
-		memQueryTemplate = `(node_memory_MemTotal_bytes{instance=~"%s(:.*)?"} 
-                            - node_memory_MemAvailable_bytes{instance=~"%s(:.*)?"}) 
-                            / node_memory_MemTotal_bytes{instance=~"%s(:.*)?"}`
+// now 返回当前时间；未注入时钟（测试直接构造结构体）时退回time.Now
+func (d *DynamicWeight) now() time.Time {
+	if d.timeNow != nil {
+		return d.timeNow()
+	}
+	return time.Now()
+}
 
-		diskQueryTemplate = `rate(node_disk_io_time_seconds_total{device=~"sdb", instance=~"%s(:.*)?"}[5m])`
+// activeScheduledWeights 返回当前时刻命中的时段权重表
+// 列表序在前的窗口优先（重叠时first match wins）；无命中返回nil，
+// 由调用方回退DefaultWeights
+func activeScheduledWeights(args *DynamicWeightArgs, now time.Time) map[string]float64 {
+	for i := range args.ScheduledWeights {
+		if args.ScheduledWeights[i].active(now) {
+			return args.ScheduledWeights[i].Weights
+		}
+	}
+	return nil
+}
 
-		netQueryTemplate = `
-                            (rate(node_network_receive_bytes_total{device="eth0", instance=~"%s(:.*)?"}[5m]) * 8 
-                            + rate(node_network_transmit_bytes_total{device="eth0", instance=~"%s(:.*)?"}[5m]) * 8
-                            ) / (node_network_speed_bytes{device="eth0", instance=~"%s(:.*)?"}) * 100` // 转换为百分比
-	)
+// 节点权重覆盖注解：取值为JSON权重映射（如{"cpu":0.6,"memory":0.4}）
+// 运维按节点池粒度微调该节点的评分权重，优先级高于Pod/命名空间权重
+const nodeWeightOverrideAnnotation = "dynamicweight.k8s.io/weights"
 
-	// 执行CPU查询
-	cpuQuery := fmt.Sprintf(cpuQueryTemplate, nodeIP, nodeIP)
-	cpuValue, err := d.queryPrometheus(cpuQuery)
-	if err != nil {
-		return nil, fmt.Errorf("CPU查询失败: %v", err)
+// nodeWeightOverride 读取节点注解中的权重覆盖表
+// 注解缺失返回nil；解析或校验失败时忽略覆盖（该节点继续用常规权重），
+// 只记录日志，避免一个写坏的注解让节点评分整体失败
+func (d *DynamicWeight) nodeWeightOverride(nodeName string) map[string]float64 {
+	node := d.scoredNode(nodeName)
+	if node == nil {
+		return nil
+	}
+	raw, ok := node.Annotations[nodeWeightOverrideAnnotation]
+	if !ok {
+		return nil
+	}
+	override := map[string]float64{}
+	if err := json.Unmarshal([]byte(raw), &override); err != nil {
+		klog.V(2).InfoS("节点权重覆盖注解解析失败，忽略覆盖",
+			"node", nodeName, "annotation", nodeWeightOverrideAnnotation, "err", err)
+		return nil
+	}
+	if err := validateWeights(nodeWeightOverrideAnnotation, override); err != nil {
+		klog.V(2).InfoS("节点权重覆盖注解未通过校验，忽略覆盖",
+			"node", nodeName, "annotation", nodeWeightOverrideAnnotation, "err", err)
+		return nil
 	}
+	return override
+}
 
-	// 执行内存查询
-	memQuery := fmt.Sprintf(memQueryTemplate, nodeIP, nodeIP, nodeIP)
-	memValue, err := d.queryPrometheus(memQuery)
-	if err != nil {
-		return nil, fmt.Errorf("内存查询失败: %v", err)
+// scoredNode 获取评分中节点的完整对象
+// 优先读调度周期的快照（与本轮评分一致），其次退回节点Lister
+func (d *DynamicWeight) scoredNode(nodeName string) *v1.Node {
+	if d.handle != nil {
+		if lister := d.handle.SnapshotSharedLister(); lister != nil {
+			if nodeInfo, err := lister.NodeInfos().Get(nodeName); err == nil && nodeInfo != nil && nodeInfo.Node() != nil {
+				return nodeInfo.Node()
+			}
+		}
+	}
+	if d.nodeLister != nil {
+		if node, err := d.nodeLister.Get(nodeName); err == nil {
+			return node
+		}
 	}
+	return nil
+}
 
-	// 执行磁盘IO查询
-	diskQuery := fmt.Sprintf(diskQueryTemplate, nodeIP)
-	diskValue, err := d.queryPrometheus(diskQuery)
-	if err != nil {
-		klog.Warningf("磁盘指标不可用，使用默认值: %v", err)
-		diskValue = 0.3 // 降级处理
+// excludeSelector 解析节点排除选择器；未配置或无效/空选择器时返回nil
+// 空选择器会匹配所有节点，按配置错误忽略（Validate已拦截显式配置）
+func excludeSelector(args *DynamicWeightArgs) labels.Selector {
+	if args == nil || args.ExcludeNodeSelector == nil {
+		return nil
 	}
+	selector, err := metav1.LabelSelectorAsSelector(args.ExcludeNodeSelector)
+	if err != nil || selector.Empty() {
+		return nil
+	}
+	return selector
+}
 
-	// 执行网络查询
-	netQuery := fmt.Sprintf(netQueryTemplate, nodeIP, nodeIP, nodeIP)
-	netValue, err := d.queryPrometheus(netQuery)
-	if err != nil {
-		klog.Warningf("网络指标不可用，使用默认值: %v", err)
-		netValue = 0.2 // 降级处理
+// resolvePackingBias 读取spread/binpack混合系数
+// 配置后优先于Strategy硬开关；未配置时返回false走既有策略逻辑
+func resolvePackingBias(args *DynamicWeightArgs) (float64, bool) {
+	if args.PackingBias == nil {
+		return 0, false
 	}
+	return *args.PackingBias, true
+}
 
-	// 构建返回数据
-	usage := &NodeUsage{
-		CPU:     cpuValue,
-		Memory:  memValue,
-		DiskIO:  diskValue,
-		Network: netValue,
+// resolveStrategy 读取指定资源维度的负载分布策略
+// 优先按资源维度的覆盖值，其次全局Strategy，未配置时默认spread
+func resolveStrategy(args *DynamicWeightArgs, resource string) string {
+	if strategy, ok := args.ResourceStrategies[resource]; ok {
+		return strategy
 	}
+	if args.Strategy != "" {
+		return args.Strategy
+	}
+	return strategySpread
+}
 
-	// 更新缓存
-	d.cache.Set(nodeName, usage)
-	return usage, nil
+// resolveFailOpen 读取指标不可用时的兜底策略；未配置时默认fail-open
+func resolveFailOpen(args *DynamicWeightArgs) bool {
+	return args.FailOpen == nil || *args.FailOpen
 }
 
-// 统一Prometheus查询方法
-func (d *DynamicWeight) queryPrometheus(query string) (float64, error) {
-	result, _, err := d.promClient.Query(context.Background(), query, time.Now())
-	if err != nil {
-		return 0, err
+// resolveNeutralScore 读取配置的中性分，未配置时用内置默认值
+func resolveNeutralScore(args *DynamicWeightArgs) int64 {
+	if args.NeutralScore > 0 {
+		return args.NeutralScore
 	}
+	return neutralScore
+}
 
-	// 解析向量类型结果
-	vector, ok := result.(model.Vector)
-	if !ok || len(vector) == 0 {
-		return 0, fmt.Errorf("无效的查询结果格式")
+// composeLabelWeights 按resource-prefer标签值合成权重表
+// 标签值用下划线分隔多个偏好（如"cpu_netio"），对每个能在labelWeights
+// 中匹配到的偏好取其权重表，逐维度求平均后归一化（和为1.0）
+// 无法匹配的偏好忽略；全部未匹配时返回nil，由调用方回退默认权重
+func composeLabelWeights(labelValue string, labelWeights map[string]map[string]float64) map[string]float64 {
+	var matched []map[string]float64
+	for _, token := range strings.Split(labelValue, "_") {
+		if w, ok := labelWeights[token]; ok {
+			matched = append(matched, w)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	if len(matched) == 1 {
+		return matched[0]
 	}
 
-	return float64(vector[0].Value), nil
+	// 逐维度求平均
+	composed := make(map[string]float64)
+	for _, weights := range matched {
+		for res, w := range weights {
+			composed[res] += w / float64(len(matched))
+		}
+	}
+
+	// 归一化：各权重表本应和为1.0，此处兜底消除浮点累计误差
+	var sum float64
+	for _, w := range composed {
+		sum += w
+	}
+	if sum > 0 {
+		for res := range composed {
+			composed[res] /= sum
+		}
+	}
+	return composed
 }
 
-// 初始化工厂函数
-func New(ctx context.Context, args runtime.Object, h framework.Handle) (framework.Plugin, error) {
-	// 初始化Prometheus客户端
-	promClient, err := initPrometheusClient()
-	if err != nil {
-		return nil, fmt.Errorf("初始化Prometheus客户端失败: %v", err)
+// 构建Prometheus连接使用的TLS配置
+// 流程：
+//  1. 若配置了CAFile，从文件（通常为挂载的Secret路径）加载自定义CA证书
+//  2. 若配置了客户端证书/私钥，加载并启用双向TLS认证
+//  3. 任一环节失败则返回描述性错误，让New()快速失败
+func buildTLSConfig(args *DynamicWeightArgs) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: args.InsecureSkipVerify,
 	}
 
-	// 初始化权重加载器
-	weightLoader, err := NewWeightLoader(h.ClientSet())
-	if err != nil {
-		return nil, fmt.Errorf("配置加载失败: %v", err)
+	// 加载自定义CA证书（用于校验HTTPS Prometheus的服务端证书）
+	if args.CAFile != "" {
+		caCert, err := os.ReadFile(args.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书文件 %s 失败: %v", args.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("CA证书文件 %s 中无有效的PEM证书", args.CAFile)
+		}
+		tlsConfig.RootCAs = pool
 	}
 
-	// 返回插件实例
-	return &DynamicWeight{
-		handle:       h,
-		weightLoader: weightLoader,
-		promClient:   promClient,
-		cache:        NewNodeUsageCache(5 * time.Minute),
-	}, nil
+	// 加载客户端证书/私钥对（双向TLS认证）
+	if args.ClientCertFile != "" || args.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(args.ClientCertFile, args.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("解析客户端证书/私钥对 (%s, %s) 失败: %v",
+				args.ClientCertFile, args.ClientKeyFile, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// 未显式配置PrometheusAddress时使用的集群内惯例地址
+const defaultPrometheusAddress = "http://prometheus-operated.monitoring.svc:9090"
+
+// 初始化Prometheus客户端
+// 将TLS配置注入API传输层，以支持HTTPS Prometheus（自定义CA/双向认证）
+func initPrometheusClient(args *DynamicWeightArgs) (promv1.API, error) {
+	tlsConfig, err := buildTLSConfig(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// 基于默认RoundTripper克隆，仅替换TLS配置
+	transport := prometheus.DefaultRoundTripper.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	// Thanos/Cortex等多租户后端按请求头路由：把配置的附加头
+	// （及租户标识）注入每个查询请求
+	rt := withQueryHeaders(args, transport)
+
+	address := defaultPrometheusAddress
+	if args.PrometheusAddress != "" {
+		address = args.PrometheusAddress
+	}
+	client, err := prometheus.NewClient(prometheus.Config{
+		Address:      address,
+		RoundTripper: rt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promv1.NewAPI(client), nil
+}
+
+// 多租户后端的标准租户头（Thanos Query/Cortex均识别）
+const tenantHeaderName = "X-Scope-OrgID"
+
+// headerRoundTripper 在每个请求上附加固定HTTP头的传输层包装
+type headerRoundTripper struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (h *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// 不改动调用方持有的原始请求（RoundTripper契约）
+	cloned := req.Clone(req.Context())
+	for name, value := range h.headers {
+		cloned.Header.Set(name, value)
+	}
+	return h.next.RoundTrip(cloned)
+}
+
+// withQueryHeaders 按配置包装传输层；未配置任何附加头时原样返回
+func withQueryHeaders(args *DynamicWeightArgs, next http.RoundTripper) http.RoundTripper {
+	headers := make(map[string]string, len(args.QueryHeaders)+1)
+	for name, value := range args.QueryHeaders {
+		headers[name] = value
+	}
+	if args.TenantHeader != "" {
+		headers[tenantHeaderName] = args.TenantHeader
+	}
+	if len(headers) == 0 {
+		return next
+	}
+	return &headerRoundTripper{headers: headers, next: next}
+}
+
+// NodeUsage 节点资源使用率数据结构,在cache中已定义
+//type NodeUsage struct {
+//	CPU     float64
+//	Memory  float64
+//	DiskIO  float64
+//	Network float64
+//}
+
+// 获取节点真实资源使用率（同步路径，后台刷新外的按需兜底）
+// 按维度过期：只有部分维度过期时增量重查过期维度，其余沿用缓存值
+func (d *DynamicWeight) getRealNodeUsage(ctx context.Context, nodeName string) (*NodeUsage, error) {
+	// 尝试从缓存获取
+	cached, stale := d.cache.GetWithStale(nodeName)
+	if cached != nil && len(stale) == 0 {
+		cacheHits.Inc()
+		return d.applyStalenessDecay(cached), nil
+	}
+	cacheMisses.Inc()
+	// 获取节点对象
+	node, err := d.getNode(ctx, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("获取节点信息失败: %v", err)
+	}
+
+	if cached != nil {
+		// 部分过期：只重查过期的维度并合并进缓存
+		return d.refreshStaleResources(ctx, node, stale)
+	}
+	return d.updateNodeUsage(ctx, node)
+}
+
+// 陈旧度衰减混合的中性使用率：对应中性分50的负载水平
+const stalenessNeutralUsage = 0.5
+
+// applyStalenessDecay 按StalenessDecay配置对缓存命中的使用率做陈旧度衰减
+// 临近TTL的条目与刚拉取的条目可信度并不相同：linear曲线按各维度
+// 年龄占TTL的比例向中性使用率混合，让陈旧数据的影响平滑减弱。
+// 返回衰减后的副本，缓存内的原始数据保持不变
+func (d *DynamicWeight) applyStalenessDecay(usage *NodeUsage) *NodeUsage {
+	if usage == nil || d.weightLoader == nil {
+		return usage
+	}
+	args := d.weightLoader.GetWeights()
+	if args == nil || args.StalenessDecay == "" || args.StalenessDecay == stalenessDecayNone {
+		return usage
+	}
+	if d.cache == nil || d.cache.timeout <= 0 {
+		return usage
+	}
+	ttl := d.cache.timeout
+	now := d.now()
+
+	out := *usage
+	if usage.Metrics != nil {
+		out.Metrics = make(map[string]float64, len(usage.Metrics))
+		for res, v := range usage.Metrics {
+			// 有按维度时间戳时各维度按各自年龄衰减（增量刷新后年龄不同）
+			ts := usage.Timestamp
+			if t, ok := usage.ResourceTimestamps[res]; ok {
+				ts = t
+			}
+			decayed := decayTowardNeutral(v, now.Sub(ts), ttl)
+			out.Metrics[res] = decayed
+			out.setNamed(res, decayed)
+		}
+		return &out
+	}
+
+	// 旧式整条写入：全部内置维度按条目年龄统一衰减
+	age := now.Sub(usage.Timestamp)
+	out.CPU = decayTowardNeutral(usage.CPU, age, ttl)
+	out.Memory = decayTowardNeutral(usage.Memory, age, ttl)
+	out.DiskIO = decayTowardNeutral(usage.DiskIO, age, ttl)
+	out.Network = decayTowardNeutral(usage.Network, age, ttl)
+	out.NetworkRx = decayTowardNeutral(usage.NetworkRx, age, ttl)
+	out.NetworkTx = decayTowardNeutral(usage.NetworkTx, age, ttl)
+	out.GPU = decayTowardNeutral(usage.GPU, age, ttl)
+	return &out
+}
+
+// decayTowardNeutral 按年龄占TTL的比例将使用率向中性值线性混合
+// 年龄为0返回原值，达到或超过TTL返回中性值
+func decayTowardNeutral(value float64, age, ttl time.Duration) float64 {
+	if age <= 0 {
+		return value
+	}
+	frac := float64(age) / float64(ttl)
+	if frac > 1 {
+		frac = 1
+	}
+	return value*(1-frac) + stalenessNeutralUsage*frac
+}
+
+// getNode 获取节点对象
+// 优先读调度器的节点informer缓存，避免缓存未命中时逐次请求apiserver；
+// lister未就绪或未命中时回退直连API
+func (d *DynamicWeight) getNode(ctx context.Context, nodeName string) (*v1.Node, error) {
+	if d.nodeLister != nil {
+		if node, err := d.nodeLister.Get(nodeName); err == nil {
+			return node, nil
+		}
+	}
+	return d.handle.ClientSet().CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+}
+
+// nodeInstanceAddress 按InstanceLabelSource解析节点在Prometheus中的instance标识
+// node-exporter部署方式不同，instance标签可能是内部IP、主机名或节点名；
+// 缺少对应地址类型时返回带节点名的明确错误
+// 解析结果按节点缓存，避免每轮指标拉取都重复扫描地址列表
+func (d *DynamicWeight) nodeInstanceAddress(node *v1.Node) (string, error) {
+	source := d.resolveInstanceLabelSource()
+
+	d.instanceMu.Lock()
+	// 来源配置变化（热更新）时整体失效
+	if d.instanceAddrs == nil || d.instanceAddrSource != source {
+		d.instanceAddrs = make(map[string]string)
+		d.instanceAddrSource = source
+	}
+	if addr, ok := d.instanceAddrs[node.Name]; ok {
+		d.instanceMu.Unlock()
+		return addr, nil
+	}
+	d.instanceMu.Unlock()
+
+	addr, err := nodeAddressBySource(node, source, d.addressTypePreference())
+	if err != nil {
+		return "", err
+	}
+
+	d.instanceMu.Lock()
+	d.instanceAddrs[node.Name] = addr
+	d.instanceMu.Unlock()
+	return addr, nil
+}
+
+// forgetNodeInstance 清理下线节点的instance标识缓存
+func (d *DynamicWeight) forgetNodeInstance(nodeName string) {
+	d.instanceMu.Lock()
+	delete(d.instanceAddrs, nodeName)
+	d.instanceMu.Unlock()
+}
+
+// resolveInstanceLabelSource 读取配置的instance标识来源，默认InternalIP
+func (d *DynamicWeight) resolveInstanceLabelSource() string {
+	if d.weightLoader != nil {
+		if source := d.weightLoader.GetWeights().InstanceLabelSource; source != "" {
+			return source
+		}
+	}
+	return instanceSourceInternalIP
+}
+
+// 默认的地址类型回退顺序：优先内部IP，随后主机名、外部IP
+// 部分云/边缘节点只上报ExternalIP，直接放弃会让这类节点永远拿中性分
+var defaultAddressTypePreference = []v1.NodeAddressType{
+	v1.NodeInternalIP, v1.NodeHostName, v1.NodeExternalIP,
+}
+
+// addressTypePreference 解析配置的地址类型回退顺序，未配置时用内置默认链
+func (d *DynamicWeight) addressTypePreference() []v1.NodeAddressType {
+	if d.weightLoader != nil {
+		if prefs := d.weightLoader.GetWeights().AddressTypePreference; len(prefs) > 0 {
+			out := make([]v1.NodeAddressType, 0, len(prefs))
+			for _, p := range prefs {
+				out = append(out, v1.NodeAddressType(p))
+			}
+			return out
+		}
+	}
+	return defaultAddressTypePreference
+}
+
+// nodeAddressBySource 从节点对象提取指定来源的instance标识
+// InstanceLabelSource决定首选地址类型，取不到时沿preference链回退
+func nodeAddressBySource(node *v1.Node, source string, preference []v1.NodeAddressType) (string, error) {
+	if source == instanceSourceNodeName {
+		return node.Name, nil
+	}
+	primary := v1.NodeInternalIP
+	if source == instanceSourceHostname {
+		primary = v1.NodeHostName
+	}
+
+	tried := make([]v1.NodeAddressType, 0, len(preference)+1)
+	for _, addrType := range append([]v1.NodeAddressType{primary}, preference...) {
+		alreadyTried := false
+		for _, t := range tried {
+			if t == addrType {
+				alreadyTried = true
+				break
+			}
+		}
+		if alreadyTried {
+			continue
+		}
+		tried = append(tried, addrType)
+		for _, addr := range node.Status.Addresses {
+			if addr.Type == addrType {
+				return addr.Address, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("节点 %s 无可用地址（已尝试类型 %v）", node.Name, tried)
+}
+
+// updateNodeUsage 经主数据源拉取节点全维度使用率并刷新缓存
+// 主源（Prometheus）整轮失败时退到备源（metrics-server，仅CPU/内存），
+// 缺席的维度由评分时的重归一化兜底；主备都失败返回主源错误
+func (d *DynamicWeight) updateNodeUsage(ctx context.Context, node *v1.Node) (*NodeUsage, error) {
+	values, sampledAt, err := d.primary().NodeUsage(ctx, node)
+	if err != nil {
+		if d.secondary != nil {
+			if fallbackValues, fallbackSampled, serr := d.secondary.NodeUsage(ctx, node); serr == nil {
+				klog.FromContext(ctx).V(2).Info("Prometheus不可用，改用备用指标源评分",
+					"node", node.Name, "resources", len(fallbackValues), "primaryErr", err)
+				usage := usageFromMetrics(fallbackValues, fallbackSampled)
+				d.cache.Set(node.Name, usage)
+				return usage, nil
+			} else {
+				klog.FromContext(ctx).V(2).Info("主备指标源均不可用",
+					"node", node.Name, "primaryErr", err, "secondaryErr", serr)
+			}
+		}
+		return nil, err
+	}
+
+	usage := usageFromMetrics(values, sampledAt)
+	d.cache.Set(node.Name, usage)
+	return usage, nil
+}
+
+// ScoreBreakdown 读取ExplainScores开关下记录的节点得分构成
+// 返回各资源维度对最终得分的贡献（0-100分制，合计即最终得分）；
+// 开关未开启或该节点本轮未走完整评分路径时返回nil
+func (d *DynamicWeight) ScoreBreakdown(state *framework.CycleState, nodeName string) map[string]float64 {
+	if state == nil {
+		return nil
+	}
+	data, err := state.Read(scoreBreakdownStateKey(nodeName))
+	if err != nil {
+		return nil
+	}
+	breakdown, ok := data.(*scoreBreakdownState)
+	if !ok {
+		return nil
+	}
+	return breakdown.contributions
+}
+
+// Reserve 登记被调度器暂定放置到节点的Pod
+// 其请求量折算的使用率增量在真实指标赶上前抬高该节点后续Pod的
+// 有效使用率，缓解同一调度突发内多个Pod挤向同一空闲节点的超卖
+func (d *DynamicWeight) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	fractions := d.podRequestFractions(pod, nodeName)
+	if len(fractions) == 0 {
+		return nil
+	}
+
+	d.reservedMu.Lock()
+	defer d.reservedMu.Unlock()
+	if d.reservedPods == nil {
+		d.reservedPods = make(map[string]map[types.UID]podReservation)
+	}
+	byPod := d.reservedPods[nodeName]
+	if byPod == nil {
+		byPod = make(map[types.UID]podReservation)
+		d.reservedPods[nodeName] = byPod
+	}
+	byPod[pod.UID] = podReservation{fractions: fractions, at: d.now()}
+	return nil
+}
+
+// Unreserve 回退预留：绑定失败/被抢占时该Pod不再占用节点
+func (d *DynamicWeight) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	d.reservedMu.Lock()
+	defer d.reservedMu.Unlock()
+	if byPod, ok := d.reservedPods[nodeName]; ok {
+		delete(byPod, pod.UID)
+		if len(byPod) == 0 {
+			delete(d.reservedPods, nodeName)
+		}
+	}
+}
+
+// reservationTTL 预留条目的保留时长
+// 新Pod的负载大约在一个缓存刷新周期加rate窗口后进入真实指标，
+// 超过后继续计入会与监控数据双重计数
+func (d *DynamicWeight) reservationTTL() time.Duration {
+	refresh := defaultRefreshInterval
+	window := defaultRateWindow
+	if d.weightLoader != nil {
+		args := d.weightLoader.GetWeights()
+		if args.RefreshInterval.Duration > 0 {
+			refresh = args.RefreshInterval.Duration
+		}
+		if args.RateWindow.Duration > 0 {
+			window = args.RateWindow.Duration
+		}
+	}
+	return refresh + window
+}
+
+// reservedUsage 汇总节点上未过期预留的各维度使用率增量，并顺带清理过期项
+func (d *DynamicWeight) reservedUsage(nodeName string) map[string]float64 {
+	d.reservedMu.Lock()
+	defer d.reservedMu.Unlock()
+	byPod, ok := d.reservedPods[nodeName]
+	if !ok {
+		return nil
+	}
+
+	cutoff := d.now().Add(-d.reservationTTL())
+	total := make(map[string]float64, 2)
+	for uid, reservation := range byPod {
+		if reservation.at.Before(cutoff) {
+			delete(byPod, uid)
+			continue
+		}
+		for res, frac := range reservation.fractions {
+			total[res] += frac
+		}
+	}
+	if len(byPod) == 0 {
+		delete(d.reservedPods, nodeName)
+	}
+	return total
+}
+
+// primary 返回主指标数据源（既有的Prometheus查询路径）
+func (d *DynamicWeight) primary() MetricsProvider {
+	return &prometheusProvider{d: d}
+}
+
+// refreshStaleResources 只重查指定的过期维度并合并进缓存条目
+func (d *DynamicWeight) refreshStaleResources(ctx context.Context, node *v1.Node, stale []string) (*NodeUsage, error) {
+	nodeIP, err := d.nodeInstanceAddress(node)
+	if err != nil {
+		return nil, err
+	}
+
+	only := make(map[string]bool, len(stale))
+	for _, res := range stale {
+		// netio为收发合计的派生维度，无独立查询模板：改查两个方向，
+		// 合并时重算合计值
+		if res == "netio" {
+			only["netrx"] = true
+			only["nettx"] = true
+			continue
+		}
+		only[res] = true
+	}
+	values, sampledAt, err := d.queryUsageMetrics(ctx, nodeIP, node, only)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := d.cache.UpdateResources(node.Name, values, sampledAt)
+	if usage == nil {
+		// 条目在查询期间被删除/淘汰：整条重建
+		return d.updateNodeUsage(ctx, node)
+	}
+	return usage, nil
+}
+
+// startCacheRefresher 后台定期刷新所有节点的使用率缓存
+// 使Score只读缓存，调度关键路径不被Prometheus查询阻塞
+// 每轮间隔叠加随机抖动，多副本调度器不会同时压向Prometheus
+func (d *DynamicWeight) startCacheRefresher(ctx context.Context, interval time.Duration) {
+	// 启动时先刷新一轮，尽快让Score有数据可用
+	d.refreshAllNodes(ctx)
+
+	for {
+		timer := time.NewTimer(jitteredPeriod(interval, d.resolveResyncJitterFactor()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			d.refreshAllNodes(ctx)
+		}
+	}
+}
+
+// resolveResyncJitterFactor 读取配置的抖动系数，未配置时用默认值
+func (d *DynamicWeight) resolveResyncJitterFactor() float64 {
+	if d.weightLoader != nil {
+		if f := d.weightLoader.GetWeights().ResyncJitterFactor; f > 0 {
+			return f
+		}
+	}
+	return defaultResyncJitterFactor
+}
+
+// refreshAllNodes 通过informer的节点Lister遍历所有节点刷新缓存
+// 批量路径适用时优先走批量预热（单次往返覆盖全部节点），批量失败
+// 或配置了自定义查询模板/匹配表达式时回退逐节点查询
+func (d *DynamicWeight) refreshAllNodes(ctx context.Context) {
+	nodes, err := d.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "刷新缓存时列举节点失败")
+		return
+	}
+
+	// 命中排除选择器的节点不刷新：Score对其恒返回中性分，查询纯属浪费
+	var exclude labels.Selector
+	if d.weightLoader != nil {
+		exclude = excludeSelector(d.weightLoader.GetWeights())
+	}
+	if exclude != nil {
+		kept := nodes[:0]
+		for _, node := range nodes {
+			if !exclude.Matches(labels.Set(node.Labels)) {
+				kept = append(kept, node)
+			}
+		}
+		nodes = kept
+	}
+
+	cutoff := d.now()
+	if d.batchQueryUsable() {
+		ips := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			if instance, err := d.nodeInstanceAddress(node); err == nil {
+				ips = append(ips, instance)
+			}
+		}
+		if warmErr := d.WarmAll(ctx, ips); warmErr != nil {
+			klog.V(2).InfoS("批量预热缓存失败，回退到逐节点刷新", "err", warmErr)
+		}
+	}
+
+	// 批量预热未覆盖的节点（部分instance无样本/批量失败/自定义模板）
+	// 走逐节点查询兜底；本轮已刷新过的节点跳过。条目只有部分维度
+	// 过期时只重查这些维度（见GetWithStale）；其余情况（无条目/全部
+	// 过期/各维度虽未过期但到了刷新周期）整条重查，保持刷新节奏
+	for _, node := range nodes {
+		cached, stale := d.cache.GetWithStale(node.Name)
+		if cached != nil && !cached.Timestamp.Before(cutoff) {
+			continue
+		}
+		if cached != nil && len(stale) > 0 {
+			if _, err := d.refreshStaleResources(ctx, node, stale); err != nil {
+				klog.V(4).InfoS("增量刷新过期维度失败", "node", node.Name, "stale", stale, "err", err)
+			}
+			continue
+		}
+		if _, err := d.updateNodeUsage(ctx, node); err != nil {
+			klog.V(4).InfoS("刷新节点使用率失败", "node", node.Name, "err", err)
+		}
+	}
+}
+
+// WarmAll 对一组节点（按instance标识）发起批量查询并预热缓存
+// 每个资源维度只发一次按instance分组的聚合查询，把HTTP开销摊薄到
+// 整个节点集合上，供后台刷新器或PreScore调用。个别instance无样本
+// 时跳过该节点（逐节点路径兜底）；不覆盖预热期间其他路径写入的
+// 更新鲜的条目
+func (d *DynamicWeight) WarmAll(ctx context.Context, nodeIPs []string) error {
+	if len(nodeIPs) == 0 {
+		return nil
+	}
+	if d.nodeLister == nil {
+		return fmt.Errorf("节点Lister未就绪，无法解析instance对应的节点")
+	}
+
+	requested := make(map[string]bool, len(nodeIPs))
+	for _, ip := range nodeIPs {
+		requested[ip] = true
+	}
+	nodes, err := d.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("列举节点失败: %v", err)
+	}
+	ipToName := make(map[string]string, len(nodeIPs))
+	quotedIPs := make([]string, 0, len(nodeIPs))
+	for _, node := range nodes {
+		instance, err := d.nodeInstanceAddress(node)
+		if err != nil || !requested[instance] {
+			continue
+		}
+		ipToName[instance] = node.Name
+		quotedIPs = append(quotedIPs, regexp.QuoteMeta(instance))
+	}
+	if len(quotedIPs) == 0 {
+		return nil
+	}
+
+	cutoff := d.now()
+	usages, err := d.batchQueryNodeUsage(ctx, quotedIPs, ipToName)
+	if err != nil {
+		return err
+	}
+	for nodeName, usage := range usages {
+		d.cache.SetIfOlder(nodeName, usage, cutoff)
+	}
+	return nil
+}
+
+const (
+	// 节点标签：按节点覆盖磁盘设备/网卡名称，支持异构硬件集群共存
+	diskDeviceNodeLabel       = "dynamicweight.scheduler.k8s.io/disk-device"
+	networkInterfaceNodeLabel = "dynamicweight.scheduler.k8s.io/network-interface"
+
+	// 内置默认设备名（保持向后兼容）
+	defaultDiskDevice       = "sdb"
+	defaultNetworkInterface = "eth0"
+)
+
+// 各资源维度内置的PromQL查询模板
+// 占位符在查询时替换：{instance}->节点IP，{device}->磁盘设备名，{iface}->网卡名
+// 可通过DynamicWeightArgs.QueryTemplates按资源维度覆盖，无需重新编译
+var defaultQueryTemplates = map[string]string{
+	"cpu": `sum(rate(node_cpu_seconds_total{mode!="idle", instance=~"{instance}(:.*)?"}[{window}]))
+                            / count(node_cpu_seconds_total{mode="user", instance=~"{instance}(:.*)?"})`,
+
+	"memory": `(node_memory_MemTotal_bytes{instance=~"{instance}(:.*)?"}
+                            - node_memory_MemAvailable_bytes{instance=~"{instance}(:.*)?"})
+                            / node_memory_MemTotal_bytes{instance=~"{instance}(:.*)?"}`,
+
+	"diskio": `rate(node_disk_io_time_seconds_total{device=~"{device}", instance=~"{instance}(:.*)?"}[{window}])`,
+
+	// 网络按收/发方向分别查询，便于独立加权；合计值保持向后兼容
+	// 带宽占比本身即0-1比值，不做百分比换算：评分按weight*(1-usage)
+	// 消费0-1使用率，乘100会被clamp压成恒1.0，网络维度彻底失去区分度
+	"netrx": `rate(node_network_receive_bytes_total{device=~"{iface}", instance=~"{instance}(:.*)?"}[{window}]) * 8
+                            / (node_network_speed_bytes{device=~"{iface}", instance=~"{instance}(:.*)?"})`,
+
+	"nettx": `rate(node_network_transmit_bytes_total{device=~"{iface}", instance=~"{instance}(:.*)?"}[{window}]) * 8
+                            / (node_network_speed_bytes{device=~"{iface}", instance=~"{instance}(:.*)?"})`,
+
+	// GPU使用率基于DCGM exporter指标，按节点求平均并换算到0-1区间
+	// 无GPU的节点查询无结果，按降级默认值处理（见queryNodeUsage）
+	"gpu": `avg(DCGM_FI_DEV_GPU_UTIL{instance=~"{instance}(:.*)?"}) / 100`,
+}
+
+// resolveDiskDevice 解析磁盘设备名，优先级：节点标签 > 插件配置 > 内置默认值
+func (d *DynamicWeight) resolveDiskDevice(node *v1.Node) string {
+	if node != nil {
+		if dev, ok := node.Labels[diskDeviceNodeLabel]; ok && dev != "" {
+			return dev
+		}
+	}
+	if d.weightLoader != nil {
+		if dev := d.weightLoader.GetWeights().DiskDevice; dev != "" {
+			return dev
+		}
+	}
+	return defaultDiskDevice
+}
+
+// resolveNetworkInterface 解析网卡名，优先级：节点标签 > 插件配置 > 内置默认值
+func (d *DynamicWeight) resolveNetworkInterface(node *v1.Node) string {
+	if node != nil {
+		if iface, ok := node.Labels[networkInterfaceNodeLabel]; ok && iface != "" {
+			return iface
+		}
+	}
+	if d.weightLoader != nil {
+		if iface := d.weightLoader.GetWeights().NetworkInterface; iface != "" {
+			return iface
+		}
+	}
+	return defaultNetworkInterface
+}
+
+// resolveQueryTemplates 合并内置模板与配置中的按资源覆盖项
+func (d *DynamicWeight) resolveQueryTemplates() map[string]string {
+	templates := make(map[string]string, len(defaultQueryTemplates))
+	for res, tpl := range defaultQueryTemplates {
+		templates[res] = tpl
+	}
+	if d.weightLoader != nil {
+		args := d.weightLoader.GetWeights()
+		for res, tpl := range args.QueryTemplates {
+			templates[res] = tpl
+		}
+		// 扩展资源维度：键为扩展资源名，与内置维度走同一查询与评分路径
+		for res, tpl := range args.ExtendedResources {
+			templates[res] = tpl
+		}
+	}
+	return templates
+}
+
+// 模板试探查询用的哨兵instance，只为验证语法，不要求有对应节点
+const templateSentinelInstance = "127.0.0.1"
+
+// validateQueryTemplates 启动时对自定义/扩展资源查询模板各做一次试探查询
+// 模板渲染哨兵instance后真实执行：语法错误立即失败并指明坏模板所在维度，
+// 空结果与网络类错误放过（Prometheus未就绪不应阻塞调度器启动）
+func (d *DynamicWeight) validateQueryTemplates(ctx context.Context) error {
+	args := d.weightLoader.GetWeights()
+	if len(args.QueryTemplates) == 0 && len(args.ExtendedResources) == 0 {
+		return nil
+	}
+	matcher := d.resolveInstanceMatcher(templateSentinelInstance)
+	diskDevice := d.resolveDiskDevice(nil)
+	netInterface := d.resolveNetworkInterface(nil)
+	rateWindow := d.resolveRateWindow()
+	for field, custom := range map[string]map[string]string{
+		"queryTemplates":    args.QueryTemplates,
+		"extendedResources": args.ExtendedResources,
+	} {
+		for res, template := range custom {
+			query := expandQueryTemplate(template, matcher, templateSentinelInstance, diskDevice, netInterface, rateWindow)
+			if _, _, err := d.promClient.Query(ctx, query, time.Now()); isPromQLBadData(err) {
+				return fmt.Errorf("%s[%s] 模板非法: %v", field, res, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isPromQLBadData 判断查询错误是否为Prometheus端的bad_data（语法/语义非法）
+// 连接失败等环境性错误不算：那说明Prometheus不可达，而非模板写错
+func isPromQLBadData(err error) bool {
+	if err == nil {
+		return false
+	}
+	var promErr *promv1.Error
+	return errors.As(err, &promErr) && promErr.Type == promv1.ErrBadData
+}
+
+// 默认rate查询窗口
+const defaultRateWindow = 5 * time.Minute
+
+// resolveRateWindow 解析rate查询窗口（PromQL时长格式），未配置时默认5m
+func (d *DynamicWeight) resolveRateWindow() string {
+	window := defaultRateWindow
+	if d.weightLoader != nil {
+		if w := d.weightLoader.GetWeights().RateWindow.Duration; w > 0 {
+			window = w
+		}
+	}
+	return model.Duration(window).String()
+}
+
+// 内置模板中节点序列的匹配表达式：按instance标签做带可选端口的正则匹配
+// InstanceMatcher配置后整体替换为自定义表达式（如node="{value}"）
+const templateInstanceMatcher = `instance=~"{instance}(:.*)?"`
+
+// resolveInstanceMatcher 渲染节点序列匹配表达式
+// identity为InstanceLabelSource解析出的节点标识（IP/主机名/节点名）
+func (d *DynamicWeight) resolveInstanceMatcher(identity string) string {
+	if d.weightLoader != nil {
+		if m := d.weightLoader.GetWeights().InstanceMatcher; m != "" {
+			return strings.ReplaceAll(m, "{value}", identity)
+		}
+	}
+	return strings.ReplaceAll(templateInstanceMatcher, "{instance}", identity)
+}
+
+// expandQueryTemplate 替换模板中的占位符
+// 先整体替换内置的instance匹配表达式（支持按node/nodename等标签键控的
+// 部署形态），再替换自定义模板中可能单独出现的{instance}
+func expandQueryTemplate(template, matcher, nodeIP, diskDevice, netInterface, rateWindow string) string {
+	query := strings.ReplaceAll(template, templateInstanceMatcher, matcher)
+	query = strings.ReplaceAll(query, "{instance}", nodeIP)
+	query = strings.ReplaceAll(query, "{device}", diskDevice)
+	query = strings.ReplaceAll(query, "{iface}", netInterface)
+	query = strings.ReplaceAll(query, "{window}", rateWindow)
+	return query
+}
+
+// requiredResources 查询失败即视为节点不可评分的核心维度
+var requiredResources = map[string]bool{
+	"cpu":    true,
+	"memory": true,
+}
+
+// fallbackUsageValues 可选维度查询失败时的内置降级默认值
+// 可通过DynamicWeightArgs.FallbackUsage按维度覆盖
+// GPU降级为中性值0.5，不影响无GPU集群
+var fallbackUsageValues = map[string]float64{
+	"diskio": 0.3,
+	"netrx":  0.1,
+	"nettx":  0.1,
+	"gpu":    0.5,
+}
+
+// queryNodeUsage 按节点IP执行各维度的Prometheus查询，构建NodeUsage
+// 遍历全部已配置模板（内置维度+自定义指标），结果写入Metrics映射：
+//   - 核心维度（cpu/memory）查询失败直接报错
+//   - 内置可选维度失败时取降级默认值
+//   - 自定义指标失败时跳过该维度（评分时忽略）
+//
+// node参数用于解析按节点覆盖的设备名，可为nil（使用配置/默认值）
+func (d *DynamicWeight) queryNodeUsage(ctx context.Context, nodeIP string, node *v1.Node) (*NodeUsage, error) {
+	usageMetrics, sampledAt, err := d.queryUsageMetrics(ctx, nodeIP, node, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return usageFromMetrics(usageMetrics, sampledAt), nil
+}
+
+// queryUsageMetrics 执行各维度查询，返回使用率映射与各维度的采样完成时刻
+// only非nil时仅查询其中的维度（按维度增量刷新用），nil查询全部模板
+func (d *DynamicWeight) queryUsageMetrics(ctx context.Context, nodeIP string, node *v1.Node, only map[string]bool) (map[string]float64, map[string]time.Time, error) {
+	// 熔断打开时整轮短路：不撞查询超时，调用方按查询失败降级
+	if d.breaker != nil && !d.breaker.allow() {
+		return nil, nil, errBreakerOpen
+	}
+	usageMetrics, sampledAt, err := d.doQueryUsageMetrics(ctx, nodeIP, node, only)
+	if d.breaker != nil {
+		if err != nil {
+			d.breaker.onFailure()
+		} else {
+			d.breaker.onSuccess()
+		}
+	}
+	return usageMetrics, sampledAt, err
+}
+
+// doQueryUsageMetrics 查询轮次的实际执行体，熔断计数由外层包装维护
+// 每个维度在查询成功时记录各自的采样完成时刻；被降级默认值顶替的
+// 维度不记采样时刻，写入缓存后立即视为过期，由增量刷新持续重查
+func (d *DynamicWeight) doQueryUsageMetrics(ctx context.Context, nodeIP string, node *v1.Node, only map[string]bool) (map[string]float64, map[string]time.Time, error) {
+	ctx, cancel := d.withQueryTimeout(ctx)
+	defer cancel()
+
+	templates := d.resolveQueryTemplates()
+	matcher := d.resolveInstanceMatcher(nodeIP)
+	diskDevice := d.resolveDiskDevice(node)
+	netInterface := d.resolveNetworkInterface(node)
+	rateWindow := d.resolveRateWindow()
+
+	var degradedResources []string
+	usageMetrics := make(map[string]float64, len(templates)+1)
+	sampledAt := make(map[string]time.Time, len(templates)+1)
+	for res, template := range templates {
+		if only != nil && !only[res] {
+			continue
+		}
+		query := expandQueryTemplate(template, matcher, nodeIP, diskDevice, netInterface, rateWindow)
+		queryCtx, cancelQuery := d.withResourceQueryTimeout(ctx, res)
+		value, err := d.timedQuery(queryCtx, res, query)
+		cancelQuery()
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				queryTimeouts.Inc()
+				// 单维度时限到期而整轮预算未耗尽：只算该维度失败，
+				// 走下方降级/剔除路径，不拖垮其余维度
+				if ctx.Err() != nil {
+					// 整轮超时中止本轮拉取：按错误返回，调用方（缓存刷新/
+					// 兜底路径）不更新缓存，Score对缓存未命中的节点给中性分
+					return nil, nil, fmt.Errorf("指标拉取超时: %v", err)
+				}
+			}
+			// 核心维度无论空结果还是硬错误都视为节点不可评分；
+			// 可选维度的空结果与查询失败同样走降级路径
+			if requiredResources[res] {
+				return nil, nil, fmt.Errorf("%s查询失败: %v", res, err)
+			}
+			// gpu在无GPU集群中查询无结果属预期，不计入降级事件
+			if res != "gpu" {
+				degradedResources = append(degradedResources, res)
+			}
+			if fallback, ok := d.resolveFallbackUsage(res); ok {
+				klog.FromContext(ctx).V(4).Info("指标不可用，使用降级默认值",
+					"resource", res, "fallback", fallback, "err", err)
+				usageMetrics[res] = fallback
+				// gpu在无GPU集群中查询无结果属预期，降级中性值视为
+				// 有效采样；其余维度不记采样时刻，等待增量重查
+				if res == "gpu" {
+					sampledAt[res] = time.Now()
+				}
+				continue
+			}
+			// 无降级默认值的维度：本轮剔除，评分时从加权和中排除并重归一化
+			klog.FromContext(ctx).Info("指标不可用且未配置降级值，本轮剔除",
+				"resource", res, "err", err)
+			continue
+		}
+		usageMetrics[res] = clampUsage(d.scaleMetric(res, value))
+		sampledAt[res] = time.Now()
+	}
+
+	// 降级状态翻转时向节点发事件，让运维感知指标管道故障/恢复
+	// 增量刷新只覆盖部分维度，不足以判断整体状态，跳过
+	if only == nil {
+		d.recordDegradation(node, degradedResources)
+	}
+
+	// 合计值 = 收 + 发，保持netio语义不变；任一方向被剔除时合计值同样缺席
+	// 合计值的采样时刻取两方向中较早者；任一方向为降级值时同样视为未采样
+	if rx, ok := usageMetrics["netrx"]; ok {
+		if tx, ok := usageMetrics["nettx"]; ok {
+			usageMetrics["netio"] = rx + tx
+			if rxTs, ok := sampledAt["netrx"]; ok {
+				if txTs, ok := sampledAt["nettx"]; ok {
+					if txTs.Before(rxTs) {
+						sampledAt["netio"] = txTs
+					} else {
+						sampledAt["netio"] = rxTs
+					}
+				}
+			}
+		}
+	}
+	return usageMetrics, sampledAt, nil
+}
+
+// recordDegradation 维护节点的指标降级状态并在翻转时发事件
+// 仅首次进入降级发Warning事件、恢复时发Normal事件（每节点限流），
+// 避免Prometheus整体故障期间每轮刷新都向全部节点刷事件
+func (d *DynamicWeight) recordDegradation(node *v1.Node, degradedResources []string) {
+	if node == nil || d.recorder == nil {
+		return
+	}
+
+	d.degradedMu.Lock()
+	wasDegraded := d.degradedNodes[node.Name]
+	nowDegraded := len(degradedResources) > 0
+	if nowDegraded == wasDegraded {
+		d.degradedMu.Unlock()
+		return
+	}
+	if nowDegraded {
+		if d.degradedNodes == nil {
+			d.degradedNodes = make(map[string]bool)
+		}
+		d.degradedNodes[node.Name] = true
+	} else {
+		delete(d.degradedNodes, node.Name)
+	}
+	d.degradedMu.Unlock()
+
+	if nowDegraded {
+		d.recorder.Eventf(node, nil, v1.EventTypeWarning, "MetricFallback", "Scoring",
+			"DynamicWeight is using fallback values for metrics %v; check the metrics pipeline", degradedResources)
+	} else {
+		d.recorder.Eventf(node, nil, v1.EventTypeNormal, "MetricRecovered", "Scoring",
+			"DynamicWeight metric queries succeeded again; fallback values no longer in use")
+	}
+}
+
+// resolveFallbackUsage 解析维度的降级使用率，优先级：配置 > 内置默认值
+func (d *DynamicWeight) resolveFallbackUsage(resource string) (float64, bool) {
+	if d.weightLoader != nil {
+		if v, ok := d.weightLoader.GetWeights().FallbackUsage[resource]; ok {
+			return v, true
+		}
+	}
+	v, ok := fallbackUsageValues[resource]
+	return v, ok
+}
+
+// withQueryTimeout 按配置为一轮指标拉取附加总超时
+// 未配置时返回原ctx（cancel为空操作），保持既有行为
+func (d *DynamicWeight) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d.weightLoader != nil {
+		if timeout := d.weightLoader.GetWeights().QueryTimeout.Duration; timeout > 0 {
+			return context.WithTimeout(ctx, timeout)
+		}
+	}
+	return ctx, func() {}
+}
+
+// withResourceQueryTimeout 按配置为单条维度查询附加超时
+// 磁盘IO等重聚合可单独给更长时限；未配置的维度沿用整轮超时
+func (d *DynamicWeight) withResourceQueryTimeout(ctx context.Context, resource string) (context.Context, context.CancelFunc) {
+	if d.weightLoader != nil {
+		if timeout, ok := d.weightLoader.GetWeights().ResourceQueryTimeouts[resource]; ok && timeout.Duration > 0 {
+			return context.WithTimeout(ctx, timeout.Duration)
+		}
+	}
+	return ctx, func() {}
+}
+
+// timedQuery 执行查询并记录按资源维度的时延直方图
+func (d *DynamicWeight) timedQuery(ctx context.Context, resource, query string) (float64, error) {
+	start := time.Now()
+	value, err := d.queryPrometheus(ctx, query)
+	queryDuration.WithLabelValues(resource).Observe(time.Since(start).Seconds())
+	d.recordQueryHealth(err == nil)
+	return value, err
+}
+
+// recordQueryHealth 记录最近一次Prometheus查询结果，驱动健康仪表
+func (d *DynamicWeight) recordQueryHealth(ok bool) {
+	d.queriedOnce.Store(true)
+	d.lastQueryOK.Store(ok)
+	if ok {
+		d.lastQuerySuccessNano.Store(time.Now().UnixNano())
+		prometheusHealthy.Set(1)
+	} else {
+		prometheusHealthy.Set(0)
+	}
+}
+
+// Healthy 报告插件是否处于健康状态：配置来源的最近一次加载成功，
+// 且新鲜窗口（两个刷新周期）内有成功的Prometheus查询
+// 返回false说明插件可能已静默退化为全降级值/中性分评分，应当告警
+func (d *DynamicWeight) Healthy() bool {
+	if d.weightLoader != nil && !d.weightLoader.Healthy() {
+		return false
+	}
+	if !d.queriedOnce.Load() {
+		// 尚未发起过任何查询（启动初期）不算异常
+		return true
+	}
+	if !d.lastQueryOK.Load() {
+		return false
+	}
+	window := 2 * d.resolveRefreshInterval()
+	return time.Since(time.Unix(0, d.lastQuerySuccessNano.Load())) <= window
+}
+
+// resolveRefreshInterval 读取配置的缓存刷新间隔，未配置时用默认值
+func (d *DynamicWeight) resolveRefreshInterval() time.Duration {
+	if d.weightLoader != nil {
+		if interval := d.weightLoader.GetWeights().RefreshInterval.Duration; interval > 0 {
+			return interval
+		}
+	}
+	return defaultRefreshInterval
+}
+
+// 统一Prometheus查询方法
+// 在有界指数退避下重试瞬时失败（次数/基础延迟可配置，默认不重试），
+// 等待期间尊重ctx的取消与超时；只有最终失败才向调用方报错
+func (d *DynamicWeight) queryPrometheus(ctx context.Context, query string) (float64, error) {
+	maxAttempts, baseDelay := d.resolveRetryPolicy()
+	var lastErr error
+	delay := baseDelay
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		value, err := d.queryPrometheusOnce(ctx, query)
+		if err == nil {
+			return value, nil
+		}
+		lastErr = err
+		if attempt == maxAttempts {
+			break
+		}
+		// 空结果是确定性的（目标实例无样本），重试无意义，直接返回
+		// 交由调用方按资源维度决定降级还是报错
+		if errors.Is(err, errEmptyResult) {
+			break
+		}
+		klog.V(4).InfoS("Prometheus查询失败，退避后重试",
+			"attempt", attempt, "maxAttempts", maxAttempts, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("查询重试被中止: %v (最近一次错误: %v)", ctx.Err(), lastErr)
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return 0, lastErr
+}
+
+// 默认的重试退避基础延迟
+const defaultQueryRetryBaseDelay = 100 * time.Millisecond
+
+// resolveRetryPolicy 解析重试策略，未配置时单次尝试（保持既有行为）
+func (d *DynamicWeight) resolveRetryPolicy() (int, time.Duration) {
+	maxAttempts := 1
+	baseDelay := defaultQueryRetryBaseDelay
+	if d.weightLoader != nil {
+		args := d.weightLoader.GetWeights()
+		if args.QueryMaxAttempts > 0 {
+			maxAttempts = args.QueryMaxAttempts
+		}
+		if args.QueryRetryBaseDelay.Duration > 0 {
+			baseDelay = args.QueryRetryBaseDelay.Duration
+		}
+	}
+	return maxAttempts, baseDelay
+}
+
+// errEmptyResult 查询成功但结果为空（如node-exporter下线、instance标签不匹配）
+// 区别于硬错误：调用方可按资源维度选择降级默认值而不是整体失败
+var errEmptyResult = errors.New("查询结果为空")
+
+// 平滑查询的默认回溯窗口与采样步长
+const (
+	defaultSmoothingWindow = 2 * time.Minute
+	smoothingStep          = 30 * time.Second
+)
+
+// queryPrometheusOnce 执行单次查询并解析向量结果
+// 启用平滑时改走区间查询取均值；Prometheus返回的警告（如部分分片超时）
+// 记录日志但不影响取值
+func (d *DynamicWeight) queryPrometheusOnce(ctx context.Context, query string) (float64, error) {
+	if d.weightLoader != nil && d.weightLoader.GetWeights().SmoothingEnabled {
+		return d.queryPrometheusRangeAvg(ctx, query)
+	}
+	result, warnings, err := d.promClient.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if len(warnings) > 0 {
+		klog.V(2).InfoS("Prometheus查询返回警告", "query", query, "warnings", warnings)
+	}
+
+	// 解析向量类型结果：类型不符是硬错误，空向量返回哨兵错误
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("无效的查询结果格式: %T", result)
+	}
+	if len(vector) == 0 {
+		return 0, fmt.Errorf("%w: %s", errEmptyResult, query)
+	}
+	// 超龄样本按缺失处理：缓存TTL分不清"结果新鲜"与"指标本身停更"，
+	// 样本自带的时间戳可以（node-exporter停抓后Prometheus返回旧样本）
+	if maxAge := d.resolveMaxDataAge(); maxAge > 0 {
+		if age := time.Since(vector[0].Timestamp.Time()); age > maxAge {
+			return 0, fmt.Errorf("%w: 样本时间戳超龄(%v > maxDataAge %v): %s",
+				errEmptyResult, age.Truncate(time.Second), maxAge, query)
+		}
+	}
+
+	return float64(vector[0].Value), nil
+}
+
+// resolveMaxDataAge 读取配置的样本最大年龄，0表示关闭检查
+func (d *DynamicWeight) resolveMaxDataAge() time.Duration {
+	if d.weightLoader != nil {
+		return d.weightLoader.GetWeights().MaxDataAge.Duration
+	}
+	return 0
+}
+
+// queryPrometheusRangeAvg 区间查询并对全部采样点求平均（平滑模式）
+// 瞬时查询对单次坏抓取敏感，短回溯窗口内的均值更平稳
+func (d *DynamicWeight) queryPrometheusRangeAvg(ctx context.Context, query string) (float64, error) {
+	window := defaultSmoothingWindow
+	if w := d.weightLoader.GetWeights().SmoothingWindow.Duration; w > 0 {
+		window = w
+	}
+	end := time.Now()
+	r := promv1.Range{Start: end.Add(-window), End: end, Step: smoothingStep}
+
+	result, warnings, err := d.promClient.QueryRange(ctx, query, r)
+	if err != nil {
+		return 0, err
+	}
+	if len(warnings) > 0 {
+		klog.V(2).InfoS("Prometheus区间查询返回警告", "query", query, "warnings", warnings)
+	}
+
+	matrix, ok := result.(model.Matrix)
+	if !ok {
+		return 0, fmt.Errorf("无效的区间查询结果格式: %T", result)
+	}
+
+	var sum float64
+	var count int
+	for _, series := range matrix {
+		for _, point := range series.Values {
+			sum += float64(point.Value)
+			count++
+		}
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("%w: %s", errEmptyResult, query)
+	}
+	return sum / float64(count), nil
+}
+
+// 初始化工厂函数
+// DynamicWeight特性门控关闭时返回空操作实现：保留调度框架的接线，
+// 但不访问Prometheus也不参与评分，便于实验性插件默认关闭发布
+func New(ctx context.Context, args runtime.Object, h framework.Handle, fts feature.Features) (framework.Plugin, error) {
+	if !fts.EnableDynamicWeight {
+		klog.V(2).InfoS("DynamicWeight特性门控关闭，使用空操作实现")
+		return &disabledDynamicWeight{}, nil
+	}
+
+	// 解析插件配置参数（含TLS相关字段）
+	dwArgs, err := NewDynamicWeightArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("解析插件配置失败: %v", err)
+	}
+
+	// 先展开环境变量引用再校验：校验与后续初始化都基于实际取值
+	dwArgs.expandEnv()
+
+	// 校验配置
+	if err := dwArgs.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败: %v", err)
+	}
+
+	// 初始化Prometheus客户端
+	promClient, err := initPrometheusClient(dwArgs)
+	if err != nil {
+		return nil, fmt.Errorf("初始化Prometheus客户端失败: %v", err)
+	}
+
+	// 初始化权重加载器：默认直接使用插件args中的静态配置，
+	// 配置ConfigMapName或WeightSource时才接入外部配置源（带热更新）
+	var dynClient dynamic.Interface
+	if dwArgs.WeightSource == weightSourceCRD {
+		dynClient, err = dynamic.NewForConfig(h.KubeConfig())
+		if err != nil {
+			return nil, fmt.Errorf("初始化动态客户端失败: %v", err)
+		}
+	}
+	weightLoader, err := newWeightLoaderForArgs(ctx, h.ClientSet(), dynClient, dwArgs)
+	if err != nil {
+		return nil, fmt.Errorf("配置加载失败: %v", err)
+	}
+
+	// 注册插件指标
+	registerMetrics()
+
+	// 缓存有效期：与刷新间隔独立配置，决定多旧的数据仍可用于评分
+	cacheTTL := defaultCacheTTL
+	if dwArgs.CacheTTL.Duration > 0 {
+		cacheTTL = dwArgs.CacheTTL.Duration
+	}
+
+	// 构建插件实例
+	plugin := newDynamicWeight(h, weightLoader, promClient,
+		NewNodeUsageCache(cacheTTL, defaultCacheMaxEntries),
+		h.SharedInformerFactory().Core().V1().Nodes().Lister(),
+		h.EventRecorder())
+
+	// 节点下线时清理对应的缓存条目，避免残留已删除节点的数据
+	h.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(clientgocache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			node, ok := obj.(*v1.Node)
+			if !ok {
+				tombstone, ok := obj.(clientgocache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				node, ok = tombstone.Obj.(*v1.Node)
+				if !ok {
+					return
+				}
+			}
+			plugin.cache.Delete(node.Name)
+			plugin.forgetNodeInstance(node.Name)
+		},
+		// 封锁/排空或地址变化时立即作废缓存，不等TTL自然过期
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, ok := oldObj.(*v1.Node)
+			if !ok {
+				return
+			}
+			newNode, ok := newObj.(*v1.Node)
+			if !ok {
+				return
+			}
+			if nodeCacheInvalidated(oldNode, newNode) {
+				plugin.cache.Invalidate(newNode.Name)
+				plugin.forgetNodeInstance(newNode.Name)
+			}
+		},
+	})
+
+	// 备用指标源：metrics-server仅覆盖CPU/内存，Prometheus故障期兜底
+	if dwArgs.MetricsServerFallback {
+		metricsClient, err := resourceclient.NewForConfig(h.KubeConfig())
+		if err != nil {
+			return nil, fmt.Errorf("初始化metrics-server客户端失败: %v", err)
+		}
+		plugin.secondary = &metricsServerProvider{client: metricsClient}
+	}
+
+	// 自定义查询模板启动时做一次试探查询，语法错误快速失败
+	// 避免typo直到第一次Score才逐节点暴露
+	if err := plugin.validateQueryTemplates(ctx); err != nil {
+		return nil, fmt.Errorf("查询模板校验失败: %v", err)
+	}
+
+	// 启动后台缓存刷新协程（随调度器上下文退出）
+	refreshInterval := defaultRefreshInterval
+	if dwArgs.RefreshInterval.Duration > 0 {
+		refreshInterval = dwArgs.RefreshInterval.Duration
+	}
+	go plugin.startCacheRefresher(ctx, refreshInterval)
+
+	// 调试端点：仅在显式配置监听地址时启动（暴露集群内部状态）
+	if dwArgs.DebugAddress != "" {
+		plugin.startDebugServer(ctx, dwArgs.DebugAddress)
+	}
+
+	// 启动缓存过期条目清理协程（随调度器上下文退出）
+	plugin.cache.StartGC(ctx, defaultCacheGCInterval)
+
+	return plugin, nil
+}
+
+// nodeCacheInvalidated 判断节点更新是否使既有用量缓存失效
+// 封锁状态翻转（cordon/drain）意味着负载即将迁移，地址变化意味着
+// 指标抓取目标变了，两种情况下旧的使用率数据都不应再参与评分
+func nodeCacheInvalidated(oldNode, newNode *v1.Node) bool {
+	if oldNode.Spec.Unschedulable != newNode.Spec.Unschedulable {
+		return true
+	}
+	if len(oldNode.Status.Addresses) != len(newNode.Status.Addresses) {
+		return true
+	}
+	for i := range oldNode.Status.Addresses {
+		if oldNode.Status.Addresses[i] != newNode.Status.Addresses[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// disabledDynamicWeight DynamicWeight特性门控关闭时的空操作实现
+// 实现与DynamicWeight相同的扩展点，保证Profile中启用该插件时框架接线不报错：
+// 评分恒为0（不影响节点排序），过滤与预取直接放行
+type disabledDynamicWeight struct{}
+
+var _ framework.ScorePlugin = &disabledDynamicWeight{}
+var _ framework.PreScorePlugin = &disabledDynamicWeight{}
+var _ framework.FilterPlugin = &disabledDynamicWeight{}
+var _ framework.PreFilterPlugin = &disabledDynamicWeight{}
+var _ framework.ReservePlugin = &disabledDynamicWeight{}
+
+func (d *disabledDynamicWeight) Name() string {
+	return Name
+}
+
+func (d *disabledDynamicWeight) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	return 0, nil
+}
+
+func (d *disabledDynamicWeight) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+func (d *disabledDynamicWeight) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (d *disabledDynamicWeight) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	return nil
+}
+
+func (d *disabledDynamicWeight) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	return nil, nil
+}
+
+func (d *disabledDynamicWeight) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	return nil
+}
+
+func (d *disabledDynamicWeight) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+}
+
+func (d *disabledDynamicWeight) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
 }
@@ -0,0 +1,4126 @@
+// pkg/scheduler/framework/plugins/dynamicweight/plugin_test.go
+package dynamicweight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	clientgocache "k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	internalcache "k8s.io/kubernetes/pkg/scheduler/backend/cache"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	schedmetrics "k8s.io/kubernetes/pkg/scheduler/metrics"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+)
+
+// 编译期断言：registry.go中的注册依赖这两个签名
+// New经FactoryAdapter接入DynamicWeight特性门控，NewPodFilter不门控
+var _ frameworkruntime.PluginFactoryWithFts = New
+var _ frameworkruntime.PluginFactory = NewPodFilter
+
+// fakePromAPI 只实现Query方法的Prometheus客户端桩
+// 按查询语句中的指标名子串匹配返回预设值，未匹配时返回错误
+type fakePromAPI struct {
+	promv1.API
+	values map[string]float64 // 指标名子串 -> 返回值
+	// 指标名子串 -> instance标签 -> 返回值（模拟按instance分组的批量查询）
+	instanceValues map[string]map[string]float64
+	sampleTime     time.Time // 非零时作为返回样本的时间戳（模拟停更的指标）
+	queries        []string  // 记录收到的查询语句
+}
+
+// sampleTimestamp 返回样本应携带的时间戳；未设置时取当前时间
+func (f *fakePromAPI) sampleTimestamp() model.Time {
+	ts := f.sampleTime
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return model.TimeFromUnixNano(ts.UnixNano())
+}
+
+func (f *fakePromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	for substr, byInstance := range f.instanceValues {
+		if strings.Contains(query, substr) {
+			vector := model.Vector{}
+			for instance, v := range byInstance {
+				vector = append(vector, &model.Sample{
+					Metric:    model.Metric{"instance": model.LabelValue(instance)},
+					Value:     model.SampleValue(v),
+					Timestamp: f.sampleTimestamp(),
+				})
+			}
+			return vector, nil, nil
+		}
+	}
+	for substr, v := range f.values {
+		if strings.Contains(query, substr) {
+			return model.Vector{&model.Sample{Value: model.SampleValue(v), Timestamp: f.sampleTimestamp()}}, nil, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+// fakeWeightLoader 返回固定配置的加载器桩
+type fakeWeightLoader struct {
+	args *DynamicWeightArgs
+}
+
+func (f *fakeWeightLoader) GetWeights() *DynamicWeightArgs {
+	return f.args
+}
+
+func (f *fakeWeightLoader) Healthy() bool {
+	return true
+}
+
+// 验证网络查询按收/发方向拆分，且合计值等于两方向之和
+func TestQueryNodeUsageSplitNetwork(t *testing.T) {
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.15,
+			"node_network_transmit_bytes_total": 0.05,
+		},
+	}
+	d := &DynamicWeight{
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+
+	if usage.NetworkRx != 0.15 {
+		t.Errorf("NetworkRx = %v, 期望 0.15", usage.NetworkRx)
+	}
+	if usage.NetworkTx != 0.05 {
+		t.Errorf("NetworkTx = %v, 期望 0.05", usage.NetworkTx)
+	}
+	if usage.Network != 0.2 {
+		t.Errorf("Network = %v, 期望 0.2（收发合计）", usage.Network)
+	}
+
+	// 收/发方向必须是两条独立的查询
+	var rxQueried, txQueried bool
+	for _, q := range prom.queries {
+		hasRx := strings.Contains(q, "node_network_receive_bytes_total")
+		hasTx := strings.Contains(q, "node_network_transmit_bytes_total")
+		if hasRx && hasTx {
+			t.Errorf("收发方向未拆分为独立查询: %s", q)
+		}
+		rxQueried = rxQueried || hasRx
+		txQueried = txQueried || hasTx
+	}
+	if !rxQueried || !txQueried {
+		t.Errorf("缺少方向查询: rx=%v tx=%v", rxQueried, txQueried)
+	}
+}
+
+// badDataPromAPI 对匹配子串的查询返回bad_data错误（模拟PromQL语法错误），
+// 其余查询返回空向量；记录查询语句供断言
+type badDataPromAPI struct {
+	promv1.API
+	badSubstring string
+	queries      []string
+}
+
+func (f *badDataPromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.queries = append(f.queries, query)
+	if f.badSubstring != "" && strings.Contains(query, f.badSubstring) {
+		return nil, nil, &promv1.Error{Type: promv1.ErrBadData, Msg: "parse error: unexpected end of input"}
+	}
+	return model.Vector{}, nil, nil
+}
+
+// 验证启动时的模板试探查询：语法错误快速失败，空结果与网络错误放过
+func TestValidateQueryTemplates(t *testing.T) {
+	// 语法非法的模板：试探查询返回bad_data，校验失败并指明维度
+	prom := &badDataPromAPI{badSubstring: "rate(node_cpu{"}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			QueryTemplates: map[string]string{
+				"cpu": `rate(node_cpu{instance=~"{instance}(:.*)?"`,
+			},
+		}},
+		promClient: prom,
+	}
+	err := d.validateQueryTemplates(context.Background())
+	if err == nil {
+		t.Fatal("语法非法的模板应校验失败")
+	}
+	if !strings.Contains(err.Error(), "cpu") {
+		t.Errorf("错误信息未指明坏模板所在维度: %v", err)
+	}
+
+	// 合法模板但无数据：空结果应被容忍
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		QueryTemplates: map[string]string{
+			"cpu": `rate(node_cpu_seconds_total{instance=~"{instance}(:.*)?"}[{window}])`,
+		},
+	}}
+	d.promClient = &badDataPromAPI{}
+	if err := d.validateQueryTemplates(context.Background()); err != nil {
+		t.Errorf("空结果不应导致校验失败: %v", err)
+	}
+
+	// Prometheus不可达：环境性错误不算模板问题，不阻塞启动
+	d.promClient = &fakePromAPI{} // 未配置values，任何查询都返回普通错误
+	if err := d.validateQueryTemplates(context.Background()); err != nil {
+		t.Errorf("连接类错误不应导致校验失败: %v", err)
+	}
+
+	// 未配置自定义模板：不发起任何试探查询
+	probe := &badDataPromAPI{}
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{}}
+	d.promClient = probe
+	if err := d.validateQueryTemplates(context.Background()); err != nil {
+		t.Errorf("无自定义模板不应报错: %v", err)
+	}
+	if len(probe.queries) != 0 {
+		t.Errorf("无自定义模板不应发起试探查询: %v", probe.queries)
+	}
+}
+
+// 验证四个内置维度统一在0-1刻度上：查询模板不得做百分比换算
+// （评分按weight*(1-usage)消费0-1使用率，乘100会被clamp压成恒1.0）
+func TestUsageDimensionsShareUnitScale(t *testing.T) {
+	for name, templates := range map[string]map[string]string{
+		"defaultQueryTemplates": defaultQueryTemplates,
+		"batchQueryTemplates":   batchQueryTemplates,
+	} {
+		for _, res := range []string{"netrx", "nettx"} {
+			if strings.Contains(templates[res], "* 100") {
+				t.Errorf("%s[%s] 仍在换算百分比，与其他维度的0-1刻度不一致", name, res)
+			}
+		}
+	}
+
+	// 各维度注入已知的0-1比值，Score消费的四个维度应原样落在同一刻度
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.15,
+			"node_network_transmit_bytes_total": 0.05,
+		},
+	}
+	d := &DynamicWeight{
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	for res, want := range map[string]float64{
+		"cpu": 0.5, "memory": 0.4, "diskio": 0.3, "netio": 0.2,
+	} {
+		got, ok := usage.Get(res)
+		if !ok {
+			t.Fatalf("缺少维度 %s", res)
+		}
+		if got != want {
+			t.Errorf("%s = %v, 期望 %v", res, got, want)
+		}
+		if got < 0 || got > 1 {
+			t.Errorf("%s = %v 超出0-1刻度", res, got)
+		}
+	}
+}
+
+// TestInstanceMatcher 验证节点序列匹配表达式：默认按instance标签正则
+// 匹配，配置后改按自定义标签（如ServiceMonitor惯例下的node标签）键控
+func TestInstanceMatcher(t *testing.T) {
+	newProm := func() *fakePromAPI {
+		return &fakePromAPI{
+			values: map[string]float64{
+				"node_cpu_seconds_total":            0.5,
+				"node_memory_MemTotal_bytes":        0.4,
+				"node_disk_io_time_seconds_total":   0.3,
+				"node_network_receive_bytes_total":  0.1,
+				"node_network_transmit_bytes_total": 0.1,
+			},
+		}
+	}
+
+	// 默认风格：instance标签带可选端口的正则匹配
+	prom := newProm()
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient:   prom,
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	for _, q := range prom.queries {
+		if !strings.Contains(q, `instance=~"10.0.0.1(:.*)?"`) {
+			t.Errorf("默认风格查询应按instance正则匹配: %s", q)
+		}
+	}
+
+	// 节点名风格：内置模板的匹配表达式整体替换为node标签精确匹配
+	prom = newProm()
+	d = &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			InstanceMatcher:     `node="{value}"`,
+			InstanceLabelSource: instanceSourceNodeName,
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	if _, err := d.queryNodeUsage(context.Background(), "node-1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	for _, q := range prom.queries {
+		if !strings.Contains(q, `node="node-1"`) {
+			t.Errorf("自定义匹配风格查询应按node标签键控: %s", q)
+		}
+		if strings.Contains(q, "instance=~") {
+			t.Errorf("自定义匹配风格不应残留instance正则: %s", q)
+		}
+	}
+}
+
+// TestInstanceMatcherValidation 验证匹配表达式必须带{value}占位符
+func TestInstanceMatcherValidation(t *testing.T) {
+	args := &DynamicWeightArgs{InstanceMatcher: `node="fixed"`}
+	if err := args.Validate(); err == nil {
+		t.Error("缺少{value}占位符的instanceMatcher应校验失败")
+	}
+	args = &DynamicWeightArgs{InstanceMatcher: `nodename="{value}"`}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法instanceMatcher不应校验失败: %v", err)
+	}
+}
+
+// 验证可按资源维度覆盖查询模板，未覆盖的维度继续使用内置模板
+func TestQueryTemplateOverride(t *testing.T) {
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"my_custom_cpu_metric":              0.7,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		},
+	}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			QueryTemplates: map[string]string{
+				"cpu": `my_custom_cpu_metric{instance="{instance}"}`,
+			},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.9", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if usage.CPU != 0.7 {
+		t.Errorf("CPU = %v, 期望使用覆盖模板的结果 0.7", usage.CPU)
+	}
+	if usage.Memory != 0.4 {
+		t.Errorf("Memory = %v, 期望使用内置模板的结果 0.4", usage.Memory)
+	}
+
+	// 占位符必须已替换为节点IP
+	found := false
+	for _, q := range prom.queries {
+		if strings.Contains(q, "my_custom_cpu_metric") {
+			found = true
+			if !strings.Contains(q, `instance="10.0.0.9"`) {
+				t.Errorf("模板占位符未替换为节点IP: %s", q)
+			}
+			if strings.Contains(q, "{instance}") {
+				t.Errorf("查询中仍残留{instance}占位符: %s", q)
+			}
+		}
+	}
+	if !found {
+		t.Error("覆盖后的CPU查询模板未被使用")
+	}
+}
+
+// 验证自定义指标：配置QueryTemplates新增维度即可参与采集与评分
+func TestCustomMetricDimension(t *testing.T) {
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+			"my_iops_saturation":                0.25,
+		},
+	}
+	loader := &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"iops": 1.0},
+		QueryTemplates: map[string]string{
+			"iops": `my_iops_saturation{instance=~"{instance}(:.*)?"}`,
+		},
+	}}
+	d := &DynamicWeight{
+		weightLoader: loader,
+		promClient:   prom,
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if got, ok := usage.Get("iops"); !ok || got != 0.25 {
+		t.Errorf("iops = %v(ok=%v), 期望 0.25", got, ok)
+	}
+
+	// 自定义维度参与评分
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 75 { // 1 - 0.25
+		t.Errorf("score = %d, 期望 75", score)
+	}
+
+	// 自定义指标查询失败：跳过该维度而非报错，评分忽略
+	delete(prom.values, "my_iops_saturation")
+	usage, err = d.queryNodeUsage(context.Background(), "10.0.0.2", nil)
+	if err != nil {
+		t.Fatalf("自定义指标失败不应报错: %v", err)
+	}
+	if _, ok := usage.Get("iops"); ok {
+		t.Error("查询失败的自定义指标不应出现在结果中")
+	}
+}
+
+// 验证GPU维度：有DCGM指标时采集使用率，无指标时降级为中性值
+func TestQueryNodeUsageGPU(t *testing.T) {
+	baseValues := func() map[string]float64 {
+		return map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		}
+	}
+
+	// GPU节点：DCGM指标可用
+	values := baseValues()
+	values["DCGM_FI_DEV_GPU_UTIL"] = 0.8
+	d := &DynamicWeight{
+		promClient: &fakePromAPI{values: values},
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if usage.GPU != 0.8 {
+		t.Errorf("GPU = %v, 期望 0.8", usage.GPU)
+	}
+
+	// 无GPU节点：查询失败时降级为中性值，不报错
+	d = &DynamicWeight{
+		promClient: &fakePromAPI{values: baseValues()},
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	usage, err = d.queryNodeUsage(context.Background(), "10.0.0.2", nil)
+	if err != nil {
+		t.Fatalf("无GPU节点不应报错: %v", err)
+	}
+	if usage.GPU != 0.5 {
+		t.Errorf("GPU = %v, 期望降级中性值 0.5", usage.GPU)
+	}
+}
+
+// 验证gpu权重参与评分
+func TestScoreGPUWeight(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"gpu": 1.0},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", &NodeUsage{GPU: 0.75})
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 25 { // 1 - 0.75
+		t.Errorf("score = %d, 期望 25", score)
+	}
+}
+
+// TestPackingBias 验证spread/binpack连续混合系数：
+// 端点行为等价纯spread/纯binpack，中点对所有节点给出相同贡献分
+func TestPackingBias(t *testing.T) {
+	scoreWith := func(t *testing.T, bias float64, nodeUsage map[string]float64) map[string]int64 {
+		t.Helper()
+		d := &DynamicWeight{
+			weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 1.0},
+				PackingBias:    &bias,
+			}},
+			cache: NewNodeUsageCache(5*time.Minute, 0),
+		}
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+		scores := map[string]int64{}
+		for node, usage := range nodeUsage {
+			d.cache.Set(node, &NodeUsage{CPU: usage})
+			score, status := d.Score(context.Background(), nil, pod, node)
+			if !status.IsSuccess() {
+				t.Fatalf("Score失败: %v", status)
+			}
+			scores[node] = score
+		}
+		return scores
+	}
+	usages := map[string]float64{"node-low": 0.2, "node-high": 0.8}
+
+	// 浮点截断允许差一分
+	near := func(got, want int64) bool { return got == want || got == want-1 }
+
+	// bias=-1：纯spread，低负载节点得分高
+	scores := scoreWith(t, -1, usages)
+	if !near(scores["node-low"], 80) || !near(scores["node-high"], 20) {
+		t.Errorf("bias=-1 得分 = %v, 期望 node-low:80 node-high:20", scores)
+	}
+
+	// bias=+1：纯binpack，排序翻转
+	scores = scoreWith(t, 1, usages)
+	if !near(scores["node-low"], 20) || !near(scores["node-high"], 80) {
+		t.Errorf("bias=+1 得分 = %v, 期望 node-low:20 node-high:80", scores)
+	}
+
+	// bias=0：中性，两节点得分相同
+	scores = scoreWith(t, 0, usages)
+	if scores["node-low"] != 50 || scores["node-high"] != 50 {
+		t.Errorf("bias=0 得分 = %v, 期望两节点均为50", scores)
+	}
+
+	// 中间值：偏binpack但未到端点，高负载节点领先且差距小于纯binpack
+	scores = scoreWith(t, 0.5, usages)
+	if scores["node-high"] <= scores["node-low"] {
+		t.Errorf("bias=0.5 得分 = %v, 期望node-high领先", scores)
+	}
+	if diff := scores["node-high"] - scores["node-low"]; diff >= 60 {
+		t.Errorf("bias=0.5 分差 = %d, 应小于纯binpack的60", diff)
+	}
+}
+
+// TestExcludeNodeSelector 验证节点排除：命中选择器的节点恒得中性分，
+// 后台刷新不对其发起任何查询
+func TestExcludeNodeSelector(t *testing.T) {
+	excluded := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "node-cp",
+			Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+		},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	worker := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-worker"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+		}},
+	}
+	prom := newBatchPromAPI(map[string]float64{
+		"10.0.0.1": 0.2,
+		"10.0.0.2": 0.4,
+	})
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			ExcludeNodeSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+			},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: newFakeNodeLister(t, excluded, worker),
+	}
+
+	// 后台刷新：排除节点不应出现在任何查询语句中，也不应写入缓存
+	d.refreshAllNodes(context.Background())
+	for _, q := range prom.queries {
+		if strings.Contains(q, `10\.0\.0\.1`) {
+			t.Errorf("排除节点的instance不应出现在查询中: %s", q)
+		}
+	}
+	if d.cache.Get("node-cp") != nil {
+		t.Error("排除节点不应写入缓存")
+	}
+	if d.cache.Get("node-worker") == nil {
+		t.Error("未排除的节点应正常刷新")
+	}
+
+	// 即使缓存里有数据，排除节点的Score也恒返回中性分
+	d.cache.Set("node-cp", &NodeUsage{CPU: 0.9, Metrics: map[string]float64{"cpu": 0.9}})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "node-cp")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("排除节点得分 = %d, 期望中性分 %d", score, neutralScore)
+	}
+
+	// 未排除的节点照常按使用率评分
+	score, status = d.Score(context.Background(), nil, pod, "node-worker")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score == neutralScore {
+		t.Errorf("未排除节点得分 = %d, 不应恒为中性分", score)
+	}
+}
+
+// TestExcludeNodeSelectorValidation 验证排除选择器的配置校验
+func TestExcludeNodeSelectorValidation(t *testing.T) {
+	args := &DynamicWeightArgs{ExcludeNodeSelector: &metav1.LabelSelector{}}
+	if err := args.Validate(); err == nil {
+		t.Error("空选择器会排除所有节点，应校验失败")
+	}
+	args = &DynamicWeightArgs{ExcludeNodeSelector: &metav1.LabelSelector{
+		MatchLabels: map[string]string{"dedicated": "db"},
+	}}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法选择器不应校验失败: %v", err)
+	}
+}
+
+// TestPackingBiasValidation 验证混合系数的区间校验
+func TestPackingBiasValidation(t *testing.T) {
+	bias := 1.5
+	args := &DynamicWeightArgs{PackingBias: &bias}
+	if err := args.Validate(); err == nil {
+		t.Error("超出[-1,1]的packingBias应校验失败")
+	}
+	bias = -1.0
+	if err := args.Validate(); err != nil {
+		t.Errorf("边界值-1.0不应校验失败: %v", err)
+	}
+}
+
+// newBatchPromAPI 构造覆盖指定实例集的批量查询桩
+func newBatchPromAPI(instances map[string]float64) *fakePromAPI {
+	byInstance := func(offset float64) map[string]float64 {
+		m := make(map[string]float64, len(instances))
+		for inst, v := range instances {
+			m[inst] = v + offset
+		}
+		return m
+	}
+	return &fakePromAPI{
+		instanceValues: map[string]map[string]float64{
+			"node_cpu_seconds_total":            byInstance(0),
+			"node_memory_MemTotal_bytes":        byInstance(0.1),
+			"node_disk_io_time_seconds_total":   byInstance(0.2),
+			"node_network_receive_bytes_total":  byInstance(0.05),
+			"node_network_transmit_bytes_total": byInstance(0.05),
+			"DCGM_FI_DEV_GPU_UTIL":              byInstance(0.3),
+		},
+	}
+}
+
+// 验证IPv6实例的批量样本能匹配回节点：instance标签按host:port解析，
+// 不能按首个冒号截断（会把"[fd00::1]:9100"截成"[fd00"）
+func TestBatchQueryIPv6Instance(t *testing.T) {
+	prom := newBatchPromAPI(map[string]float64{"[fd00::1]:9100": 0.2})
+	d := &DynamicWeight{
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: &fakeNodeLister{nodes: []*v1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-v6"},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+			}},
+		}}},
+	}
+
+	if err := d.WarmAll(context.Background(), []string{"fd00::1"}); err != nil {
+		t.Fatalf("WarmAll失败: %v", err)
+	}
+	usage := d.cache.Get("node-v6")
+	if usage == nil {
+		t.Fatal("IPv6节点的批量结果未写入缓存")
+	}
+	if usage.CPU != 0.2 {
+		t.Errorf("cpu = %v, 期望 0.2", usage.CPU)
+	}
+}
+
+// TestMaxDataAge 验证样本时间戳超龄时按缺失处理（与缓存过期独立）
+func TestMaxDataAge(t *testing.T) {
+	newD := func(sampleTime time.Time, maxAge time.Duration) *DynamicWeight {
+		return &DynamicWeight{
+			weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+				MaxDataAge: metav1.Duration{Duration: maxAge},
+			}},
+			promClient: &fakePromAPI{
+				values:     map[string]float64{"up": 0.5},
+				sampleTime: sampleTime,
+			},
+			cache: NewNodeUsageCache(5*time.Minute, 0),
+		}
+	}
+
+	// 回拨5分钟的样本超过2分钟的maxDataAge：按查询结果为空处理
+	d := newD(time.Now().Add(-5*time.Minute), 2*time.Minute)
+	if _, err := d.queryPrometheus(context.Background(), "up"); !errors.Is(err, errEmptyResult) {
+		t.Errorf("超龄样本应按缺失处理, err = %v", err)
+	}
+
+	// 新鲜样本正常取值
+	d = newD(time.Now(), 2*time.Minute)
+	if v, err := d.queryPrometheus(context.Background(), "up"); err != nil || v != 0.5 {
+		t.Errorf("新鲜样本取值 = %v, err = %v, 期望 0.5", v, err)
+	}
+
+	// 未配置maxDataAge时不做检查（保持既有行为）
+	d = newD(time.Now().Add(-time.Hour), 0)
+	if v, err := d.queryPrometheus(context.Background(), "up"); err != nil || v != 0.5 {
+		t.Errorf("关闭检查时取值 = %v, err = %v, 期望 0.5", v, err)
+	}
+}
+
+// TestMaxDataAgeBatch 验证批量查询跳过超龄样本，对应节点按无样本回退
+func TestMaxDataAgeBatch(t *testing.T) {
+	prom := newBatchPromAPI(map[string]float64{"10.0.0.1": 0.2})
+	prom.sampleTime = time.Now().Add(-10 * time.Minute)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			MaxDataAge: metav1.Duration{Duration: 2 * time.Minute},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usages, err := d.batchQueryNodeUsage(context.Background(),
+		[]string{`10\.0\.0\.1`}, map[string]string{"10.0.0.1": "node-1"})
+	if err != nil {
+		t.Fatalf("batchQueryNodeUsage失败: %v", err)
+	}
+	if _, ok := usages["node-1"]; ok {
+		t.Errorf("超龄样本的节点不应出现在批量结果中: %+v", usages)
+	}
+}
+
+// TestWarmAll 验证批量预热：一轮按instance分组的查询填充多个节点的
+// 缓存条目；无样本的instance被跳过；不覆盖更新鲜的已有条目
+func TestWarmAll(t *testing.T) {
+	node1 := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	node2 := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+		}},
+	}
+	node3 := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-3"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.3"},
+		}},
+	}
+
+	// 返回的多instance向量只覆盖node-1/node-2；node-3无样本
+	prom := newBatchPromAPI(map[string]float64{
+		"10.0.0.1": 0.2,
+		"10.0.0.2": 0.6,
+	})
+	d := &DynamicWeight{
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: newFakeNodeLister(t, node1, node2, node3),
+	}
+
+	// 模拟预热查询期间逐节点路径写入的数据：时间戳晚于本轮cutoff，
+	// 批量结果不应覆盖
+	fresh := &NodeUsage{CPU: 0.99, Metrics: map[string]float64{"cpu": 0.99}}
+	d.cache.Set("node-2", fresh)
+	fresh.Timestamp = time.Now().Add(time.Second)
+
+	if err := d.WarmAll(context.Background(), []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}); err != nil {
+		t.Fatalf("WarmAll失败: %v", err)
+	}
+
+	// 每个资源维度只应发一次查询（HTTP开销按节点集合摊薄）
+	if got := len(prom.queries); got != len(batchQueryTemplates) {
+		t.Errorf("查询次数 = %d, 期望每维度一次共 %d 次", got, len(batchQueryTemplates))
+	}
+
+	usage1 := d.cache.Get("node-1")
+	if usage1 == nil {
+		t.Fatal("node-1应被批量预热填充")
+	}
+	if usage1.CPU != 0.2 {
+		t.Errorf("node-1 CPU = %v, 期望 0.2", usage1.CPU)
+	}
+	if usage2 := d.cache.Get("node-2"); usage2 == nil || usage2.CPU != 0.99 {
+		t.Errorf("node-2的新鲜条目被批量结果覆盖: %+v", usage2)
+	}
+	if usage3 := d.cache.Get("node-3"); usage3 != nil {
+		t.Errorf("无样本的node-3不应写入缓存: %+v", usage3)
+	}
+
+	// 陈旧条目应被批量结果刷新
+	aged := time.Now().Add(-time.Minute)
+	fresh.Timestamp = aged
+	if err := d.WarmAll(context.Background(), []string{"10.0.0.2"}); err != nil {
+		t.Fatalf("WarmAll失败: %v", err)
+	}
+	if usage2 := d.cache.Get("node-2"); usage2 == nil || usage2.CPU != 0.6 {
+		t.Errorf("node-2的陈旧条目未被批量结果刷新: %+v", usage2)
+	}
+}
+
+func newTestNodeInfos(ips ...string) []*framework.NodeInfo {
+	nodeInfos := make([]*framework.NodeInfo, 0, len(ips))
+	for i, ip := range ips {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%d", i+1)},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: ip},
+			}},
+		}
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+	return nodeInfos
+}
+
+// 验证PreScore一次批量查询覆盖全部候选节点，Score从CycleState取数
+func TestPreScoreBatch(t *testing.T) {
+	prom := newBatchPromAPI(map[string]float64{
+		"10.0.0.1:9100": 0.2,
+		"10.0.0.2:9100": 0.6,
+	})
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	state := framework.NewCycleState()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	nodes := newTestNodeInfos("10.0.0.1", "10.0.0.2")
+
+	if status := d.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+		t.Fatalf("PreScore失败: %v", status)
+	}
+
+	// 每个资源维度只发一次批量查询
+	if len(prom.queries) != len(batchQueryTemplates) {
+		t.Errorf("查询次数 = %d, 期望每维度一次共 %d 次", len(prom.queries), len(batchQueryTemplates))
+	}
+
+	// Score直接消费CycleState中的预取结果，不再发起新查询
+	queriesBefore := len(prom.queries)
+	score1, status := d.Score(context.Background(), state, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	score2, status := d.Score(context.Background(), state, pod, "node-2")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if len(prom.queries) != queriesBefore {
+		t.Errorf("Score阶段发起了 %d 次额外查询, 期望 0", len(prom.queries)-queriesBefore)
+	}
+	if score1 != 80 { // 1 - 0.2 (cpu)
+		t.Errorf("node-1 score = %d, 期望 80", score1)
+	}
+	if score2 != 40 { // 1 - 0.6 (cpu)
+		t.Errorf("node-2 score = %d, 期望 40", score2)
+	}
+}
+
+// 验证批量查询失败时PreScore不阻塞调度，Score回退到缓存/中性分
+func TestPreScoreBatchFallback(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		promClient: &fakePromAPI{}, // 所有查询都失败
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	state := framework.NewCycleState()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	nodes := newTestNodeInfos("10.0.0.1")
+
+	if status := d.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+		t.Fatalf("批量失败时PreScore不应报错: %v", status)
+	}
+
+	// 回退路径：缓存有数据用缓存，否则中性分
+	d.cache.Set("node-1", &NodeUsage{CPU: 0.25})
+	score, status := d.Score(context.Background(), state, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 75 {
+		t.Errorf("score = %d, 期望回退缓存路径得 75", score)
+	}
+}
+
+// 基准：批量预取路径下对大量候选节点的完整PreScore+Score评分
+func BenchmarkPreScoreBatch(b *testing.B) {
+	const nodeCount = 500
+	instances := make(map[string]float64, nodeCount)
+	ips := make([]string, 0, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		ip := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		ips = append(ips, ip)
+		instances[ip+":9100"] = 0.5
+	}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+		}},
+		promClient: newBatchPromAPI(instances),
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bench-pod"}}
+	nodes := newTestNodeInfos(ips...)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		state := framework.NewCycleState()
+		if status := d.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+			b.Fatalf("PreScore失败: %v", status)
+		}
+		for _, nodeInfo := range nodes {
+			if _, status := d.Score(context.Background(), state, pod, nodeInfo.Node().Name); !status.IsSuccess() {
+				b.Fatalf("Score失败: %v", status)
+			}
+		}
+	}
+}
+
+// shapePromAPI 返回固定结果与警告的客户端桩（用于测试各种结果形态）
+type shapePromAPI struct {
+	promv1.API
+	result   model.Value
+	warnings promv1.Warnings
+	calls    int
+}
+
+func (f *shapePromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.calls++
+	return f.result, f.warnings, nil
+}
+
+// 验证各种结果形态的处理：空向量哨兵、类型错误、带警告的正常结果
+func TestQueryPrometheusResultShapes(t *testing.T) {
+	// 空向量：返回可识别的哨兵错误，且不触发重试
+	prom := &shapePromAPI{result: model.Vector{}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			QueryMaxAttempts:    3,
+			QueryRetryBaseDelay: metav1.Duration{Duration: time.Millisecond},
+		}},
+		promClient: prom,
+	}
+	_, err := d.queryPrometheus(context.Background(), "up")
+	if !errors.Is(err, errEmptyResult) {
+		t.Errorf("err = %v, 期望errEmptyResult哨兵", err)
+	}
+	if prom.calls != 1 {
+		t.Errorf("查询次数 = %d, 空结果不应重试", prom.calls)
+	}
+
+	// 类型错误（矩阵）：硬错误，不是空结果哨兵
+	d.promClient = &shapePromAPI{result: model.Matrix{}}
+	_, err = d.queryPrometheus(context.Background(), "up")
+	if err == nil || errors.Is(err, errEmptyResult) {
+		t.Errorf("err = %v, 期望非哨兵的硬错误", err)
+	}
+
+	// 带警告的正常结果：取值不受影响
+	d.promClient = &shapePromAPI{
+		result:   model.Vector{&model.Sample{Value: 0.3}},
+		warnings: promv1.Warnings{"partial data"},
+	}
+	value, err := d.queryPrometheus(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("带警告的查询不应失败: %v", err)
+	}
+	if value != 0.3 {
+		t.Errorf("value = %v, 期望 0.3", value)
+	}
+}
+
+// 验证降级值可配置覆盖，未配置降级值的维度被剔除并重归一化
+func TestConfigurableFallbackUsage(t *testing.T) {
+	baseValues := map[string]float64{
+		"node_cpu_seconds_total":            0.5,
+		"node_memory_MemTotal_bytes":        0.4,
+		"node_network_receive_bytes_total":  0.1,
+		"node_network_transmit_bytes_total": 0.1,
+		// 故意缺少磁盘指标
+	}
+
+	// 配置覆盖：磁盘降级值设为1.0，强力惩罚指标缺失的节点
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			FallbackUsage: map[string]float64{"diskio": 1.0},
+		}},
+		promClient: &fakePromAPI{values: baseValues},
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if usage.DiskIO != 1.0 {
+		t.Errorf("DiskIO = %v, 期望配置的降级值 1.0", usage.DiskIO)
+	}
+
+	// 无降级值的维度（自定义指标）失败：剔除后评分重归一化
+	d = &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 0.5, "iops": 0.5},
+			QueryTemplates: map[string]string{
+				"iops": `my_iops_saturation{instance=~"{instance}(:.*)?"}`,
+			},
+		}},
+		promClient: &fakePromAPI{values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+			// 故意缺少iops指标
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	usage, err = d.queryNodeUsage(context.Background(), "10.0.0.2", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	d.cache.Set("node-1", usage)
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	// iops被剔除后重归一化：0.5*(1-0.5)/0.5 = 0.5，而非未归一化的25分
+	if score != 50 {
+		t.Errorf("score = %d, 期望重归一化后的 50", score)
+	}
+}
+
+// slowPromAPI 阻塞到ctx结束才返回的客户端桩（模拟Prometheus挂起）
+type slowPromAPI struct {
+	promv1.API
+}
+
+func (f *slowPromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+// 验证查询超时：拉取及时中止并计数，Score对该节点退回中性分
+func TestQueryTimeout(t *testing.T) {
+	registerMetrics()
+	timeoutsBefore := counterValue(t, queryTimeouts)
+
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			QueryTimeout:   metav1.Duration{Duration: 10 * time.Millisecond},
+		}},
+		promClient: &slowPromAPI{},
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	start := time.Now()
+	_, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err == nil {
+		t.Fatal("期望超时报错")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("超时后未及时返回")
+	}
+	if got := counterValue(t, queryTimeouts); got != timeoutsBefore+1 {
+		t.Errorf("prometheus_query_timeouts_total = %v, 期望 %v", got, timeoutsBefore+1)
+	}
+
+	// 超时未回填缓存：Score走缓存未命中路径返回中性分
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("score = %d, 期望中性分 %d", score, neutralScore)
+	}
+}
+
+// selectiveSlowPromAPI 只延迟匹配子串的查询，其余走内嵌桩立即返回
+// delay为0时匹配的查询一直阻塞到ctx结束（模拟单条重查询挂起）
+type selectiveSlowPromAPI struct {
+	*fakePromAPI
+	slowSubstring string
+	delay         time.Duration
+}
+
+func (f *selectiveSlowPromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	if strings.Contains(query, f.slowSubstring) {
+		if f.delay <= 0 {
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		}
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	return f.fakePromAPI.Query(ctx, query, ts, opts...)
+}
+
+// 验证按维度超时：慢查询只拖垮自己的维度，且可单独获得更长时限
+func TestResourceQueryTimeout(t *testing.T) {
+	registerMetrics()
+	values := map[string]float64{
+		"node_cpu_seconds_total":            0.5,
+		"node_memory_MemTotal_bytes":        0.4,
+		"node_disk_io_time_seconds_total":   0.35,
+		"node_network_receive_bytes_total":  0.1,
+		"node_network_transmit_bytes_total": 0.1,
+	}
+
+	// 磁盘查询挂起 + 磁盘维度短时限：仅diskio走降级，其余维度正常
+	timeoutsBefore := counterValue(t, queryTimeouts)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			ResourceQueryTimeouts: map[string]metav1.Duration{
+				"diskio": {Duration: 10 * time.Millisecond},
+			},
+		}},
+		promClient: &selectiveSlowPromAPI{
+			fakePromAPI:   &fakePromAPI{values: values},
+			slowSubstring: "node_disk_io_time",
+		},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	start := time.Now()
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("单维度超时不应中止整轮: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("单维度超时后未及时返回")
+	}
+	if usage.CPU != 0.5 {
+		t.Errorf("cpu = %v, 期望 0.5（不受磁盘超时影响）", usage.CPU)
+	}
+	if usage.DiskIO != fallbackUsageValues["diskio"] {
+		t.Errorf("diskio = %v, 期望降级值 %v", usage.DiskIO, fallbackUsageValues["diskio"])
+	}
+	if got := counterValue(t, queryTimeouts); got != timeoutsBefore+1 {
+		t.Errorf("prometheus_query_timeouts_total = %v, 期望 %v", got, timeoutsBefore+1)
+	}
+
+	// 磁盘维度单独给比其他维度更长的时限：慢查询有足够余量成功返回
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+		ResourceQueryTimeouts: map[string]metav1.Duration{
+			"cpu":    {Duration: 100 * time.Millisecond},
+			"memory": {Duration: 100 * time.Millisecond},
+			"diskio": {Duration: 2 * time.Second},
+		},
+	}}
+	d.promClient = &selectiveSlowPromAPI{
+		fakePromAPI:   &fakePromAPI{values: values},
+		slowSubstring: "node_disk_io_time",
+		delay:         30 * time.Millisecond,
+	}
+	usage, err = d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if usage.DiskIO != 0.35 {
+		t.Errorf("diskio = %v, 期望更长时限下取回真实值 0.35", usage.DiskIO)
+	}
+}
+
+// 验证按维度超时配置的校验：须为正且不超过整轮预算
+func TestResourceQueryTimeoutValidation(t *testing.T) {
+	args := &DynamicWeightArgs{ResourceQueryTimeouts: map[string]metav1.Duration{
+		"diskio": {Duration: -time.Second},
+	}}
+	if err := args.Validate(); err == nil {
+		t.Error("非正的维度超时应校验失败")
+	}
+	args = &DynamicWeightArgs{
+		QueryTimeout: metav1.Duration{Duration: time.Second},
+		ResourceQueryTimeouts: map[string]metav1.Duration{
+			"diskio": {Duration: 2 * time.Second},
+		},
+	}
+	if err := args.Validate(); err == nil {
+		t.Error("超过整轮预算的维度超时应校验失败")
+	}
+	args = &DynamicWeightArgs{
+		QueryTimeout: metav1.Duration{Duration: 5 * time.Second},
+		ResourceQueryTimeouts: map[string]metav1.Duration{
+			"diskio": {Duration: 3 * time.Second},
+			"cpu":    {Duration: 500 * time.Millisecond},
+		},
+	}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法配置不应报错: %v", err)
+	}
+}
+
+// rangePromAPI 记录区间查询并返回固定矩阵的客户端桩
+type rangePromAPI struct {
+	promv1.API
+	matrix     model.Matrix
+	rangeCalls int
+	queryCalls int
+}
+
+func (f *rangePromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.queryCalls++
+	return model.Vector{&model.Sample{Value: 0.9}}, nil, nil
+}
+
+func (f *rangePromAPI) QueryRange(ctx context.Context, query string, r promv1.Range, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.rangeCalls++
+	return f.matrix, nil, nil
+}
+
+// 验证平滑模式：区间查询对采样点求平均，默认仍走瞬时查询
+func TestQueryPrometheusSmoothing(t *testing.T) {
+	matrix := model.Matrix{&model.SampleStream{
+		Values: []model.SamplePair{{Value: 0.25}, {Value: 0.5}, {Value: 0.75}},
+	}}
+
+	// 启用平滑：走区间查询并取均值
+	prom := &rangePromAPI{matrix: matrix}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{SmoothingEnabled: true}},
+		promClient:   prom,
+	}
+	value, err := d.queryPrometheus(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("平滑查询失败: %v", err)
+	}
+	if value != 0.5 { // (0.25+0.5+0.75)/3
+		t.Errorf("value = %v, 期望采样均值 0.5", value)
+	}
+	if prom.rangeCalls != 1 || prom.queryCalls != 0 {
+		t.Errorf("rangeCalls=%d queryCalls=%d, 期望只走区间查询", prom.rangeCalls, prom.queryCalls)
+	}
+
+	// 空矩阵：返回空结果哨兵
+	d.promClient = &rangePromAPI{matrix: model.Matrix{}}
+	if _, err := d.queryPrometheus(context.Background(), "up"); !errors.Is(err, errEmptyResult) {
+		t.Errorf("err = %v, 期望errEmptyResult哨兵", err)
+	}
+
+	// 默认关闭：保持瞬时查询路径
+	prom = &rangePromAPI{matrix: matrix}
+	d = &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient:   prom,
+	}
+	value, err = d.queryPrometheus(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("瞬时查询失败: %v", err)
+	}
+	if value != 0.9 || prom.queryCalls != 1 || prom.rangeCalls != 0 {
+		t.Errorf("value=%v queryCalls=%d rangeCalls=%d, 期望默认走瞬时查询", value, prom.queryCalls, prom.rangeCalls)
+	}
+}
+
+// flakyPromAPI 前failures次查询返回错误、之后成功的客户端桩
+type flakyPromAPI struct {
+	promv1.API
+	failures int
+	value    float64
+	calls    int
+}
+
+func (f *flakyPromAPI) Query(ctx context.Context, query string, ts time.Time, opts ...promv1.Option) (model.Value, promv1.Warnings, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, nil, fmt.Errorf("transient error %d", f.calls)
+	}
+	return model.Vector{&model.Sample{Value: model.SampleValue(f.value)}}, nil, nil
+}
+
+// 验证查询重试：瞬时失败在退避后重试，超过次数上限才报错
+func TestQueryPrometheusRetry(t *testing.T) {
+	// 失败两次后成功：3次尝试内拿到结果
+	prom := &flakyPromAPI{failures: 2, value: 0.4}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			QueryMaxAttempts:    3,
+			QueryRetryBaseDelay: metav1.Duration{Duration: time.Millisecond},
+		}},
+		promClient: prom,
+	}
+	value, err := d.queryPrometheus(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("重试后仍失败: %v", err)
+	}
+	if value != 0.4 {
+		t.Errorf("value = %v, 期望 0.4", value)
+	}
+	if prom.calls != 3 {
+		t.Errorf("查询次数 = %d, 期望 3", prom.calls)
+	}
+
+	// 次数上限内未成功：只报最终失败
+	prom = &flakyPromAPI{failures: 5, value: 0.4}
+	d.promClient = prom
+	if _, err := d.queryPrometheus(context.Background(), "up"); err == nil {
+		t.Error("期望超过重试上限后报错")
+	}
+	if prom.calls != 3 {
+		t.Errorf("查询次数 = %d, 期望恰好尝试 3 次", prom.calls)
+	}
+
+	// 未配置重试时保持单次尝试
+	prom = &flakyPromAPI{failures: 1, value: 0.4}
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{}}
+	d.promClient = prom
+	if _, err := d.queryPrometheus(context.Background(), "up"); err == nil {
+		t.Error("期望单次尝试失败直接报错")
+	}
+	if prom.calls != 1 {
+		t.Errorf("查询次数 = %d, 期望 1", prom.calls)
+	}
+
+	// 退避等待尊重ctx取消
+	prom = &flakyPromAPI{failures: 5, value: 0.4}
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		QueryMaxAttempts:    5,
+		QueryRetryBaseDelay: metav1.Duration{Duration: time.Minute},
+	}}
+	d.promClient = prom
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := d.queryPrometheus(ctx, "up"); err == nil {
+		t.Error("期望ctx超时中止重试")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("ctx超时后未及时返回")
+	}
+}
+
+// fakeRecorder 记录事件reason的EventRecorder桩
+type fakeRecorder struct {
+	reasons []string
+}
+
+func (f *fakeRecorder) Eventf(regarding runtime.Object, related runtime.Object, eventtype, reason, action, note string, args ...interface{}) {
+	f.reasons = append(f.reasons, reason)
+}
+
+// 验证指标降级/恢复时按状态翻转发事件（每节点限流）
+func TestMetricFallbackEvents(t *testing.T) {
+	values := map[string]float64{
+		"node_cpu_seconds_total":            0.5,
+		"node_memory_MemTotal_bytes":        0.4,
+		"node_network_receive_bytes_total":  0.1,
+		"node_network_transmit_bytes_total": 0.1,
+		// 故意缺少磁盘指标，触发降级
+	}
+	prom := &fakePromAPI{values: values}
+	recorder := &fakeRecorder{}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	d := &DynamicWeight{
+		promClient:    prom,
+		cache:         NewNodeUsageCache(5*time.Minute, 0),
+		recorder:      recorder,
+		degradedNodes: make(map[string]bool),
+	}
+
+	// 首次降级：发一条MetricFallback
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", node); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if len(recorder.reasons) != 1 || recorder.reasons[0] != "MetricFallback" {
+		t.Fatalf("events = %v, 期望仅一条MetricFallback", recorder.reasons)
+	}
+
+	// 持续降级：不再重复发事件
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", node); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if len(recorder.reasons) != 1 {
+		t.Fatalf("events = %v, 持续降级不应重复发事件", recorder.reasons)
+	}
+
+	// 指标恢复：发一条MetricRecovered并清除降级状态
+	prom.values["node_disk_io_time_seconds_total"] = 0.3
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", node); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if len(recorder.reasons) != 2 || recorder.reasons[1] != "MetricRecovered" {
+		t.Fatalf("events = %v, 期望追加一条MetricRecovered", recorder.reasons)
+	}
+
+	// 恢复后保持正常：无新事件
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", node); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if len(recorder.reasons) != 2 {
+		t.Fatalf("events = %v, 恢复后不应再发事件", recorder.reasons)
+	}
+}
+
+// fakeNodeLister 返回固定节点列表的Lister桩
+type fakeNodeLister struct {
+	nodes []*v1.Node
+}
+
+func (f *fakeNodeLister) List(selector labels.Selector) ([]*v1.Node, error) {
+	return f.nodes, nil
+}
+
+func (f *fakeNodeLister) Get(name string) (*v1.Node, error) {
+	for _, n := range f.nodes {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("node %s not found", name)
+}
+
+// fakeHandle 仅提供ClientSet的调度器句柄桩
+type fakeHandle struct {
+	framework.Handle
+	clientset    kubernetes.Interface
+	sharedLister framework.SharedLister
+}
+
+func (f *fakeHandle) ClientSet() kubernetes.Interface {
+	return f.clientset
+}
+
+func (f *fakeHandle) SnapshotSharedLister() framework.SharedLister {
+	return f.sharedLister
+}
+
+// fakeSharedLister 提供固定NodeInfo集合的调度快照桩
+type fakeSharedLister struct {
+	nodes map[string]*framework.NodeInfo
+}
+
+func (f *fakeSharedLister) NodeInfos() framework.NodeInfoLister       { return f }
+func (f *fakeSharedLister) StorageInfos() framework.StorageInfoLister { return nil }
+
+func (f *fakeSharedLister) List() ([]*framework.NodeInfo, error) {
+	infos := make([]*framework.NodeInfo, 0, len(f.nodes))
+	for _, info := range f.nodes {
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (f *fakeSharedLister) HavePodsWithAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeSharedLister) HavePodsWithRequiredAntiAffinityList() ([]*framework.NodeInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeSharedLister) Get(nodeName string) (*framework.NodeInfo, error) {
+	info, ok := f.nodes[nodeName]
+	if !ok {
+		return nil, fmt.Errorf("node %q not found", nodeName)
+	}
+	return info, nil
+}
+
+// counterValue 读取计数器当前值
+func counterValue(t *testing.T, c *metrics.Counter) float64 {
+	t.Helper()
+	v, err := testutil.GetCounterMetricValue(c)
+	if err != nil {
+		t.Fatalf("读取计数器失败: %v", err)
+	}
+	return v
+}
+
+// 验证缓存命中/未命中计数随读写变化
+func TestCacheHitMissCounters(t *testing.T) {
+	// 指标注册前Inc为空操作，先确保已注册
+	registerMetrics()
+
+	hitsBefore := counterValue(t, cacheHits)
+	missesBefore := counterValue(t, cacheMisses)
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	d := &DynamicWeight{
+		handle: &fakeHandle{clientset: clientsetfake.NewSimpleClientset(node)},
+		promClient: &fakePromAPI{
+			values: map[string]float64{
+				"node_cpu_seconds_total":            0.5,
+				"node_memory_MemTotal_bytes":        0.4,
+				"node_disk_io_time_seconds_total":   0.3,
+				"node_network_receive_bytes_total":  0.1,
+				"node_network_transmit_bytes_total": 0.1,
+			},
+		},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	// 第一次读取：缓存为空，计一次未命中
+	if _, err := d.getRealNodeUsage(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	// 第二次读取：上一步已回填缓存，计一次命中
+	if _, err := d.getRealNodeUsage(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+
+	if got := counterValue(t, cacheMisses); got != missesBefore+1 {
+		t.Errorf("cache_misses_total = %v, 期望 %v", got, missesBefore+1)
+	}
+	if got := counterValue(t, cacheHits); got != hitsBefore+1 {
+		t.Errorf("cache_hits_total = %v, 期望 %v", got, hitsBefore+1)
+	}
+}
+
+// 验证linear陈旧度衰减：缓存条目越老，有效使用率越接近中性值0.5
+func TestStalenessDecayLinear(t *testing.T) {
+	ttl := 10 * time.Minute
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			StalenessDecay: stalenessDecayLinear,
+		}},
+		cache: NewNodeUsageCache(ttl, 0),
+	}
+	usage := &NodeUsage{CPU: 0.9, Metrics: map[string]float64{"cpu": 0.9}}
+	d.cache.Set("node-1", usage)
+
+	// 刚写入：年龄≈0，衰减不可见
+	got, err := d.getRealNodeUsage(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	if v, _ := got.Get("cpu"); v < 0.89 || v > 0.9 {
+		t.Errorf("新鲜条目cpu = %v, 期望≈0.9", v)
+	}
+
+	// 条目年龄达到TTL的一半：线性混合到 0.9*0.5 + 0.5*0.5 = 0.7
+	aged := time.Now().Add(-ttl / 2)
+	usage.Timestamp = aged
+	usage.ResourceTimestamps["cpu"] = aged
+	got, err = d.getRealNodeUsage(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	halfAged, _ := got.Get("cpu")
+	if halfAged < 0.69 || halfAged > 0.71 {
+		t.Errorf("半衰期条目cpu = %v, 期望≈0.7", halfAged)
+	}
+
+	// 更老的条目应更接近中性值
+	older := time.Now().Add(-ttl * 9 / 10)
+	usage.Timestamp = older
+	usage.ResourceTimestamps["cpu"] = older
+	got, err = d.getRealNodeUsage(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	nearlyStale, _ := got.Get("cpu")
+	if nearlyStale >= halfAged || nearlyStale < 0.5 {
+		t.Errorf("近过期条目cpu = %v, 期望小于 %v 且不低于0.5", nearlyStale, halfAged)
+	}
+
+	// 衰减应作用于副本，缓存内原值保持不变
+	if usage.Metrics["cpu"] != 0.9 {
+		t.Errorf("缓存原值被修改为 %v, 期望保持0.9", usage.Metrics["cpu"])
+	}
+}
+
+// 验证陈旧度衰减作用于Score的读缓存路径：条目越老得分越接近中性分
+func TestScoreAppliesStalenessDecay(t *testing.T) {
+	ttl := 10 * time.Minute
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			StalenessDecay: stalenessDecayLinear,
+		}},
+		cache: NewNodeUsageCache(ttl, 0),
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	usage := &NodeUsage{CPU: 0.9, Metrics: map[string]float64{"cpu": 0.9}}
+	d.cache.Set("node-1", usage)
+
+	// 刚写入：年龄≈0，得分即原始使用率对应的 (1-0.9)*100 = 10
+	fresh, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status.AsError())
+	}
+	if fresh < 9 || fresh > 11 {
+		t.Errorf("新鲜条目得分 = %d, 期望≈10", fresh)
+	}
+
+	// 条目年龄达到TTL的一半：使用率混合到0.7，得分≈30
+	aged := time.Now().Add(-ttl / 2)
+	usage.Timestamp = aged
+	usage.ResourceTimestamps["cpu"] = aged
+	halfAged, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status.AsError())
+	}
+	if halfAged < 29 || halfAged > 31 {
+		t.Errorf("半衰期条目得分 = %d, 期望≈30", halfAged)
+	}
+
+	// 更老的条目进一步靠近中性分50（但未过期，仍可评分）
+	older := time.Now().Add(-ttl * 9 / 10)
+	usage.Timestamp = older
+	usage.ResourceTimestamps["cpu"] = older
+	nearlyStale, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status.AsError())
+	}
+	if nearlyStale <= halfAged || nearlyStale > 50 {
+		t.Errorf("近过期条目得分 = %d, 期望大于 %d 且不超过中性分50", nearlyStale, halfAged)
+	}
+}
+
+// 验证默认（未配置）时不做衰减，保持既有行为
+func TestStalenessDecayDefaultOff(t *testing.T) {
+	ttl := 10 * time.Minute
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		cache:        NewNodeUsageCache(ttl, 0),
+	}
+	usage := &NodeUsage{CPU: 0.9, Metrics: map[string]float64{"cpu": 0.9}}
+	d.cache.Set("node-1", usage)
+	aged := time.Now().Add(-ttl / 2)
+	usage.Timestamp = aged
+	usage.ResourceTimestamps["cpu"] = aged
+
+	got, err := d.getRealNodeUsage(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	if v, _ := got.Get("cpu"); v != 0.9 {
+		t.Errorf("未配置衰减时cpu = %v, 期望原值0.9", v)
+	}
+}
+
+// 验证缓存未命中时Score返回中性分而不是阻塞查询
+func TestScoreNeutralOnCacheMiss(t *testing.T) {
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	score, status := d.Score(context.Background(), nil, pod, "unknown-node")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("score = %d, 期望中性分 %d", score, neutralScore)
+	}
+}
+
+// 验证后台刷新通过Lister为所有节点预取指标
+func TestRefreshAllNodes(t *testing.T) {
+	newNode := func(name, ip string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: ip},
+			}},
+		}
+	}
+	d := &DynamicWeight{
+		promClient: &fakePromAPI{
+			values: map[string]float64{
+				"node_cpu_seconds_total":            0.5,
+				"node_memory_MemTotal_bytes":        0.4,
+				"node_disk_io_time_seconds_total":   0.3,
+				"node_network_receive_bytes_total":  0.1,
+				"node_network_transmit_bytes_total": 0.1,
+			},
+		},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: &fakeNodeLister{nodes: []*v1.Node{
+			newNode("node-1", "10.0.0.1"),
+			newNode("node-2", "10.0.0.2"),
+		}},
+	}
+
+	d.refreshAllNodes(context.Background())
+
+	for _, name := range []string{"node-1", "node-2"} {
+		if d.cache.Get(name) == nil {
+			t.Errorf("节点%s的缓存未被刷新", name)
+		}
+	}
+}
+
+// 验证按维度过期的自然形成与增量刷新：查询失败被降级值顶替的维度
+// 立即标记过期，下一轮只重查该维度；各维度均采样成功的节点到刷新
+// 周期仍整条重查
+func TestRefreshAllNodesIncremental(t *testing.T) {
+	newNode := func(name, ip string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: ip},
+			}},
+		}
+	}
+	// diskio的自定义模板只对node-2有值：node-1的diskio查询失败，
+	// 走降级默认值
+	prom := &fakePromAPI{values: map[string]float64{
+		"node_cpu_seconds_total":             0.5,
+		"node_memory_MemTotal_bytes":         0.4,
+		"node_network_receive_bytes_total":   0.1,
+		"node_network_transmit_bytes_total":  0.1,
+		`custom_diskio{instance="10.0.0.2"}`: 0.3,
+	}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			// 自定义模板跳过批量预热，逐节点兜底成为唯一刷新入口
+			QueryTemplates: map[string]string{
+				"diskio": `custom_diskio{instance="{instance}"}`,
+			},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: &fakeNodeLister{nodes: []*v1.Node{
+			newNode("node-1", "10.0.0.1"),
+			newNode("node-2", "10.0.0.2"),
+		}},
+	}
+
+	// 第一轮：node-1的diskio被降级默认值顶替并标记过期；
+	// node-2各维度均采样成功
+	d.refreshAllNodes(context.Background())
+	if usage, stale := d.cache.GetWithStale("node-1"); usage == nil || len(stale) != 1 || stale[0] != "diskio" {
+		t.Fatalf("降级维度应被标记过期, stale = %v", stale)
+	}
+	if _, stale := d.cache.GetWithStale("node-2"); len(stale) != 0 {
+		t.Fatalf("node-2各维度均采样成功, 不应有过期维度: %v", stale)
+	}
+
+	// 第二轮：diskio恢复可查
+	prom.values[`custom_diskio{instance="10.0.0.1"}`] = 0.35
+	prom.queries = nil
+	d.refreshAllNodes(context.Background())
+
+	// node-1只应重查diskio；node-2虽各维度未过期，但上轮写入早于本轮
+	// 基准时刻，应整条重查（刷新节奏与增量路径引入前一致）
+	var node1, node2 []string
+	for _, q := range prom.queries {
+		if strings.Contains(q, "10.0.0.1") {
+			node1 = append(node1, q)
+		}
+		if strings.Contains(q, "10.0.0.2") {
+			node2 = append(node2, q)
+		}
+	}
+	if len(node1) != 1 || !strings.Contains(node1[0], "custom_diskio") {
+		t.Errorf("node-1应只重查diskio, 实际查询: %v", node1)
+	}
+	fullRefresh := false
+	for _, q := range node2 {
+		if strings.Contains(q, "node_memory") {
+			fullRefresh = true
+		}
+	}
+	if len(node2) < 2 || !fullRefresh {
+		t.Errorf("node-2到刷新周期应整条重查, 实际查询: %v", node2)
+	}
+
+	got := d.cache.Get("node-1")
+	if got == nil {
+		t.Fatal("增量刷新后条目不应缺失")
+	}
+	if got.DiskIO != 0.35 {
+		t.Errorf("diskio = %v, 期望重查后的 0.35", got.DiskIO)
+	}
+	if got.Metrics["cpu"] != 0.5 {
+		t.Errorf("cpu = %v, 新鲜维度应沿用缓存值 0.5", got.Metrics["cpu"])
+	}
+	if got2 := d.cache.Get("node-2"); got2 == nil || got2.DiskIO != 0.3 {
+		t.Errorf("整条重查后node-2条目 = %+v, 期望diskio 0.3", got2)
+	}
+}
+
+// 验证配置InstanceMatcher时批量路径整体停用：批量模板按instance
+// 标签硬匹配，套不进自定义的标签键，照发只会全量空匹配后回退
+func TestBatchSkippedWithInstanceMatcher(t *testing.T) {
+	prom := &fakePromAPI{values: map[string]float64{
+		"node_cpu_seconds_total":            0.5,
+		"node_memory_MemTotal_bytes":        0.4,
+		"node_disk_io_time_seconds_total":   0.3,
+		"node_network_receive_bytes_total":  0.1,
+		"node_network_transmit_bytes_total": 0.1,
+	}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			InstanceMatcher: `node="{value}"`,
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: &fakeNodeLister{nodes: []*v1.Node{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			}},
+		}}},
+	}
+
+	// PreScore不发批量查询也不写预取状态，Score走逐节点缓存路径
+	state := framework.NewCycleState()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	if status := d.PreScore(context.Background(), state, pod, newTestNodeInfos("10.0.0.1")); !status.IsSuccess() {
+		t.Fatalf("PreScore失败: %v", status)
+	}
+	if len(prom.queries) != 0 {
+		t.Fatalf("配置InstanceMatcher时PreScore不应发起批量查询: %v", prom.queries)
+	}
+	if _, err := state.Read(preScoreStateKey); err == nil {
+		t.Error("批量路径停用时不应写入预取状态")
+	}
+
+	// 后台刷新跳过批量预热，逐节点查询按自定义表达式渲染
+	d.refreshAllNodes(context.Background())
+	if len(prom.queries) == 0 {
+		t.Fatal("逐节点刷新未发出查询")
+	}
+	for _, q := range prom.queries {
+		if strings.Contains(q, `instance=~"(`) {
+			t.Errorf("不应发出按instance分组的批量查询: %s", q)
+		}
+		if !strings.Contains(q, `node="10.0.0.1"`) {
+			t.Errorf("逐节点查询应按自定义匹配表达式渲染: %s", q)
+		}
+	}
+	if d.cache.Get("node-1") == nil {
+		t.Error("逐节点刷新后缓存应有条目")
+	}
+}
+
+// 验证rate查询窗口可配置，默认5m，且渲染后的查询包含配置的窗口
+func TestRateWindow(t *testing.T) {
+	newProm := func() *fakePromAPI {
+		return &fakePromAPI{
+			values: map[string]float64{
+				"node_cpu_seconds_total":            0.5,
+				"node_memory_MemTotal_bytes":        0.4,
+				"node_disk_io_time_seconds_total":   0.3,
+				"node_network_receive_bytes_total":  0.1,
+				"node_network_transmit_bytes_total": 0.1,
+			},
+		}
+	}
+
+	// 默认窗口5m
+	prom := newProm()
+	d := &DynamicWeight{promClient: prom, cache: NewNodeUsageCache(5*time.Minute, 0)}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_cpu_seconds_total", "[5m]")
+	assertQueryContains(t, prom.queries, "node_disk_io_time_seconds_total", "[5m]")
+
+	// 配置的窗口被代入所有rate查询
+	prom = newProm()
+	d = &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			RateWindow: metav1.Duration{Duration: time.Minute},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_cpu_seconds_total", "[1m]")
+	assertQueryContains(t, prom.queries, "node_network_receive_bytes_total", "[1m]")
+	for _, q := range prom.queries {
+		if strings.Contains(q, "[5m]") {
+			t.Errorf("查询仍使用默认窗口: %s", q)
+		}
+	}
+}
+
+// 验证短于抓取间隔下限的窗口被拒绝
+func TestRateWindowValidation(t *testing.T) {
+	args := &DynamicWeightArgs{RateWindow: metav1.Duration{Duration: 10 * time.Second}}
+	if err := args.Validate(); err == nil {
+		t.Error("期望10s窗口校验失败，实际通过")
+	}
+	args = &DynamicWeightArgs{RateWindow: metav1.Duration{Duration: time.Minute}}
+	if err := args.Validate(); err != nil {
+		t.Errorf("期望1m窗口校验通过，实际失败: %v", err)
+	}
+	// 未配置时不校验（使用默认值）
+	if err := (&DynamicWeightArgs{}).Validate(); err != nil {
+		t.Errorf("期望空配置校验通过，实际失败: %v", err)
+	}
+}
+
+// 验证权重表校验：总和须约等于1.0、各项非负，LabelWeights逐表检查
+func TestWeightsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    *DynamicWeightArgs
+		wantErr bool
+	}{
+		{
+			name: "默认权重和为1.0",
+			args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 0.25, "memory": 0.25, "diskio": 0.25, "netio": 0.25},
+			},
+		},
+		{
+			name: "浮点误差范围内通过",
+			args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 0.333, "memory": 0.333, "diskio": 0.333},
+			},
+		},
+		{
+			name: "权重和明显偏离1.0",
+			args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 2.0, "memory": 3.0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "负权重被拒绝",
+			args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 1.5, "memory": -0.5},
+			},
+			wantErr: true,
+		},
+		{
+			name: "标签权重表逐个校验",
+			args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+				LabelWeights: map[string]map[string]float64{
+					"cpu-prefer": {"cpu": 0.7, "memory": 0.1},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.args.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 验证resource-prefer标签支持下划线分隔的多偏好合成
+func TestComposeLabelWeights(t *testing.T) {
+	labelWeights := map[string]map[string]float64{
+		"cpu":   {"cpu": 0.7, "memory": 0.3},
+		"netio": {"netio": 0.9, "cpu": 0.1},
+	}
+
+	// 单个偏好：直接返回对应权重表
+	got := composeLabelWeights("cpu", labelWeights)
+	if got == nil || got["cpu"] != 0.7 {
+		t.Errorf("单偏好结果 = %v, 期望cpu权重表", got)
+	}
+
+	// 多个偏好：逐维度平均
+	got = composeLabelWeights("cpu_netio", labelWeights)
+	if got == nil {
+		t.Fatal("多偏好合成不应返回nil")
+	}
+	almostEqual := func(a, b float64) bool {
+		return a > b-1e-9 && a < b+1e-9
+	}
+	if !almostEqual(got["cpu"], 0.4) { // (0.7+0.1)/2
+		t.Errorf("cpu = %v, 期望 0.4", got["cpu"])
+	}
+	if !almostEqual(got["memory"], 0.15) { // (0.3+0)/2
+		t.Errorf("memory = %v, 期望 0.15", got["memory"])
+	}
+	if !almostEqual(got["netio"], 0.45) { // (0+0.9)/2
+		t.Errorf("netio = %v, 期望 0.45", got["netio"])
+	}
+	var sum float64
+	for _, w := range got {
+		sum += w
+	}
+	if sum < 0.999 || sum > 1.001 {
+		t.Errorf("合成权重之和 = %v, 期望归一化为1.0", sum)
+	}
+
+	// 未知偏好被忽略，已知偏好仍生效
+	got = composeLabelWeights("cpu_unknown", labelWeights)
+	if got == nil || got["cpu"] != 0.7 {
+		t.Errorf("含未知偏好的结果 = %v, 期望仅cpu权重表生效", got)
+	}
+
+	// 全部未匹配：返回nil以回退默认权重
+	if got = composeLabelWeights("unknown", labelWeights); got != nil {
+		t.Errorf("全未匹配时 = %v, 期望nil", got)
+	}
+}
+
+// 验证权重优先级：命名空间权重 > resource-prefer标签权重 > 默认权重
+func TestScoreNamespaceWeights(t *testing.T) {
+	args := &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+		LabelWeights: map[string]map[string]float64{
+			"memory-prefer": {"memory": 1.0},
+		},
+		NamespaceWeights: map[string]map[string]float64{
+			"team-a": {"diskio": 1.0},
+		},
+	}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: args},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", &NodeUsage{CPU: 0.75, Memory: 0.5, DiskIO: 0.25})
+
+	// 命名空间和标签都有覆盖时，命名空间权重优先
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "test-pod",
+		Namespace: "team-a",
+		Labels:    map[string]string{"resource-prefer": "memory-prefer"},
+	}}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 75 { // 1 - 0.25 (diskio)
+		t.Errorf("score = %d, 期望命名空间权重生效得75", score)
+	}
+
+	// 命名空间无覆盖时回退到标签权重
+	pod.Namespace = "team-b"
+	score, status = d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 50 { // 1 - 0.5 (memory)
+		t.Errorf("score = %d, 期望标签权重生效得50", score)
+	}
+
+	// 命名空间与标签都无覆盖时使用默认权重
+	pod.Labels = nil
+	score, status = d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 25 { // 1 - 0.75 (cpu)
+		t.Errorf("score = %d, 期望默认权重生效得25", score)
+	}
+}
+
+// 验证磁盘设备/网卡名可通过配置与节点标签覆盖，默认值保持sdb/eth0
+func TestDeviceAndInterfaceOverride(t *testing.T) {
+	newProm := func() *fakePromAPI {
+		return &fakePromAPI{
+			values: map[string]float64{
+				"node_cpu_seconds_total":            0.5,
+				"node_memory_MemTotal_bytes":        0.4,
+				"node_disk_io_time_seconds_total":   0.3,
+				"node_network_receive_bytes_total":  0.1,
+				"node_network_transmit_bytes_total": 0.1,
+			},
+		}
+	}
+
+	// 默认值：未配置时保持向后兼容
+	prom := newProm()
+	d := &DynamicWeight{promClient: prom, cache: NewNodeUsageCache(5*time.Minute, 0)}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_disk_io_time_seconds_total", `device=~"sdb"`)
+	assertQueryContains(t, prom.queries, "node_network_receive_bytes_total", `device=~"eth0"`)
+
+	// 插件配置覆盖（支持正则）
+	prom = newProm()
+	d = &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DiskDevice:       "nvme.*",
+			NetworkInterface: "ens.*",
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_disk_io_time_seconds_total", `device=~"nvme.*"`)
+	assertQueryContains(t, prom.queries, "node_network_transmit_bytes_total", `device=~"ens.*"`)
+
+	// 节点标签覆盖优先于插件配置
+	prom = newProm()
+	d.promClient = prom
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name: "node-1",
+		Labels: map[string]string{
+			diskDeviceNodeLabel:       "vda",
+			networkInterfaceNodeLabel: "bond0",
+		},
+	}}
+	if _, err := d.queryNodeUsage(context.Background(), "10.0.0.1", node); err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_disk_io_time_seconds_total", `device=~"vda"`)
+	assertQueryContains(t, prom.queries, "node_network_receive_bytes_total", `device=~"bond0"`)
+}
+
+// assertQueryContains 断言指定指标的查询语句中包含期望子串
+func assertQueryContains(t *testing.T, queries []string, metric, want string) {
+	t.Helper()
+	for _, q := range queries {
+		if strings.Contains(q, metric) {
+			if !strings.Contains(q, want) {
+				t.Errorf("指标%s的查询缺少%s: %s", metric, want, q)
+			}
+			return
+		}
+	}
+	t.Errorf("未找到指标%s的查询", metric)
+}
+
+// 验证netrx/nettx可独立加权，netio合计维度保持兼容
+func TestScoreNetworkDimensions(t *testing.T) {
+	usage := &NodeUsage{
+		CPU:       0.5,
+		Memory:    0.5,
+		DiskIO:    0.5,
+		Network:   0.6,
+		NetworkRx: 0.2,
+		NetworkTx: 0.4,
+	}
+
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		want    int64
+	}{
+		{
+			name:    "仅按发送方向加权",
+			weights: map[string]float64{"nettx": 1.0},
+			want:    60, // 1 - 0.4
+		},
+		{
+			name:    "仅按接收方向加权",
+			weights: map[string]float64{"netrx": 1.0},
+			want:    80, // 1 - 0.2
+		},
+		{
+			name:    "合计维度保持兼容",
+			weights: map[string]float64{"netio": 1.0},
+			want:    40, // 1 - 0.6
+		},
+		{
+			name:    "方向混合加权",
+			weights: map[string]float64{"netrx": 0.5, "nettx": 0.5},
+			want:    70, // 0.5*(1-0.2) + 0.5*(1-0.4)
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DynamicWeight{
+				weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+					DefaultWeights: tt.weights,
+				}},
+				cache: NewNodeUsageCache(5*time.Minute, 0),
+			}
+			// 预置缓存，避免测试依赖真实的节点/Prometheus访问
+			d.cache.Set("node-1", usage)
+
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+			score, status := d.Score(context.Background(), nil, pod, "node-1")
+			if !status.IsSuccess() {
+				t.Fatalf("Score失败: %v", status)
+			}
+			if score != tt.want {
+				t.Errorf("score = %d, 期望 %d", score, tt.want)
+			}
+		})
+	}
+}
+
+// 验证只有部分维度过期时仅增量重查过期维度，未过期维度沿用缓存值
+func TestGetRealNodeUsagePartialRefresh(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.15,
+			"node_network_transmit_bytes_total": 0.05,
+			"DCGM_FI_DEV_GPU_UTIL":              0.2,
+		},
+	}
+	d := &DynamicWeight{
+		handle:     &fakeHandle{clientset: clientsetfake.NewSimpleClientset(node)},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	// 首轮整条拉取填充缓存
+	if _, err := d.getRealNodeUsage(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+
+	// 回拨diskio的采集时间使其单独过期，并改变数据源中的磁盘值
+	d.cache.mu.Lock()
+	d.cache.data["node-1"].usage.ResourceTimestamps["diskio"] = time.Now().Add(-10 * time.Minute)
+	d.cache.mu.Unlock()
+	prom.values["node_disk_io_time_seconds_total"] = 0.8
+	prom.queries = nil
+
+	usage, err := d.getRealNodeUsage(context.Background(), "node-1")
+	if err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	if usage.DiskIO != 0.8 {
+		t.Errorf("DiskIO = %v, 期望增量刷新为 0.8", usage.DiskIO)
+	}
+	if usage.CPU != 0.5 {
+		t.Errorf("CPU = %v, 期望沿用缓存值 0.5", usage.CPU)
+	}
+	// 增量刷新只应发起过期维度的查询
+	if len(prom.queries) != 1 || !strings.Contains(prom.queries[0], "node_disk_io_time_seconds_total") {
+		t.Errorf("增量刷新发起的查询 = %v, 期望仅查询diskio", prom.queries)
+	}
+
+	// 刷新后再次读取应命中缓存，不再发起查询
+	prom.queries = nil
+	if _, err := d.getRealNodeUsage(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	if len(prom.queries) != 0 {
+		t.Errorf("命中缓存后仍发起了 %d 次查询", len(prom.queries))
+	}
+}
+
+// 验证Filter按MaxUsage硬性上限过滤过载节点，未配置时放行一切
+func TestFilterMaxUsage(t *testing.T) {
+	cache := NewNodeUsageCache(5*time.Minute, 0)
+	cache.Set("node-1", &NodeUsage{CPU: 0.98, Memory: 0.5})
+	cache.Set("node-2", &NodeUsage{CPU: 0.5, Memory: 0.5})
+
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			MaxUsage: map[string]float64{"cpu": 0.95},
+		}},
+		cache: cache,
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	nodeInfos := newTestNodeInfos("10.0.0.1", "10.0.0.2")
+	state := framework.NewCycleState()
+
+	status := d.Filter(context.Background(), state, pod, nodeInfos[0])
+	if status.Code() != framework.Unschedulable {
+		t.Errorf("过载节点的Filter状态 = %v, 期望 Unschedulable", status)
+	}
+	if status = d.Filter(context.Background(), state, pod, nodeInfos[1]); status != nil {
+		t.Errorf("正常节点的Filter状态 = %v, 期望放行", status)
+	}
+
+	// 缓存未命中的节点放行（不在过滤路径上发起查询）
+	cache.Delete("node-1")
+	if status = d.Filter(context.Background(), state, pod, nodeInfos[0]); status != nil {
+		t.Errorf("缓存未命中时Filter状态 = %v, 期望放行", status)
+	}
+
+	// 未配置MaxUsage时不过滤，行为与引入Filter前一致
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{}}
+	cache.Set("node-1", &NodeUsage{CPU: 0.99})
+	if status = d.Filter(context.Background(), state, pod, nodeInfos[0]); status != nil {
+		t.Errorf("未配置上限时Filter状态 = %v, 期望放行", status)
+	}
+}
+
+// 验证MaxUsage上限的取值区间校验
+func TestMaxUsageValidation(t *testing.T) {
+	valid := &DynamicWeightArgs{MaxUsage: map[string]float64{"cpu": 0.95, "memory": 1.0}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("合法的maxUsage不应报错: %v", err)
+	}
+	for _, limit := range []float64{0, -0.1, 1.5} {
+		args := &DynamicWeightArgs{MaxUsage: map[string]float64{"cpu": limit}}
+		if err := args.Validate(); err == nil {
+			t.Errorf("maxUsage[cpu]=%v 应校验失败", limit)
+		}
+	}
+}
+
+// 验证PreFilter对resource-prefer标签取值的校验：宽松模式发事件，严格模式拒绝
+func TestPreFilterLabelValidation(t *testing.T) {
+	labelWeights := map[string]map[string]float64{
+		"cpu-prefer": {"cpu": 0.7, "memory": 0.3},
+	}
+	newPod := func(prefer string) *v1.Pod {
+		labels := map[string]string{}
+		if prefer != "" {
+			labels["resource-prefer"] = prefer
+		}
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Labels: labels}}
+	}
+
+	// 宽松模式：未识别的取值只发Warning事件并写入诊断，不拒绝
+	recorder := &fakeRecorder{}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{LabelWeights: labelWeights}},
+		recorder:     recorder,
+	}
+	state := framework.NewCycleState()
+	if _, status := d.PreFilter(context.Background(), state, newPod("cpu-prefre")); status != nil {
+		t.Errorf("宽松模式的PreFilter状态 = %v, 期望放行", status)
+	}
+	if len(recorder.reasons) != 1 || recorder.reasons[0] != "UnknownResourcePreference" {
+		t.Errorf("事件 = %v, 期望 [UnknownResourcePreference]", recorder.reasons)
+	}
+	data, err := state.Read(preFilterStateKey)
+	if err != nil {
+		t.Fatalf("读取诊断信息失败: %v", err)
+	}
+	diag := data.(*preFilterState)
+	if len(diag.unrecognized) != 1 || diag.unrecognized[0] != "cpu-prefre" {
+		t.Errorf("诊断信息 = %v, 期望 [cpu-prefre]", diag.unrecognized)
+	}
+
+	// 合法取值与无标签的Pod不发事件、不写诊断
+	recorder.reasons = nil
+	for _, prefer := range []string{"cpu-prefer", ""} {
+		state = framework.NewCycleState()
+		if _, status := d.PreFilter(context.Background(), state, newPod(prefer)); status != nil {
+			t.Errorf("prefer=%q 的PreFilter状态 = %v, 期望放行", prefer, status)
+		}
+		if _, err := state.Read(preFilterStateKey); err == nil {
+			t.Errorf("prefer=%q 不应写入诊断信息", prefer)
+		}
+	}
+	if len(recorder.reasons) != 0 {
+		t.Errorf("合法取值不应发事件: %v", recorder.reasons)
+	}
+
+	// 严格模式：未识别的取值直接拒绝
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		LabelWeights:          labelWeights,
+		StrictLabelValidation: true,
+	}}
+	_, status := d.PreFilter(context.Background(), framework.NewCycleState(), newPod("cpu-prefre"))
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("严格模式的PreFilter状态 = %v, 期望 UnschedulableAndUnresolvable", status)
+	}
+	// 多偏好取值中只要有一个合法部分仍校验未识别部分
+	_, status = d.PreFilter(context.Background(), framework.NewCycleState(), newPod("cpu-prefer_netio"))
+	if status.Code() != framework.UnschedulableAndUnresolvable {
+		t.Errorf("部分未识别时PreFilter状态 = %v, 期望 UnschedulableAndUnresolvable", status)
+	}
+}
+
+// 验证InstanceLabelSource的三种来源与缺少地址时的报错
+func TestNodeInstanceAddress(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: v1.NodeHostName, Address: "worker-1.example.com"},
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		node    *v1.Node
+		want    string
+		wantErr bool
+	}{
+		{name: "默认取内部IP", source: "", node: node, want: "10.0.0.1"},
+		{name: "InternalIP来源", source: "InternalIP", node: node, want: "10.0.0.1"},
+		{name: "Hostname来源", source: "Hostname", node: node, want: "worker-1.example.com"},
+		{name: "NodeName来源", source: "NodeName", node: node, want: "node-1"},
+		{
+			name:    "缺少内部IP时报错",
+			source:  "InternalIP",
+			node:    &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-x"}},
+			wantErr: true,
+		},
+		{
+			name:    "缺少Hostname时报错",
+			source:  "Hostname",
+			node:    &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-x"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DynamicWeight{
+				weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{InstanceLabelSource: tt.source}},
+			}
+			got, err := d.nodeInstanceAddress(tt.node)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("期望报错")
+				}
+				if !strings.Contains(err.Error(), tt.node.Name) {
+					t.Errorf("错误信息 %q 应包含节点名 %s", err, tt.node.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("nodeInstanceAddress失败: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("instance = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 验证地址类型回退链：首选类型缺失时按序尝试其余类型
+func TestAddressTypePreferenceFallback(t *testing.T) {
+	externalOnly := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeExternalIP, Address: "203.0.113.7"},
+		}},
+	}
+
+	// 默认链（InternalIP→Hostname→ExternalIP）：只报外部IP的节点可解析
+	d := &DynamicWeight{weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}}}
+	got, err := d.nodeInstanceAddress(externalOnly)
+	if err != nil {
+		t.Fatalf("只报ExternalIP的节点解析失败: %v", err)
+	}
+	if got != "203.0.113.7" {
+		t.Errorf("instance = %s, 期望回退到外部IP 203.0.113.7", got)
+	}
+
+	// Hostname来源但节点无主机名：沿链回退到内部IP
+	internalOnly := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	d = &DynamicWeight{weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+		InstanceLabelSource: "Hostname",
+	}}}
+	if got, err = d.nodeInstanceAddress(internalOnly); err != nil || got != "10.0.0.1" {
+		t.Errorf("instance = %s (err=%v), 期望回退到内部IP 10.0.0.1", got, err)
+	}
+
+	// 自定义链不含ExternalIP：只报外部IP的节点仍然报错，错误列出已尝试类型
+	d = &DynamicWeight{weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+		AddressTypePreference: []string{"InternalIP", "Hostname"},
+	}}}
+	if _, err = d.nodeInstanceAddress(externalOnly); err == nil {
+		t.Error("链中无可命中的地址类型时应报错")
+	} else if !strings.Contains(err.Error(), "edge-1") {
+		t.Errorf("错误信息应包含节点名: %v", err)
+	}
+
+	// 不受支持的地址类型在配置校验阶段拒绝
+	args := &DynamicWeightArgs{AddressTypePreference: []string{"InternalDNS"}}
+	if err := args.Validate(); err == nil {
+		t.Error("不受支持的地址类型应校验失败")
+	}
+}
+
+// 验证按Hostname来源查询时主机名被替换进查询语句
+func TestQueryUsesHostnameInstance(t *testing.T) {
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		},
+	}
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: v1.NodeHostName, Address: "worker-1"},
+		}},
+	}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{InstanceLabelSource: "Hostname"}},
+		promClient:   prom,
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	if _, err := d.updateNodeUsage(context.Background(), node); err != nil {
+		t.Fatalf("updateNodeUsage失败: %v", err)
+	}
+	assertQueryContains(t, prom.queries, "node_cpu_seconds_total", `worker-1`)
+	for _, q := range prom.queries {
+		if strings.Contains(q, "10.0.0.1") {
+			t.Errorf("查询不应使用内部IP: %s", q)
+		}
+	}
+}
+
+// 验证缓存未命中时节点对象从informer缓存读取，不直连apiserver
+func TestGetRealNodeUsageUsesNodeLister(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+			{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+		}},
+	}
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"node_cpu_seconds_total":            0.5,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		},
+	}
+	cs := clientsetfake.NewSimpleClientset() // API端故意不放节点
+	d := &DynamicWeight{
+		handle:     &fakeHandle{clientset: cs},
+		nodeLister: &fakeNodeLister{nodes: []*v1.Node{node}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	if _, err := d.getRealNodeUsage(context.Background(), "node-1"); err != nil {
+		t.Fatalf("getRealNodeUsage失败: %v", err)
+	}
+	for _, action := range cs.Actions() {
+		if action.Matches("get", "nodes") {
+			t.Error("lister已有节点时不应直连apiserver")
+		}
+	}
+
+	// 地址解析结果被缓存：清空地址后再次解析仍命中缓存
+	node.Status.Addresses = nil
+	if _, err := d.nodeInstanceAddress(node); err != nil {
+		t.Errorf("instance地址应命中缓存: %v", err)
+	}
+	// 节点下线清理后重新解析，此时缺少地址应报错
+	d.forgetNodeInstance("node-1")
+	if _, err := d.nodeInstanceAddress(node); err == nil {
+		t.Error("缓存清理后无地址的节点应解析失败")
+	}
+}
+
+// 验证指标不可用时的兜底策略：默认fail-open返回中性分，fail-closed报错
+func TestScoreFailOpenModes(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+
+	// 默认（未配置FailOpen）：缓存未命中返回中性分，状态Success
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("fail-open模式Score不应报错: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("score = %d, 期望中性分 %d", score, neutralScore)
+	}
+
+	// 中性分可配置
+	custom := &DynamicWeightArgs{NeutralScore: 30}
+	d.weightLoader = &fakeWeightLoader{args: custom}
+	if score, _ = d.Score(context.Background(), nil, pod, "node-1"); score != 30 {
+		t.Errorf("score = %d, 期望配置的中性分 30", score)
+	}
+
+	// fail-closed：显式关闭FailOpen后返回错误状态
+	failOpen := false
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{FailOpen: &failOpen}}
+	if _, status = d.Score(context.Background(), nil, pod, "node-1"); status.IsSuccess() {
+		t.Error("fail-closed模式下指标不可用应返回错误状态")
+	}
+}
+
+// 验证预热宽限期：到期前Score恒为中性分，到期后切换为真实评分
+func TestWarmupPeriod(t *testing.T) {
+	registerMetrics()
+
+	base := time.Now()
+	clock := base
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.4)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			WarmupPeriod:   metav1.Duration{Duration: time.Minute},
+		}},
+		cache:     NewNodeUsageCache(5*time.Minute, 0),
+		timeNow:   func() time.Time { return clock },
+		startedAt: base,
+	}
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+
+	// 预热期内：即使缓存已有数据也返回中性分
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("预热期内score = %d, 期望中性分 %d", score, neutralScore)
+	}
+	if v, err := testutil.GetGaugeMetricValue(warmupComplete); err != nil || v != 0 {
+		t.Errorf("warmup_complete = %v (err=%v), 预热期内期望 0", v, err)
+	}
+
+	// 宽限期过后：按真实使用率评分
+	clock = base.Add(2 * time.Minute)
+	if score, _ = d.Score(context.Background(), nil, pod, "node-1"); score != 60 {
+		t.Errorf("预热结束后score = %d, 期望 usage 0.4 对应的 60", score)
+	}
+	if v, err := testutil.GetGaugeMetricValue(warmupComplete); err != nil || v != 1 {
+		t.Errorf("warmup_complete = %v (err=%v), 预热结束期望 1", v, err)
+	}
+
+	// 未配置预热期（零值）：启动即真实评分
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+	}}
+	clock = base
+	if score, _ = d.Score(context.Background(), nil, pod, "node-1"); score != 60 {
+		t.Errorf("无预热配置score = %d, 期望 60", score)
+	}
+
+	// 负的预热期应校验失败
+	args := &DynamicWeightArgs{WarmupPeriod: metav1.Duration{Duration: -time.Second}}
+	if err := args.Validate(); err == nil {
+		t.Error("负的warmupPeriod应校验失败")
+	}
+}
+
+// 验证所有兜底路径统一使用配置的NeutralScore，而非各自散落的魔数
+func TestNeutralScoreFallbackPaths(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+
+	// 指标不可用（fail-open）
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{NeutralScore: 35}},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+	if score, _ := d.Score(context.Background(), nil, pod, "node-1"); score != 35 {
+		t.Errorf("指标不可用兜底分 = %d, 期望 35", score)
+	}
+
+	// 权重全部指向缺失维度（本轮无任何维度参与评分）
+	usage := &NodeUsage{Metrics: map[string]float64{"cpu": 0.4}}
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"ceph_iops": 1.0},
+		NeutralScore:   35,
+	}}
+	d.cache.Set("node-1", usage)
+	if score, _ := d.Score(context.Background(), nil, pod, "node-1"); score != 35 {
+		t.Errorf("全维度缺失兜底分 = %d, 期望 35", score)
+	}
+
+	// 观察模式：真实得分不参与决策，恒返回中性分
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+		ObserveOnly:    true,
+		NeutralScore:   35,
+	}}
+	if score, _ := d.Score(context.Background(), nil, pod, "node-1"); score != 35 {
+		t.Errorf("观察模式兜底分 = %d, 期望 35", score)
+	}
+
+	// 命中排除选择器的节点
+	excluded := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "node-cp",
+		Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+	}}
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+		ExcludeNodeSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"node-role.kubernetes.io/control-plane": ""},
+		},
+		NeutralScore: 35,
+	}}
+	d.nodeLister = newFakeNodeLister(t, excluded)
+	if score, _ := d.Score(context.Background(), nil, pod, "node-cp"); score != 35 {
+		t.Errorf("排除节点兜底分 = %d, 期望 35", score)
+	}
+}
+
+// 验证各评分曲线对一组使用率的相对排序与曲线特征
+func TestCurveScoreOrdering(t *testing.T) {
+	usages := []float64{0.1, 0.4, 0.7, 0.9}
+	for _, curve := range []string{scoringCurveLinear, scoringCurveExponential, scoringCurveSigmoid} {
+		prev := math.Inf(1)
+		for _, u := range usages {
+			got := curveScore(curve, u)
+			if got >= prev {
+				t.Errorf("曲线 %s: usage=%v 得分 %v 未低于 usage更低时的 %v", curve, u, got, prev)
+			}
+			if got < 0 || got > 1 {
+				t.Errorf("曲线 %s: usage=%v 得分 %v 超出[0,1]", curve, u, got)
+			}
+			prev = got
+		}
+	}
+
+	// 指数曲线在低负载区间的区分度应强于线性
+	linGap := curveScore(scoringCurveLinear, 0.1) - curveScore(scoringCurveLinear, 0.4)
+	expGap := curveScore(scoringCurveExponential, 0.1) - curveScore(scoringCurveExponential, 0.4)
+	if expGap <= linGap {
+		t.Errorf("指数曲线低负载区分度 %v 应大于线性 %v", expGap, linGap)
+	}
+
+	// 未配置时退回线性
+	if got := curveScore("", 0.3); got != 0.7 {
+		t.Errorf("默认曲线得分 = %v, 期望线性的 0.7", got)
+	}
+}
+
+// 验证Score按配置的曲线计算得分
+func TestScoreWithScoringCurve(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.2)
+	usage.setNamed("memory", 0.2)
+
+	for _, curve := range []string{scoringCurveLinear, scoringCurveExponential} {
+		d := &DynamicWeight{
+			weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+				DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+				ScoringCurve:   curve,
+			}},
+			cache: NewNodeUsageCache(5*time.Minute, 0),
+		}
+		d.cache.Set("node-1", usage)
+		score, status := d.Score(context.Background(), nil, pod, "node-1")
+		if !status.IsSuccess() {
+			t.Fatalf("曲线 %s Score失败: %v", curve, status)
+		}
+		want := int64(curveScore(curve, 0.2) * 100)
+		if score != want {
+			t.Errorf("曲线 %s score = %d, 期望 %d", curve, score, want)
+		}
+	}
+}
+
+// 验证spread与binpack策略对同一使用率给出相反的偏好
+func TestScoreStrategyBinpack(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	busy := &NodeUsage{}
+	busy.setNamed("cpu", 0.8)
+	busy.setNamed("memory", 0.8)
+	idle := &NodeUsage{}
+	idle.setNamed("cpu", 0.1)
+	idle.setNamed("memory", 0.1)
+
+	scoreWith := func(args *DynamicWeightArgs) (int64, int64) {
+		d := &DynamicWeight{
+			weightLoader: &fakeWeightLoader{args: args},
+			cache:        NewNodeUsageCache(5*time.Minute, 0),
+		}
+		d.cache.Set("busy", busy)
+		d.cache.Set("idle", idle)
+		busyScore, status := d.Score(context.Background(), nil, pod, "busy")
+		if !status.IsSuccess() {
+			t.Fatalf("Score失败: %v", status)
+		}
+		idleScore, status := d.Score(context.Background(), nil, pod, "idle")
+		if !status.IsSuccess() {
+			t.Fatalf("Score失败: %v", status)
+		}
+		return busyScore, idleScore
+	}
+	weights := map[string]float64{"cpu": 0.5, "memory": 0.5}
+
+	// 默认（spread）：空闲节点得分更高
+	busyScore, idleScore := scoreWith(&DynamicWeightArgs{DefaultWeights: weights})
+	if busyScore >= idleScore {
+		t.Errorf("spread策略: busy=%d 应低于 idle=%d", busyScore, idleScore)
+	}
+
+	// binpack：忙节点得分更高
+	busyScore, idleScore = scoreWith(&DynamicWeightArgs{DefaultWeights: weights, Strategy: strategyBinpack})
+	if busyScore <= idleScore {
+		t.Errorf("binpack策略: busy=%d 应高于 idle=%d", busyScore, idleScore)
+	}
+
+	// 按资源覆盖：cpu装箱、memory分散，两维度贡献相互抵消
+	busyScore, idleScore = scoreWith(&DynamicWeightArgs{
+		DefaultWeights:     weights,
+		ResourceStrategies: map[string]string{"cpu": strategyBinpack},
+	})
+	if busyScore != idleScore {
+		t.Errorf("对半混合策略下得分应持平: busy=%d idle=%d", busyScore, idleScore)
+	}
+}
+
+// 验证策略取值校验
+func TestStrategyValidation(t *testing.T) {
+	args := &DynamicWeightArgs{Strategy: "pack"}
+	if err := args.Validate(); err == nil {
+		t.Error("未知strategy应校验失败")
+	}
+	args = &DynamicWeightArgs{ResourceStrategies: map[string]string{"cpu": "invert"}}
+	if err := args.Validate(); err == nil {
+		t.Error("未知resourceStrategies取值应校验失败")
+	}
+	args = &DynamicWeightArgs{Strategy: strategyBinpack, ResourceStrategies: map[string]string{"cpu": strategySpread}}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法策略配置不应校验失败: %v", err)
+	}
+}
+
+// 验证Score按Pod requests预测放置后使用率：大Pod在同一节点上得分更低
+func TestScorePredictedUsage(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.5)
+	usage.setNamed("memory", 0.5)
+
+	newPod := func(cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pod"},
+			Spec: v1.PodSpec{Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse(cpu),
+				}},
+			}}},
+		}
+	}
+
+	d := &DynamicWeight{
+		handle: &fakeHandle{sharedLister: &fakeSharedLister{
+			nodes: map[string]*framework.NodeInfo{"node-1": nodeInfo},
+		}},
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights:          map[string]float64{"cpu": 1.0},
+			PredictedUsageThreshold: 0.8,
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+
+	// 小Pod（100m / 4核 = 2.5%增量）：预测0.525，未越阈值
+	smallScore, status := d.Score(context.Background(), nil, newPod("100m"), "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	// 中Pod（1核 = 25%增量）：预测0.75，仍未越阈值但得分应低于小Pod
+	mediumScore, status := d.Score(context.Background(), nil, newPod("1"), "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	// 大Pod（2核 = 50%增量）：预测1.0越过0.8阈值，cpu维度记零分
+	bigScore, status := d.Score(context.Background(), nil, newPod("2"), "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+
+	if smallScore <= mediumScore {
+		t.Errorf("小Pod得分 %d 应高于中Pod %d", smallScore, mediumScore)
+	}
+	if mediumScore <= bigScore {
+		t.Errorf("中Pod得分 %d 应高于大Pod %d", mediumScore, bigScore)
+	}
+	if bigScore != 0 {
+		t.Errorf("越过阈值后唯一维度得分应为0, 实际 %d", bigScore)
+	}
+
+	// 未配置阈值时不读取快照，三种Pod得分一致
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+	}}
+	base, _ := d.Score(context.Background(), nil, newPod("2"), "node-1")
+	if base != 50 {
+		t.Errorf("关闭预测时得分 = %d, 期望按实时使用率的 50", base)
+	}
+}
+
+// 验证Reserve预留账本：同一调度突发内先行Pod抬高节点的有效使用率，
+// Unreserve回退、超过保留时长后过期
+func TestReserveInflatesSubsequentScores(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Allocatable: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.2)
+
+	newPod := func(name, cpu string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)},
+			Spec: v1.PodSpec{Containers: []v1.Container{{
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse(cpu),
+				}},
+			}}},
+		}
+	}
+
+	base := time.Now()
+	clock := base
+	d := &DynamicWeight{
+		handle: &fakeHandle{sharedLister: &fakeSharedLister{
+			nodes: map[string]*framework.NodeInfo{"node-1": nodeInfo},
+		}},
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		cache:   NewNodeUsageCache(time.Hour, 0),
+		timeNow: func() time.Time { return clock },
+	}
+	d.cache.Set("node-1", usage)
+
+	mustScore := func(pod *v1.Pod) int64 {
+		t.Helper()
+		score, status := d.Score(context.Background(), nil, pod, "node-1")
+		if !status.IsSuccess() {
+			t.Fatalf("Score失败: %v", status)
+		}
+		return score
+	}
+
+	// 无预留：按真实使用率0.2评分
+	if score := mustScore(newPod("pod-b", "1")); score != 80 {
+		t.Errorf("无预留score = %d, 期望 80", score)
+	}
+
+	// 预留pod-a（1核 = 25%增量）：后续Pod按有效使用率0.45评分
+	podA := newPod("pod-a", "1")
+	if status := d.Reserve(context.Background(), nil, podA, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Reserve失败: %v", status)
+	}
+	if score := mustScore(newPod("pod-b", "1")); score != 55 {
+		t.Errorf("单个预留后score = %d, 期望 55", score)
+	}
+
+	// 再预留pod-c：增量累加，有效使用率0.7
+	if status := d.Reserve(context.Background(), nil, newPod("pod-c", "1"), "node-1"); !status.IsSuccess() {
+		t.Fatalf("Reserve失败: %v", status)
+	}
+	if score := mustScore(newPod("pod-b", "1")); score != 30 {
+		t.Errorf("两个预留后score = %d, 期望 30", score)
+	}
+
+	// Unreserve回退pod-a：只剩pod-c的增量
+	d.Unreserve(context.Background(), nil, podA, "node-1")
+	if score := mustScore(newPod("pod-b", "1")); score != 55 {
+		t.Errorf("回退预留后score = %d, 期望 55", score)
+	}
+
+	// 超过保留时长（刷新周期+rate窗口）：真实指标视为已赶上，预留过期
+	clock = base.Add(d.reservationTTL() + time.Second)
+	if score := mustScore(newPod("pod-b", "1")); score != 80 {
+		t.Errorf("预留过期后score = %d, 期望 80", score)
+	}
+}
+
+// 验证MetricScale：百分比口径的原始值按0.01系数折算为小数，
+// 未配置系数时超出1的原始值被clamp截断
+func TestMetricScale(t *testing.T) {
+	prom := &fakePromAPI{values: map[string]float64{
+		"node_cpu_seconds_total":            45, // exporter按百分比上报
+		"node_memory_MemTotal_bytes":        0.4,
+		"node_disk_io_time_seconds_total":   0.3,
+		"node_network_receive_bytes_total":  0.1,
+		"node_network_transmit_bytes_total": 0.1,
+	}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			MetricScale: map[string]float64{"cpu": 0.01},
+		}},
+		promClient: prom,
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usage, err := d.updateNodeUsage(context.Background(), newProviderTestNode("node-1"))
+	if err != nil {
+		t.Fatalf("updateNodeUsage失败: %v", err)
+	}
+	if usage.CPU != 0.45 {
+		t.Errorf("cpu = %v, 期望 45 * 0.01 = 0.45", usage.CPU)
+	}
+	if usage.Memory != 0.4 {
+		t.Errorf("memory = %v, 无系数的维度不应缩放", usage.Memory)
+	}
+
+	// 未配置系数：百分比口径的45超出[0,1]，被clamp截断为1
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{}}
+	d.cache = NewNodeUsageCache(5*time.Minute, 0)
+	usage, err = d.updateNodeUsage(context.Background(), newProviderTestNode("node-1"))
+	if err != nil {
+		t.Fatalf("updateNodeUsage失败: %v", err)
+	}
+	if usage.CPU != 1.0 {
+		t.Errorf("cpu = %v, 未缩放时应截断为1.0", usage.CPU)
+	}
+
+	// 非正系数在配置校验阶段拒绝
+	args := &DynamicWeightArgs{MetricScale: map[string]float64{"cpu": 0}}
+	if err := args.Validate(); err == nil {
+		t.Error("非正的缩放系数应校验失败")
+	}
+}
+
+// 验证节点更新的缓存失效判定：封锁翻转与地址变化触发，无关更新不触发
+func TestNodeCacheInvalidated(t *testing.T) {
+	base := func() *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       v1.NodeSpec{},
+			Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+			}},
+		}
+	}
+
+	cordoned := base()
+	cordoned.Spec.Unschedulable = true
+	if !nodeCacheInvalidated(base(), cordoned) {
+		t.Error("封锁状态翻转应作废缓存")
+	}
+
+	moved := base()
+	moved.Status.Addresses[0].Address = "10.0.0.2"
+	if !nodeCacheInvalidated(base(), moved) {
+		t.Error("地址变化应作废缓存")
+	}
+
+	labeled := base()
+	labeled.Labels = map[string]string{"zone": "a"}
+	if nodeCacheInvalidated(base(), labeled) {
+		t.Error("无关的标签更新不应作废缓存")
+	}
+}
+
+// 验证得分量化：近似的原始得分收敛到同一量化值，未配置时原样保留
+func TestNormalizeScoreQuantization(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	newScores := func() framework.NodeScoreList {
+		return framework.NodeScoreList{
+			{Name: "node-a", Score: 71},
+			{Name: "node-b", Score: 73},
+			{Name: "node-c", Score: 98},
+		}
+	}
+
+	// 桶宽10：71与73收敛到同一桶，排序不再受细微抖动影响
+	d := &DynamicWeight{weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+		ScoreQuantization: 10,
+	}}}
+	scores := newScores()
+	if status := d.NormalizeScore(context.Background(), nil, pod, scores); !status.IsSuccess() {
+		t.Fatalf("NormalizeScore失败: %v", status)
+	}
+	if scores[0].Score != 70 || scores[1].Score != 70 {
+		t.Errorf("近似得分未收敛到同一桶: %d / %d, 期望 70 / 70", scores[0].Score, scores[1].Score)
+	}
+	if scores[2].Score != 100 {
+		t.Errorf("98应圆整到100, 实际 %d", scores[2].Score)
+	}
+
+	// 未配置（默认0）：得分原样保留
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{}}
+	scores = newScores()
+	if status := d.NormalizeScore(context.Background(), nil, pod, scores); !status.IsSuccess() {
+		t.Fatalf("NormalizeScore失败: %v", status)
+	}
+	if scores[0].Score != 71 || scores[1].Score != 73 {
+		t.Errorf("未配置量化时得分不应改变: %d / %d", scores[0].Score, scores[1].Score)
+	}
+
+	// 量化后得分不得越过框架上限
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{ScoreQuantization: 100}}
+	scores = framework.NodeScoreList{{Name: "node-a", Score: 60}}
+	_ = d.NormalizeScore(context.Background(), nil, pod, scores)
+	if scores[0].Score > framework.MaxNodeScore {
+		t.Errorf("量化得分 %d 越过上限", scores[0].Score)
+	}
+
+	// 桶宽越界在配置校验阶段拒绝
+	args := &DynamicWeightArgs{ScoreQuantization: 101}
+	if err := args.Validate(); err == nil {
+		t.Error("超过100的桶宽应校验失败")
+	}
+}
+
+// 验证评分解释：开关开启时各维度贡献写入CycleState且合计等于最终得分
+func TestScoreBreakdown(t *testing.T) {
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.4)
+	usage.setNamed("memory", 0.8)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 0.6, "memory": 0.4},
+			ExplainScores:  true,
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+
+	state := framework.NewCycleState()
+	score, status := d.Score(context.Background(), state, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+
+	breakdown := d.ScoreBreakdown(state, "node-1")
+	if breakdown == nil {
+		t.Fatal("开关开启时应能取回得分构成")
+	}
+	almostEqual := func(a, b float64) bool {
+		return a > b-1e-9 && a < b+1e-9
+	}
+	// cpu: 0.6×(1-0.4)=0.36 → 36分；memory: 0.4×(1-0.8)=0.08 → 8分
+	if got := breakdown["cpu"]; !almostEqual(got, 36) {
+		t.Errorf("cpu贡献 = %v, 期望 36", got)
+	}
+	if got := breakdown["memory"]; !almostEqual(got, 8) {
+		t.Errorf("memory贡献 = %v, 期望 8", got)
+	}
+	sum := 0.0
+	for _, part := range breakdown {
+		sum += part
+	}
+	// 最终得分由合计值浮点截断而来，允许差一分
+	if diff := sum - float64(score); diff < -1 || diff > 1 {
+		t.Errorf("贡献合计 %v 与最终得分 %d 不一致", sum, score)
+	}
+
+	// 其他节点无记录
+	if d.ScoreBreakdown(state, "node-2") != nil {
+		t.Error("未评分的节点不应有得分构成")
+	}
+
+	// 开关关闭：不写入状态
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 1.0},
+	}}
+	state = framework.NewCycleState()
+	if _, status := d.Score(context.Background(), state, pod, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if d.ScoreBreakdown(state, "node-1") != nil {
+		t.Error("开关关闭时不应写入得分构成")
+	}
+}
+
+// 验证Score使用ctx携带的上下文日志器，且pod/node键值统一附加
+func TestScoreContextualLogging(t *testing.T) {
+	var lines []string
+	logger := funcr.NewJSON(func(obj string) { lines = append(lines, obj) },
+		funcr.Options{Verbosity: 5})
+	ctx := klog.NewContext(context.Background(), logger)
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.4)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"}}
+
+	if _, status := d.Score(ctx, nil, pod, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+
+	findLine := func(msg string) string {
+		t.Helper()
+		for _, line := range lines {
+			if strings.Contains(line, msg) {
+				return line
+			}
+		}
+		t.Fatalf("未捕获到日志 %q, 实际输出: %v", msg, lines)
+		return ""
+	}
+
+	line := findLine("节点评分结果")
+	if !strings.Contains(line, `"pod":{"name":"test-pod","namespace":"default"}`) {
+		t.Errorf("评分日志缺少pod键值: %s", line)
+	}
+	if !strings.Contains(line, `"node":"node-1"`) {
+		t.Errorf("评分日志缺少node键值: %s", line)
+	}
+
+	// 兜底路径（指标不可用的fail-open警告）同样走上下文日志器
+	lines = nil
+	if _, status := d.Score(ctx, nil, pod, "missing-node"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	line = findLine("节点指标不可用")
+	if !strings.Contains(line, `"pod":{"name":"test-pod","namespace":"default"}`) || !strings.Contains(line, `"node":"missing-node"`) {
+		t.Errorf("兜底日志缺少上下文键值: %s", line)
+	}
+}
+
+// 验证评分直方图按resource-prefer标签观测最终得分
+func TestScoreMetrics(t *testing.T) {
+	registerMetrics()
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.4)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "test-pod",
+		Labels: map[string]string{"resource-prefer": "score-metric-test"},
+	}}
+
+	observer := nodeScore.WithLabelValues("score-metric-test")
+	sumBefore, err := testutil.GetHistogramMetricValue(observer)
+	if err != nil {
+		t.Fatalf("读取直方图失败: %v", err)
+	}
+
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != 60 {
+		t.Fatalf("score = %d, 期望 usage 0.4 对应的 60", score)
+	}
+	sumAfter, err := testutil.GetHistogramMetricValue(observer)
+	if err != nil {
+		t.Fatalf("读取直方图失败: %v", err)
+	}
+	if sumAfter-sumBefore != 60 {
+		t.Errorf("直方图观测增量 = %v, 期望 60", sumAfter-sumBefore)
+	}
+
+	// fail-closed错误路径计入评分失败计数
+	failOpen := false
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{FailOpen: &failOpen}}
+	errCounter := scoringErrors.WithLabelValues("score-metric-test")
+	errBefore, err := testutil.GetCounterMetricValue(errCounter)
+	if err != nil {
+		t.Fatalf("读取计数器失败: %v", err)
+	}
+	if _, status = d.Score(context.Background(), nil, pod, "missing-node"); status.IsSuccess() {
+		t.Fatal("期望评分失败")
+	}
+	errAfter, err := testutil.GetCounterMetricValue(errCounter)
+	if err != nil {
+		t.Fatalf("读取计数器失败: %v", err)
+	}
+	if errAfter-errBefore != 1 {
+		t.Errorf("scoring_errors_total增量 = %v, 期望 1", errAfter-errBefore)
+	}
+}
+
+// 验证权重来源计数器按评分时实际选中的配置路径打标
+func TestWeightSourceMetric(t *testing.T) {
+	registerMetrics()
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.4)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			LabelWeights: map[string]map[string]float64{
+				"cpu": {"cpu": 0.8, "memory": 0.2},
+			},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+
+	sourceValue := func(source string) float64 {
+		v, err := testutil.GetCounterMetricValue(weightSourceTotal.WithLabelValues(source))
+		if err != nil {
+			t.Fatalf("读取计数器失败: %v", err)
+		}
+		return v
+	}
+
+	// 无标签Pod走默认权重
+	defaultBefore := sourceValue("default")
+	if _, status := d.Score(context.Background(), nil, &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "plain-pod"}}, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if got := sourceValue("default") - defaultBefore; got != 1 {
+		t.Errorf("default来源增量 = %v, 期望 1", got)
+	}
+
+	// 带resource-prefer标签且命中LabelWeights时计入label来源
+	labelBefore := sourceValue("label")
+	labelPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "label-pod",
+		Labels: map[string]string{"resource-prefer": "cpu"},
+	}}
+	if _, status := d.Score(context.Background(), nil, labelPod, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if got := sourceValue("label") - labelBefore; got != 1 {
+		t.Errorf("label来源增量 = %v, 期望 1", got)
+	}
+
+	// 命名空间权重优先级高于标签权重，来源记为namespace
+	d.weightLoader = &fakeWeightLoader{args: &DynamicWeightArgs{
+		DefaultWeights:   map[string]float64{"cpu": 1.0},
+		NamespaceWeights: map[string]map[string]float64{"prod": {"cpu": 1.0}},
+	}}
+	nsBefore := sourceValue("namespace")
+	nsPod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "ns-pod", Namespace: "prod"}}
+	if _, status := d.Score(context.Background(), nil, nsPod, "node-1"); !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if got := sourceValue("namespace") - nsBefore; got != 1 {
+		t.Errorf("namespace来源增量 = %v, 期望 1", got)
+	}
+}
+
+// 验证越界与NaN/Inf使用率的收敛
+func TestClampUsage(t *testing.T) {
+	cases := []struct {
+		name  string
+		value float64
+		want  float64
+	}{
+		{"区间内原样返回", 0.42, 0.42},
+		{"超过100%收敛到1", 1.7, 1.0},
+		{"负值收敛到0", -0.3, 0},
+		{"NaN按中性值", math.NaN(), 0.5},
+		{"正Inf按中性值", math.Inf(1), 0.5},
+		{"负Inf按中性值", math.Inf(-1), 0.5},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := clampUsage(tc.value); got != tc.want {
+				t.Errorf("clampUsage(%v) = %v, 期望 %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+// 验证Score对脏使用率数据仍产出合法区间的得分
+func TestScoreClampsToValidRange(t *testing.T) {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	// 缓存中可能残留旧版本写入的越界值（未经clampUsage的历史数据）
+	over := &NodeUsage{}
+	over.setNamed("cpu", 1.8) // (1-1.8)*100 = -80，未兜底会越过MinNodeScore
+	d.cache.Set("node-over", over)
+	score, status := d.Score(context.Background(), nil, pod, "node-over")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score < framework.MinNodeScore || score > framework.MaxNodeScore {
+		t.Errorf("score = %d 超出合法区间[%d, %d]", score, framework.MinNodeScore, framework.MaxNodeScore)
+	}
+
+	nan := &NodeUsage{}
+	nan.setNamed("cpu", math.NaN())
+	d.cache.Set("node-nan", nan)
+	score, status = d.Score(context.Background(), nil, pod, "node-nan")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("NaN使用率得分 = %d, 期望中性分 %d", score, neutralScore)
+	}
+}
+
+// 验证插件健康状态随Prometheus查询成败翻转
+func TestPluginHealthy(t *testing.T) {
+	registerMetrics()
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{}},
+		promClient: &fakePromAPI{values: map[string]float64{
+			"node_cpu_seconds_total": 0.5,
+		}},
+	}
+
+	// 启动初期未查询过：不算异常
+	if !d.Healthy() {
+		t.Error("未发起过查询时Healthy()应为true")
+	}
+
+	// 成功查询后健康
+	if _, err := d.timedQuery(context.Background(), "cpu",
+		`sum(rate(node_cpu_seconds_total{mode!="idle", instance=~"10.0.0.1(:.*)?"}[2m])) / count(node_cpu_seconds_total{mode="user", instance=~"10.0.0.1(:.*)?"})`); err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if !d.Healthy() {
+		t.Error("查询成功后Healthy()应为true")
+	}
+
+	// 查询失败后不健康
+	if _, err := d.timedQuery(context.Background(), "cpu", "no_such_metric"); err == nil {
+		t.Fatal("期望查询失败")
+	}
+	if d.Healthy() {
+		t.Error("查询失败后Healthy()应为false")
+	}
+
+	// 新鲜窗口外的历史成功不再算健康
+	d.recordQueryHealth(true)
+	d.lastQuerySuccessNano.Store(time.Now().Add(-10 * defaultRefreshInterval).UnixNano())
+	if d.Healthy() {
+		t.Error("成功查询超出新鲜窗口后Healthy()应为false")
+	}
+}
+
+// recordingTransport 记录最近一次请求的传输层桩
+type recordingTransport struct {
+	lastReq *http.Request
+}
+
+func (r *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.lastReq = req
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Header:     http.Header{},
+	}, nil
+}
+
+// 验证配置的附加头与租户标识注入到每个出站查询请求
+func TestQueryHeadersInjection(t *testing.T) {
+	rec := &recordingTransport{}
+	rt := withQueryHeaders(&DynamicWeightArgs{
+		QueryHeaders: map[string]string{"X-Custom-Route": "edge"},
+		TenantHeader: "tenant-a",
+	}, rec)
+
+	req, err := http.NewRequest(http.MethodGet, "http://prometheus.example/api/v1/query", nil)
+	if err != nil {
+		t.Fatalf("构造请求失败: %v", err)
+	}
+	if _, err := rt.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip失败: %v", err)
+	}
+
+	if got := rec.lastReq.Header.Get("X-Custom-Route"); got != "edge" {
+		t.Errorf("X-Custom-Route = %q, 期望 edge", got)
+	}
+	if got := rec.lastReq.Header.Get(tenantHeaderName); got != "tenant-a" {
+		t.Errorf("%s = %q, 期望 tenant-a", tenantHeaderName, got)
+	}
+	// 原始请求不被修改
+	if req.Header.Get(tenantHeaderName) != "" {
+		t.Error("原始请求对象不应被注入头")
+	}
+
+	// 未配置附加头时不包装
+	if got := withQueryHeaders(&DynamicWeightArgs{}, rec); got != http.RoundTripper(rec) {
+		t.Error("无附加头时应原样返回底层传输层")
+	}
+}
+
+// 验证HTTP头名称校验
+func TestQueryHeadersValidation(t *testing.T) {
+	args := &DynamicWeightArgs{QueryHeaders: map[string]string{"Bad Header": "x"}}
+	if err := args.Validate(); err == nil {
+		t.Error("含空格的头名称应校验失败")
+	}
+	args = &DynamicWeightArgs{QueryHeaders: map[string]string{"X-Scope-OrgID": "tenant-b"}}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法头名称不应校验失败: %v", err)
+	}
+}
+
+// 验证观察模式：返回恒定中性分，指标仍记录真实计算值
+func TestScoreObserveOnly(t *testing.T) {
+	registerMetrics()
+
+	usage := &NodeUsage{}
+	usage.setNamed("cpu", 0.2)
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 1.0},
+			ObserveOnly:    true,
+		}},
+		cache: NewNodeUsageCache(5*time.Minute, 0),
+	}
+	d.cache.Set("node-1", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "test-pod",
+		Labels: map[string]string{"resource-prefer": "observe-mode-test"},
+	}}
+
+	observer := nodeScore.WithLabelValues("observe-mode-test")
+	sumBefore, err := testutil.GetHistogramMetricValue(observer)
+	if err != nil {
+		t.Fatalf("读取直方图失败: %v", err)
+	}
+
+	score, status := d.Score(context.Background(), nil, pod, "node-1")
+	if !status.IsSuccess() {
+		t.Fatalf("Score失败: %v", status)
+	}
+	if score != neutralScore {
+		t.Errorf("观察模式得分 = %d, 期望恒定中性分 %d", score, neutralScore)
+	}
+
+	sumAfter, err := testutil.GetHistogramMetricValue(observer)
+	if err != nil {
+		t.Fatalf("读取直方图失败: %v", err)
+	}
+	if sumAfter-sumBefore != 80 {
+		t.Errorf("直方图观测增量 = %v, 期望真实计算值 80", sumAfter-sumBefore)
+	}
+}
+
+// 验证注入装配函数与表驱动的评分数学：多组权重/使用率组合
+func TestScoreTableDriven(t *testing.T) {
+	cases := []struct {
+		name    string
+		weights map[string]float64
+		usage   map[string]float64
+		want    int64
+	}{
+		{
+			name:    "均分权重中等负载",
+			weights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+			usage:   map[string]float64{"cpu": 0.4, "memory": 0.6},
+			want:    50, // 0.5*0.6 + 0.5*0.4 = 0.5
+		},
+		{
+			name:    "偏重CPU的空闲节点",
+			weights: map[string]float64{"cpu": 0.8, "memory": 0.2},
+			usage:   map[string]float64{"cpu": 0.1, "memory": 0.9},
+			want:    74, // 0.8*0.9 + 0.2*0.1 = 0.74
+		},
+		{
+			name:    "单维度满载",
+			weights: map[string]float64{"cpu": 1.0},
+			usage:   map[string]float64{"cpu": 1.0},
+			want:    0,
+		},
+		{
+			name:    "缺失维度剔除后重归一化",
+			weights: map[string]float64{"cpu": 0.5, "diskio": 0.5},
+			usage:   map[string]float64{"cpu": 0.2}, // diskio无数据
+			want:    80,                             // 仅cpu参与：0.5*0.8/0.5
+		},
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDynamicWeight(nil,
+				&fakeWeightLoader{args: &DynamicWeightArgs{DefaultWeights: tc.weights}},
+				&fakePromAPI{}, NewNodeUsageCache(5*time.Minute, 0), nil, nil)
+			// 通过Metrics映射构造：缺失的维度对Get返回not-ok，
+			// 触发评分的剔除与重归一化路径
+			usage := &NodeUsage{Metrics: map[string]float64{}}
+			for res, value := range tc.usage {
+				usage.Metrics[res] = value
+				usage.setNamed(res, value)
+			}
+			d.cache.Set("node-1", usage)
+
+			score, status := d.Score(context.Background(), nil, pod, "node-1")
+			if !status.IsSuccess() {
+				t.Fatalf("Score失败: %v", status)
+			}
+			if score != tc.want {
+				t.Errorf("score = %d, 期望 %d", score, tc.want)
+			}
+		})
+	}
+}
+
+// TestFrameworkIntegration 通过runtime.Registry把插件接入真实的调度框架，
+// 跑一次打分周期，校验工厂签名与端到端打分路径：使用率最低的节点得分最高
+func TestFrameworkIntegration(t *testing.T) {
+	// 框架构建时会给打分插件包一层指标计数，需要先注册调度器指标
+	schedmetrics.Register()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	usages := map[string]*NodeUsage{
+		"node-low":  {Metrics: map[string]float64{"cpu": 0.1, "memory": 0.1}},
+		"node-mid":  {Metrics: map[string]float64{"cpu": 0.5, "memory": 0.5}},
+		"node-high": {Metrics: map[string]float64{"cpu": 0.9, "memory": 0.9}},
+	}
+
+	registry := frameworkruntime.Registry{
+		queuesort.Name:     queuesort.New,
+		defaultbinder.Name: defaultbinder.New,
+		// 工厂走与New相同的参数解析/加载器路径，但注入假Prometheus
+		// 客户端并预置使用率缓存，避免测试依赖真实查询
+		Name: func(ctx context.Context, args runtime.Object, h framework.Handle) (framework.Plugin, error) {
+			dwArgs, err := NewDynamicWeightArgs(args)
+			if err != nil {
+				return nil, err
+			}
+			if err := dwArgs.Validate(); err != nil {
+				return nil, err
+			}
+			loader, err := newWeightLoaderForArgs(ctx, h.ClientSet(), nil, dwArgs)
+			if err != nil {
+				return nil, err
+			}
+			cache := NewNodeUsageCache(5*time.Minute, 0)
+			for node, usage := range usages {
+				cache.Set(node, usage)
+			}
+			return newDynamicWeight(h, loader, &fakePromAPI{}, cache, nil, nil), nil
+		},
+	}
+
+	profile := &schedconfig.KubeSchedulerProfile{
+		SchedulerName: "default-scheduler",
+		Plugins: &schedconfig.Plugins{
+			QueueSort: schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: queuesort.Name}}},
+			Bind:      schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: defaultbinder.Name}}},
+			Score:     schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: Name, Weight: 1}}},
+		},
+		PluginConfig: []schedconfig.PluginConfig{
+			{Name: Name, Args: &DynamicWeightArgs{}},
+		},
+	}
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-low").Obj(),
+		st.MakeNode().Name("node-mid").Obj(),
+		st.MakeNode().Name("node-high").Obj(),
+	}
+	client := clientsetfake.NewClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	snapshot := internalcache.NewSnapshot(nil, nodes)
+
+	fwk, err := frameworkruntime.NewFramework(ctx, registry, profile,
+		frameworkruntime.WithClientSet(client),
+		frameworkruntime.WithInformerFactory(informerFactory),
+		frameworkruntime.WithSnapshotSharedLister(snapshot),
+	)
+	if err != nil {
+		t.Fatalf("构建调度框架失败: %v", err)
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+	state := framework.NewCycleState()
+	nodeInfos := tf.BuildNodeInfos(nodes)
+
+	scores, status := fwk.RunScorePlugins(ctx, state, pod, nodeInfos)
+	if !status.IsSuccess() {
+		t.Fatalf("打分失败: %v", status)
+	}
+
+	best := ""
+	bestScore := int64(-1)
+	for _, nodeScore := range scores {
+		t.Logf("节点 %s 总分 %d", nodeScore.Name, nodeScore.TotalScore)
+		if nodeScore.TotalScore > bestScore {
+			bestScore = nodeScore.TotalScore
+			best = nodeScore.Name
+		}
+	}
+	if best != "node-low" {
+		t.Errorf("期望使用率最低的node-low得分最高，实际最高为 %s", best)
+	}
+}
+
+// TestFeatureGateToggle 校验DynamicWeight特性门控的开关效果：
+// 开启时工厂构建完整插件，缓存未命中走fail-open返回中性分；
+// 关闭时工厂返回空操作实现，评分恒为0（不参与节点排序）
+func TestFeatureGateToggle(t *testing.T) {
+	schedmetrics.Register()
+
+	testCases := []struct {
+		name    string
+		enabled bool
+		want    int64 // 每个节点的期望总分
+	}{
+		{name: "门控开启，插件参与评分", enabled: true, want: 50},
+		{name: "门控关闭，空操作实现", enabled: false, want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			registry := frameworkruntime.Registry{
+				queuesort.Name:     queuesort.New,
+				defaultbinder.Name: defaultbinder.New,
+				Name:               frameworkruntime.FactoryAdapter(feature.Features{EnableDynamicWeight: tc.enabled}, New),
+			}
+
+			profile := &schedconfig.KubeSchedulerProfile{
+				SchedulerName: "default-scheduler",
+				Plugins: &schedconfig.Plugins{
+					QueueSort: schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: queuesort.Name}}},
+					Bind:      schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: defaultbinder.Name}}},
+					Score:     schedconfig.PluginSet{Enabled: []schedconfig.Plugin{{Name: Name, Weight: 1}}},
+				},
+				PluginConfig: []schedconfig.PluginConfig{
+					{Name: Name, Args: &DynamicWeightArgs{}},
+				},
+			}
+
+			nodes := []*v1.Node{st.MakeNode().Name("node-a").Obj()}
+			client := clientsetfake.NewClientset()
+			informerFactory := informers.NewSharedInformerFactory(client, 0)
+			snapshot := internalcache.NewSnapshot(nil, nodes)
+
+			fwk, err := frameworkruntime.NewFramework(ctx, registry, profile,
+				frameworkruntime.WithClientSet(client),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			if err != nil {
+				t.Fatalf("构建调度框架失败: %v", err)
+			}
+
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod"}}
+			scores, status := fwk.RunScorePlugins(ctx, framework.NewCycleState(), pod, tf.BuildNodeInfos(nodes))
+			if !status.IsSuccess() {
+				t.Fatalf("打分失败: %v", status)
+			}
+			for _, nodeScore := range scores {
+				if nodeScore.TotalScore != tc.want {
+					t.Errorf("节点 %s 总分 = %d, 期望 %d", nodeScore.Name, nodeScore.TotalScore, tc.want)
+				}
+			}
+		})
+	}
+}
+
+// TestActiveScheduledWeights 校验时段权重窗口的匹配规则：
+// 普通/跨午夜窗口、星期过滤、重叠时列表序优先、时区换算与夏令时
+func TestActiveScheduledWeights(t *testing.T) {
+	businessHours := map[string]float64{"cpu": 0.7, "memory": 0.3}
+	nightHours := map[string]float64{"cpu": 0.2, "memory": 0.8}
+
+	testCases := []struct {
+		name      string
+		schedules []ScheduledWeight
+		now       time.Time
+		want      map[string]float64 // nil表示无命中（回退默认权重）
+	}{
+		{
+			name: "工作时段命中",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 18, Weights: businessHours},
+			},
+			now:  time.Date(2026, 8, 31, 10, 0, 0, 0, time.UTC), // 周一
+			want: businessHours,
+		},
+		{
+			name: "窗口外无命中",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 18, Weights: businessHours},
+			},
+			now:  time.Date(2026, 8, 31, 20, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+		{
+			name: "终点小时不含在窗口内",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 18, Weights: businessHours},
+			},
+			now:  time.Date(2026, 8, 31, 18, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+		{
+			name: "跨午夜窗口的凌晨命中",
+			schedules: []ScheduledWeight{
+				{StartHour: 22, EndHour: 6, Weights: nightHours},
+			},
+			now:  time.Date(2026, 8, 31, 2, 0, 0, 0, time.UTC),
+			want: nightHours,
+		},
+		{
+			name: "跨午夜窗口的白天无命中",
+			schedules: []ScheduledWeight{
+				{StartHour: 22, EndHour: 6, Weights: nightHours},
+			},
+			now:  time.Date(2026, 8, 31, 12, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+		{
+			name: "星期过滤：周末不命中工作日窗口",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 18, Days: []int{1, 2, 3, 4, 5}, Weights: businessHours},
+			},
+			now:  time.Date(2026, 8, 30, 10, 0, 0, 0, time.UTC), // 周日
+			want: nil,
+		},
+		{
+			name: "窗口重叠时列表序在前者优先",
+			schedules: []ScheduledWeight{
+				{StartHour: 8, EndHour: 12, Weights: businessHours},
+				{StartHour: 9, EndHour: 18, Weights: nightHours},
+			},
+			now:  time.Date(2026, 8, 31, 10, 0, 0, 0, time.UTC),
+			want: businessHours,
+		},
+		{
+			name: "时区换算：UTC时刻按窗口时区判断",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 18, TimeZone: "Asia/Shanghai", Weights: businessHours},
+			},
+			// UTC 02:00 = 上海 10:00，命中
+			now:  time.Date(2026, 8, 31, 2, 0, 0, 0, time.UTC),
+			want: businessHours,
+		},
+		{
+			name: "夏令时：夏季UTC 21点=纽约17点，已出窗口",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 17, TimeZone: "America/New_York", Weights: businessHours},
+			},
+			now:  time.Date(2026, 7, 1, 21, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+		{
+			name: "夏令时：冬季UTC 21点=纽约16点，仍在窗口",
+			schedules: []ScheduledWeight{
+				{StartHour: 9, EndHour: 17, TimeZone: "America/New_York", Weights: businessHours},
+			},
+			now:  time.Date(2026, 1, 5, 21, 0, 0, 0, time.UTC),
+			want: businessHours,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := &DynamicWeightArgs{
+				DefaultWeights:   map[string]float64{"cpu": 0.5, "memory": 0.5},
+				ScheduledWeights: tc.schedules,
+			}
+			got := activeScheduledWeights(args, tc.now)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("activeScheduledWeights = %v, 期望 %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// 验证Score按注入时钟选择时段权重：同一节点在窗口内外得分不同
+func TestScoreUsesScheduledWeights(t *testing.T) {
+	args := &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+		ScheduledWeights: []ScheduledWeight{
+			// 工作时段全部权重压到CPU
+			{StartHour: 9, EndHour: 18, Weights: map[string]float64{"cpu": 1.0}},
+		},
+	}
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: args},
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+	// CPU空闲、内存繁忙的节点：CPU权重越高得分越高
+	d.cache.Set("node1", &NodeUsage{Metrics: map[string]float64{"cpu": 0.1, "memory": 0.9}})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}
+
+	d.timeNow = func() time.Time { return time.Date(2026, 8, 31, 10, 0, 0, 0, time.UTC) }
+	inWindow, status := d.Score(context.Background(), framework.NewCycleState(), pod, "node1")
+	if !status.IsSuccess() {
+		t.Fatalf("窗口内打分失败: %v", status)
+	}
+
+	d.timeNow = func() time.Time { return time.Date(2026, 8, 31, 22, 0, 0, 0, time.UTC) }
+	outOfWindow, status := d.Score(context.Background(), framework.NewCycleState(), pod, "node1")
+	if !status.IsSuccess() {
+		t.Fatalf("窗口外打分失败: %v", status)
+	}
+
+	if inWindow <= outOfWindow {
+		t.Errorf("窗口内得分 %d 应高于窗口外的 %d（全权重押在空闲的CPU上）", inWindow, outOfWindow)
+	}
+}
+
+// 验证时段权重的配置校验：非法小时/空窗口/非法星期/非法时区均被拒绝
+func TestScheduledWeightsValidation(t *testing.T) {
+	base := map[string]float64{"cpu": 0.5, "memory": 0.5}
+	testCases := []struct {
+		name     string
+		schedule ScheduledWeight
+		wantErr  bool
+	}{
+		{name: "合法窗口", schedule: ScheduledWeight{StartHour: 9, EndHour: 18, Weights: base}},
+		{name: "跨午夜窗口合法", schedule: ScheduledWeight{StartHour: 22, EndHour: 6, Weights: base}},
+		{name: "小时越界", schedule: ScheduledWeight{StartHour: 9, EndHour: 24, Weights: base}, wantErr: true},
+		{name: "空窗口", schedule: ScheduledWeight{StartHour: 9, EndHour: 9, Weights: base}, wantErr: true},
+		{name: "星期越界", schedule: ScheduledWeight{StartHour: 9, EndHour: 18, Days: []int{7}, Weights: base}, wantErr: true},
+		{name: "非法时区", schedule: ScheduledWeight{StartHour: 9, EndHour: 18, TimeZone: "Mars/Olympus", Weights: base}, wantErr: true},
+		{name: "权重和偏离1.0", schedule: ScheduledWeight{StartHour: 9, EndHour: 18, Weights: map[string]float64{"cpu": 3.0}}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := &DynamicWeightArgs{
+				DefaultWeights:   base,
+				ScheduledWeights: []ScheduledWeight{tc.schedule},
+			}
+			err := args.Validate()
+			if tc.wantErr && err == nil {
+				t.Error("期望校验失败，实际通过")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("期望校验通过，实际失败: %v", err)
+			}
+		})
+	}
+}
+
+// newFakeNodeLister 构造包含给定节点的NodeLister桩
+func newFakeNodeLister(t *testing.T, nodes ...*v1.Node) listersv1.NodeLister {
+	indexer := clientgocache.NewIndexer(clientgocache.MetaNamespaceKeyFunc, clientgocache.Indexers{})
+	for _, node := range nodes {
+		if err := indexer.Add(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return listersv1.NewNodeLister(indexer)
+}
+
+// TestNodeWeightOverride 校验节点注解权重覆盖：
+// 覆盖优先级高于Pod的resource-prefer标签，写坏的注解被忽略
+func TestNodeWeightOverride(t *testing.T) {
+	annotated := st.MakeNode().Name("node-override").Obj()
+	annotated.Annotations = map[string]string{
+		// 该节点评分只看CPU维度
+		nodeWeightOverrideAnnotation: `{"cpu":1.0}`,
+	}
+	broken := st.MakeNode().Name("node-broken").Obj()
+	broken.Annotations = map[string]string{
+		nodeWeightOverrideAnnotation: `{"cpu":`, // 非法JSON，应被忽略
+	}
+	plain := st.MakeNode().Name("node-plain").Obj()
+
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: &DynamicWeightArgs{
+			DefaultWeights: map[string]float64{"cpu": 0.5, "memory": 0.5},
+			LabelWeights:   map[string]map[string]float64{"mem": {"memory": 1.0}},
+		}},
+		cache:      NewNodeUsageCache(5*time.Minute, 0),
+		nodeLister: newFakeNodeLister(t, annotated, broken, plain),
+	}
+	// 三个节点使用率相同：CPU空闲、内存繁忙
+	usage := map[string]float64{"cpu": 0.1, "memory": 0.9}
+	for _, name := range []string{"node-override", "node-broken", "node-plain"} {
+		d.cache.Set(name, &NodeUsage{Metrics: usage})
+	}
+
+	// Pod声明偏好内存：常规路径权重全压在繁忙的内存维度上
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "pod1",
+		Labels: map[string]string{"resource-prefer": "mem"},
+	}}
+	state := framework.NewCycleState()
+	score := func(node string) int64 {
+		got, status := d.Score(context.Background(), state, pod, node)
+		if !status.IsSuccess() {
+			t.Fatalf("节点 %s 打分失败: %v", node, status)
+		}
+		return got
+	}
+
+	overrideScore := score("node-override")
+	brokenScore := score("node-broken")
+	plainScore := score("node-plain")
+
+	// 注解覆盖把权重改到空闲的CPU上，得分应显著高于按内存评分的节点
+	if overrideScore <= plainScore {
+		t.Errorf("覆盖节点得分 %d 应高于常规节点的 %d", overrideScore, plainScore)
+	}
+	// 写坏的注解被忽略：与无注解节点得分一致
+	if brokenScore != plainScore {
+		t.Errorf("非法注解节点得分 %d 应与常规节点的 %d 一致", brokenScore, plainScore)
+	}
+}
+
+// TestExtendedResourceUsage 校验扩展资源维度端到端参与评分：
+// 配置nvidia.com/gpu的查询模板后，使用率拉取与权重评分均按同名键工作
+func TestExtendedResourceUsage(t *testing.T) {
+	prom := &fakePromAPI{
+		values: map[string]float64{
+			"nvidia_gpu_duty_cycle":             0.8,
+			"node_cpu_seconds_total":            0.2,
+			"node_memory_MemTotal_bytes":        0.4,
+			"node_disk_io_time_seconds_total":   0.3,
+			"node_network_receive_bytes_total":  0.1,
+			"node_network_transmit_bytes_total": 0.1,
+		},
+	}
+	args := &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"nvidia.com/gpu": 1.0},
+		ExtendedResources: map[string]string{
+			"nvidia.com/gpu": `avg(nvidia_gpu_duty_cycle{instance="{instance}"})`,
+		},
+	}
+	if err := args.Validate(); err != nil {
+		t.Fatalf("合法扩展资源配置未通过校验: %v", err)
+	}
+
+	d := &DynamicWeight{
+		weightLoader: &fakeWeightLoader{args: args},
+		promClient:   prom,
+		cache:        NewNodeUsageCache(5*time.Minute, 0),
+	}
+
+	usage, err := d.queryNodeUsage(context.Background(), "10.0.0.1", nil)
+	if err != nil {
+		t.Fatalf("queryNodeUsage失败: %v", err)
+	}
+	if got, ok := usage.Get("nvidia.com/gpu"); !ok || got != 0.8 {
+		t.Fatalf("nvidia.com/gpu使用率 = %v (ok=%v), 期望 0.8", got, ok)
+	}
+
+	// 评分路径：权重全部押在扩展资源维度上
+	d.cache.Set("node-gpu", usage)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}
+	score, status := d.Score(context.Background(), framework.NewCycleState(), pod, "node-gpu")
+	if !status.IsSuccess() {
+		t.Fatalf("打分失败: %v", status)
+	}
+	// 线性曲线下得分 = 100 * (1 - 0.8)，浮点截断允许差一分
+	if score < 19 || score > 20 {
+		t.Errorf("score = %d, 期望约 20", score)
+	}
+}
+
+// 验证扩展资源映射的配置校验：资源名必须是合法标识，模板不能为空
+func TestExtendedResourcesValidation(t *testing.T) {
+	base := map[string]float64{"cpu": 0.5, "memory": 0.5}
+	testCases := []struct {
+		name      string
+		resources map[string]string
+		wantErr   bool
+	}{
+		{name: "合法扩展资源名", resources: map[string]string{"nvidia.com/gpu": "q"}},
+		{name: "无前缀资源名合法", resources: map[string]string{"hugepages-2Mi": "q"}},
+		{name: "非法字符", resources: map[string]string{"nvidia com/gpu": "q"}, wantErr: true},
+		{name: "多级斜杠", resources: map[string]string{"a/b/c": "q"}, wantErr: true},
+		{name: "空模板", resources: map[string]string{"example.com/nvme": "  "}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := &DynamicWeightArgs{
+				DefaultWeights:    base,
+				ExtendedResources: tc.resources,
+			}
+			err := args.Validate()
+			if tc.wantErr && err == nil {
+				t.Error("期望校验失败，实际通过")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("期望校验通过，实际失败: %v", err)
+			}
+		})
+	}
+}
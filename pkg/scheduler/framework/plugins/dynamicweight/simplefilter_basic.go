@@ -1,11 +1,13 @@
-//package dynamicweight
+package dynamicweight
 
 import (
 	"context"
 	"fmt"
+
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/names"
 )
 
 //context：用于传递上下文信息（如超时控制）
@@ -15,16 +17,29 @@ import (
 
 const (
 	// 定义插件名称常量
-	Name = "DynamicWeightPodFilter"
+	PodFilterName = names.DynamicWeightPodFilter
 )
 
 // 定义插件结构体，用于保存插件状态
 type DynamicWeightPodFilter struct {
 	handle framework.Handle
+	config *DynamicWeightArgs
 	//Go结构体：
 	//使用type 结构体名 struct语法
 	//可以包含字段和方法
-	//这里包含handle字段用于访问调度器API
+	//这里包含handle字段用于访问调度器API，config字段保存插件配置
+}
+
+var _ framework.FilterPlugin = &DynamicWeightPodFilter{} // 实现过滤插件接口
+
+// 未配置FilterRules时使用的内置规则：
+// 带cpu-prefer标签的Pod只能调度到开启RDMA的节点（保持插件引入时的默认行为）
+var defaultFilterRules = []PodFilterRule{
+	{
+		PodLabel:  "cpu-prefer",
+		NodeLabel: "node.kubernetes.io/rdma-enabled",
+		NodeValue: "true",
+	},
 }
 
 // 构造函数。插件入口函数，用于创建插件实例
@@ -34,17 +49,17 @@ type DynamicWeightPodFilter struct {
 //	func New(参数列表) (返回类型1, 返回类型2) {
 //	   return 实例, 错误状态
 //	}
-func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
-	// 类型断言检查配置
-	config, ok := obj.(*Config)
-	if !ok {
-		return nil, fmt.Errorf("invalid configuration")
+func NewPodFilter(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	// 解析插件配置（复用DynamicWeightArgs；无配置时使用默认值）
+	config, err := NewDynamicWeightArgs(obj)
+	if err != nil {
+		return nil, err
 	}
 
 	// 使用配置
 	return &DynamicWeightPodFilter{handle: handle, config: config}, nil
-	//参数列表(obj runtime.Object, handle framework.Handle)
-	//语法：(参数名 参数类型, 参数名 参数类型)；两个参数是Kubernetes调度框架规定的构造函数格式
+	//参数列表(ctx context.Context, obj runtime.Object, handle framework.Handle)
+	//语法：(参数名 参数类型, 参数名 参数类型)；参数是Kubernetes调度框架规定的构造函数格式
 	//obj runtime.Object：接收插件配置对象（可用于读取配置文件）
 	//handle framework.Handle：调度器框架句柄（提供API访问能力）
 
@@ -74,14 +89,25 @@ func New(obj runtime.Object, handle framework.Handle) (framework.Plugin, error)
 // 3.方法内部检查： if pod有cpu-prefer标签 && 节点无RDMA标签 → 返回Unschedulable ；else → 返回Success
 // 4.调度器根据返回值决定是否排除该节点
 func (f *DynamicWeightPodFilter) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
-	// 检查 Pod 是否有 cpu-prefer 标签
-	if _, exists := pod.Labels["cpu-prefer"]; !exists {
-		return framework.NewStatus(framework.Success) // 非目标Pod直接通过
+	// 规则来源：插件配置的FilterRules，未配置时退回内置的cpu-prefer/RDMA规则
+	rules := defaultFilterRules
+	if f.config != nil && len(f.config.FilterRules) > 0 {
+		rules = f.config.FilterRules
 	}
 
-	// 检查节点是否带有 RDMA 标签
-	if nodeValue, nodeExists := nodeInfo.Node().Labels["node.kubernetes.io/rdma-enabled"]; !nodeValue || nodeExists != "true" {
-		return framework.NewStatus(framework.Unschedulable, "Node does not support RDMA")
+	// 逐条评估规则：Pod命中多条规则时节点必须同时满足全部要求
+	for _, rule := range rules {
+		// 检查 Pod 是否命中本条规则的触发标签
+		podValue, exists := pod.Labels[rule.PodLabel]
+		if !exists || (rule.PodValue != "" && podValue != rule.PodValue) {
+			continue // 非目标Pod直接通过
+		}
+
+		// 检查节点是否带有规则要求的标签：标签缺失或取值不符都拒绝
+		if nodeValue, nodeExists := nodeInfo.Node().Labels[rule.NodeLabel]; !nodeExists || nodeValue != rule.NodeValue {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("Node does not have required label %s=%s", rule.NodeLabel, rule.NodeValue))
+		}
 	}
 
 	return framework.NewStatus(framework.Success)
@@ -102,16 +128,16 @@ func (f *DynamicWeightPodFilter) Filter(ctx context.Context, state *framework.Cy
 
 // Name 返回插件名称，必须与注册名称一致
 func (f *DynamicWeightPodFilter) Name() string {
-	return Name
+	return PodFilterName
 }
 
 //五、结合代码的完整执行流程
 
 //1.调度器启动时：
-//加载插件时调用New函数
+//加载插件时调用NewPodFilter函数
 //
 //2.参数传递：
-//obj：从配置文件解析的插件参数（本例未使用）
+//obj：从配置文件解析的插件参数
 //handle：调度框架自动注入的API访问句柄
 //
 //3.创建实例：
@@ -121,79 +147,3 @@ func (f *DynamicWeightPodFilter) Name() string {
 //4.返回结果：
 //返回结构体指针（作为framework.Plugin接口的实现）
 //返回nil表示没有错误发生
-
-//七、参数对象详解
-//1. context.Context （select语句 并发控制，响应外部事件（取消/超时））
-//ctx context.Context
-//作用：传递截止时间、取消信号、请求范围值
-//
-//典型用法：
-//select {
-//case <-ctx.Done():
-//    return framework.NewStatus(framework.Error, ctx.Err().Error())
-//default:
-//    // 正常处理
-//}
-
-//2. *framework.CycleState（状态管理，跨阶段数据持久化，PreFilter-Filter-Score 间的数据传递）
-//state *framework.CycleState
-//作用：存储当前调度周期的临时数据
-//典型用法：
-//// 写入数据
-//state.Write("key", data)
-//
-//// 读取数据
-//data, err := state.Read("key")
-//注：典型使用场景
-//// 在 PreFilter 阶段存储数据
-//func (p *Plugin) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) *framework.Status {
-//    analysisData := analyzePod(pod)
-//    if err := state.Write("pod-analysis", analysisData); err != nil {
-//        return framework.AsStatus(err)
-//    }
-//    return nil
-//}
-//
-//// 在 Filter 阶段读取数据
-//func (p *Plugin) Filter(ctx context.Context, state *framework.CleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
-//    data, err := state.Read("pod-analysis")
-//    if err != nil {
-//        return framework.AsStatus(err)
-//    }
-//    analysis := data.(*AnalysisData) // 类型断言
-//    // 使用分析数据进行过滤...
-//}
-
-//3. *v1.Pod
-//pod *v1.Pod
-//K8s Pod对象结构：
-//type Pod struct {
-//    metav1.TypeMeta
-//    metav1.ObjectMeta // 包含Labels字段
-//    Spec   PodSpec
-//    Status PodStatus
-//}
-//4. *framework.NodeInfo
-//nodeInfo *framework.NodeInfo
-//重要方法：
-//node := nodeInfo.Node()       // 获取v1.Node对象
-//allocatable := nodeInfo.Allocatable() // 节点可用资源
-//used := nodeInfo.UsedPorts()  // 已用端口
-
-///如何访问Pod的标签？
-//获取指定标签
-//value, exists := pod.Labels["cpu-prefer"]
-//
-// 遍历所有标签
-//for key, value := range pod.Labels {
-//    fmt.Printf("%s=%s\n", key, value)
-//}
-
-//type GPUFilter struct{}
-//
-//func (g *GPUFilter) Filter(ctx context.Context, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
-//    if nodeInfo.Node().Allocatable.Gpu() < pod.RequestGpu() {
-//        return framework.NewStatus(framework.Unschedulable, "Insufficient GPU")
-//    }
-//    return framework.NewStatus(framework.Success)
-//}
@@ -0,0 +1,204 @@
+// pkg/scheduler/framework/plugins/dynamicweight/simplefilter_basic_test.go
+package dynamicweight
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// 构造带标签的NodeInfo测试辅助函数
+func newLabeledNodeInfo(labels map[string]string) *framework.NodeInfo {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: labels}}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(node)
+	return nodeInfo
+}
+
+// 验证cpu-prefer标签Pod与RDMA节点标签的过滤组合
+func TestPodFilterRDMA(t *testing.T) {
+	tests := []struct {
+		name       string
+		podLabels  map[string]string
+		nodeLabels map[string]string
+		wantCode   framework.Code
+	}{
+		{
+			name:     "无标签Pod在普通节点上放行",
+			wantCode: framework.Success,
+		},
+		{
+			name:       "无标签Pod在RDMA节点上放行",
+			nodeLabels: map[string]string{"node.kubernetes.io/rdma-enabled": "true"},
+			wantCode:   framework.Success,
+		},
+		{
+			name:       "cpu-prefer标签Pod在RDMA节点上放行",
+			podLabels:  map[string]string{"cpu-prefer": "true"},
+			nodeLabels: map[string]string{"node.kubernetes.io/rdma-enabled": "true"},
+			wantCode:   framework.Success,
+		},
+		{
+			name:      "cpu-prefer标签Pod在无RDMA标签节点上拒绝",
+			podLabels: map[string]string{"cpu-prefer": "true"},
+			wantCode:  framework.Unschedulable,
+		},
+	}
+
+	f := &DynamicWeightPodFilter{config: &DynamicWeightArgs{}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Labels: tt.podLabels}}
+			status := f.Filter(context.Background(), framework.NewCycleState(), pod, newLabeledNodeInfo(tt.nodeLabels))
+			if status.Code() != tt.wantCode {
+				t.Errorf("Filter状态 = %v, 期望 %v", status.Code(), tt.wantCode)
+			}
+		})
+	}
+}
+
+// 验证工厂函数在无配置时也能创建插件实例
+func TestNewPodFilter(t *testing.T) {
+	p, err := NewPodFilter(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("NewPodFilter失败: %v", err)
+	}
+	if p.Name() != PodFilterName {
+		t.Errorf("Name() = %s, 期望 %s", p.Name(), PodFilterName)
+	}
+}
+
+// 验证配置驱动的多规则评估：Pod命中多条规则时节点必须全部满足
+func TestPodFilterConfiguredRules(t *testing.T) {
+	rules := []PodFilterRule{
+		{PodLabel: "gpu-workload", PodValue: "true", NodeLabel: "accelerator", NodeValue: "nvidia"},
+		{PodLabel: "storage-tier", NodeLabel: "disk-type", NodeValue: "ssd"},
+	}
+	f := &DynamicWeightPodFilter{config: &DynamicWeightArgs{FilterRules: rules}}
+
+	tests := []struct {
+		name       string
+		podLabels  map[string]string
+		nodeLabels map[string]string
+		wantCode   framework.Code
+	}{
+		{
+			name:     "未命中任何规则的Pod放行",
+			wantCode: framework.Success,
+		},
+		{
+			name:      "PodValue不匹配时规则不触发",
+			podLabels: map[string]string{"gpu-workload": "false"},
+			wantCode:  framework.Success,
+		},
+		{
+			name:       "单条规则满足时放行",
+			podLabels:  map[string]string{"gpu-workload": "true"},
+			nodeLabels: map[string]string{"accelerator": "nvidia"},
+			wantCode:   framework.Success,
+		},
+		{
+			name:      "单条规则不满足时拒绝",
+			podLabels: map[string]string{"gpu-workload": "true"},
+			wantCode:  framework.Unschedulable,
+		},
+		{
+			name: "命中两条规则且节点全部满足时放行",
+			podLabels: map[string]string{
+				"gpu-workload": "true",
+				"storage-tier": "fast",
+			},
+			nodeLabels: map[string]string{
+				"accelerator": "nvidia",
+				"disk-type":   "ssd",
+			},
+			wantCode: framework.Success,
+		},
+		{
+			name: "命中两条规则但节点只满足一条时拒绝",
+			podLabels: map[string]string{
+				"gpu-workload": "true",
+				"storage-tier": "fast",
+			},
+			nodeLabels: map[string]string{"accelerator": "nvidia"},
+			wantCode:   framework.Unschedulable,
+		},
+		{
+			name:       "配置规则后内置RDMA规则不再生效",
+			podLabels:  map[string]string{"cpu-prefer": "true"},
+			nodeLabels: nil,
+			wantCode:   framework.Success,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Labels: tt.podLabels}}
+			status := f.Filter(context.Background(), framework.NewCycleState(), pod, newLabeledNodeInfo(tt.nodeLabels))
+			if status.Code() != tt.wantCode {
+				t.Errorf("Filter状态 = %v, 期望 %v", status.Code(), tt.wantCode)
+			}
+		})
+	}
+}
+
+// 验证过滤规则的必填字段校验
+func TestFilterRulesValidation(t *testing.T) {
+	valid := &DynamicWeightArgs{FilterRules: []PodFilterRule{
+		{PodLabel: "a", NodeLabel: "b", NodeValue: "c"},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("合法的filterRules不应报错: %v", err)
+	}
+	for _, rule := range []PodFilterRule{
+		{NodeLabel: "b"},
+		{PodLabel: "a"},
+	} {
+		args := &DynamicWeightArgs{FilterRules: []PodFilterRule{rule}}
+		if err := args.Validate(); err == nil {
+			t.Errorf("缺少必填字段的规则 %+v 应校验失败", rule)
+		}
+	}
+}
+
+// 验证节点标签的三种状态：缺失/取值为false/取值为true 分别对应 拒绝/拒绝/放行
+func TestPodFilterNodeLabelValue(t *testing.T) {
+	f := &DynamicWeightPodFilter{config: &DynamicWeightArgs{}}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:   "test-pod",
+		Labels: map[string]string{"cpu-prefer": "true"},
+	}}
+
+	tests := []struct {
+		name       string
+		nodeLabels map[string]string
+		wantCode   framework.Code
+	}{
+		{
+			name:     "节点缺少RDMA标签时拒绝",
+			wantCode: framework.Unschedulable,
+		},
+		{
+			name:       "节点RDMA标签为false时拒绝",
+			nodeLabels: map[string]string{"node.kubernetes.io/rdma-enabled": "false"},
+			wantCode:   framework.Unschedulable,
+		},
+		{
+			name:       "节点RDMA标签为true时放行",
+			nodeLabels: map[string]string{"node.kubernetes.io/rdma-enabled": "true"},
+			wantCode:   framework.Success,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := f.Filter(context.Background(), framework.NewCycleState(), pod, newLabeledNodeInfo(tt.nodeLabels))
+			if status.Code() != tt.wantCode {
+				t.Errorf("Filter状态 = %v, 期望 %v", status.Code(), tt.wantCode)
+			}
+		})
+	}
+}
@@ -6,80 +6,192 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	//"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	//corev1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 )
 
+// 默认的权重配置ConfigMap位置，可通过DynamicWeightArgs覆盖
 const (
 	configMapNamespace = "kube-system"
 	configMapName      = "dynamic-weight-config"
 )
 
+// 默认的热更新去抖窗口：窗口内的多次变更合并为一次重载
+const defaultReloadDebounce = time.Second
+
+// 默认的informer全量重同步周期与抖动系数
+// 多副本调度器若按完全相同的周期重同步，重载会在各副本间同步发生；
+// 默认叠加一成抖动把负载错开
+const (
+	defaultResyncPeriod       = 5 * time.Minute
+	defaultResyncJitterFactor = 0.1
+)
+
+// jitteredPeriod 在基准周期上叠加[0, factor×period)的随机抖动
+// period<=0回落默认重同步周期，factor<=0回落默认抖动系数
+func jitteredPeriod(period time.Duration, factor float64) time.Duration {
+	if period <= 0 {
+		period = defaultResyncPeriod
+	}
+	if factor <= 0 {
+		factor = defaultResyncJitterFactor
+	}
+	return wait.Jitter(period, factor)
+}
+
 // 配置加载器接口定义
 // 作用：提供获取最新权重配置的能力
+// 返回的配置在两次重载之间不可变，调用方必须按只读使用，
+// 不做防御性拷贝（500节点的评分周期里逐Pod深拷贝代价过高）
 type WeightLoader interface {
 	GetWeights() *DynamicWeightArgs
+	// Healthy 报告最近一次配置加载/重载是否成功
+	// false说明当前仍在用上一份有效配置，运维应检查配置来源
+	Healthy() bool
+}
+
+// staticWeightLoader 静态配置加载器：直接使用插件args中的配置
+// 适用于通过KubeSchedulerConfiguration的pluginConfig提供权重的场景，
+// 不依赖kube-system中的ConfigMap，也没有热更新
+type staticWeightLoader struct {
+	args *DynamicWeightArgs
+}
+
+func (s *staticWeightLoader) GetWeights() *DynamicWeightArgs {
+	return s.args // 配置不可变，直接返回（只读）
+}
+
+// Healthy 静态配置在构造时已通过校验，恒为健康
+func (s *staticWeightLoader) Healthy() bool {
+	return true
+}
+
+// newWeightLoaderForArgs 根据插件配置选择权重来源
+// WeightSource显式指定后端（configmap/crd）；未指定时按旧规则：
+// 配置了ConfigMapName走ConfigMap加载（带热更新），
+// 否则直接使用插件args中的静态配置，去掉对kube-system的隐式依赖
+func newWeightLoaderForArgs(ctx context.Context, client kubernetes.Interface, dynClient dynamic.Interface, args *DynamicWeightArgs) (WeightLoader, error) {
+	switch args.WeightSource {
+	case weightSourceCRD:
+		return NewCRDWeightLoader(ctx, dynClient, args.PolicyNamespace, args.PolicyName)
+	case weightSourceConfigMap:
+		return NewWeightLoader(ctx, client, args.ConfigMapNamespace, args.ConfigMapName, args.ReloadDebounce.Duration)
+	}
+	if args.ConfigMapName != "" {
+		return NewWeightLoader(ctx, client, args.ConfigMapNamespace, args.ConfigMapName, args.ReloadDebounce.Duration)
+	}
+	return &staticWeightLoader{args: args}, nil
 }
 
 // 配置加载器实现：从ConfigMap读取并监听变更
 type weightLoader struct {
-	client kubernetes.Interface // Kubernetes API客户端
-	args   *DynamicWeightArgs   // 当前生效的配置
-	lock   sync.RWMutex         // 读写锁（保障线程安全）
+	client    kubernetes.Interface // Kubernetes API客户端
+	namespace string               // 配置ConfigMap所在命名空间
+	name      string               // 配置ConfigMap名称
+	// 当前生效的配置：重载时整体原子替换，读取方拿到的指针指向不可变内容
+	args     atomic.Pointer[DynamicWeightArgs]
+	debounce time.Duration // 热更新去抖窗口
+	// informer同步完成后的本地缓存读取器；缓存未就绪时为nil，直接读API
+	listerMu sync.RWMutex
+	cmLister corelisters.ConfigMapNamespaceLister
+	// 去抖定时器：变更风暴期间反复重置，窗口静默后才真正重载
+	timerMu     sync.Mutex
+	reloadTimer *time.Timer
+	// 最近一次加载/重载是否成功（供健康检查与告警仪表读取）
+	healthy atomic.Bool
 	//controller cache.Controller
 }
 
 // 创建配置加载器实例
-func NewWeightLoader(client kubernetes.Interface) (WeightLoader, error) {
+// namespace/name指定权重配置ConfigMap的位置，为空时使用默认值
+// ctx由插件生命周期持有，取消时后台监听协程随之退出
+// debounce为热更新去抖窗口，<=0时使用默认值
+func NewWeightLoader(ctx context.Context, client kubernetes.Interface, namespace, name string, debounce time.Duration) (WeightLoader, error) {
+	if namespace == "" {
+		namespace = configMapNamespace
+	}
+	if name == "" {
+		name = configMapName
+	}
+	if debounce <= 0 {
+		debounce = defaultReloadDebounce
+	}
 	wl := &weightLoader{
-		client: client,
-		args:   &DynamicWeightArgs{},
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		debounce:  debounce,
 	}
+	wl.args.Store(&DynamicWeightArgs{})
 
-	// 初始加载配置
+	// 初始加载配置：只有首次加载失败才视为致命错误
+	// 之后的热更新失败保留上一份有效配置（见startInformer）
 	if err := wl.loadConfig(); err != nil {
 		return nil, fmt.Errorf("failed to load initial config: %v", err)
 	}
 
 	// 启动后台监听协程
-	go wl.startInformer()
+	go wl.startInformer(ctx)
 
 	return wl, nil
 }
 
-// 获取当前配置（线程安全）
+// 获取当前配置（线程安全，只读）
+// 配置在两次重载之间不可变：直接返回原子指针，省去逐调用的深拷贝
 func (wl *weightLoader) GetWeights() *DynamicWeightArgs {
-	wl.lock.RLock()           // 加读锁（允许多个读取）
-	defer wl.lock.RUnlock()   // 函数结束时释放锁
-	return wl.args.DeepCopy() // 返回配置副本
+	return wl.args.Load()
 }
 
-// 加载配置的完整流程
-func (wl *weightLoader) loadConfig() error {
-	// 从Kubernetes API获取ConfigMap
-	cm, err := wl.client.CoreV1().ConfigMaps(configMapNamespace).Get(
+// getConfigMap 获取权重ConfigMap
+// informer缓存同步后走本地lister，降低apiserver压力；
+// 仅首次加载（缓存未就绪）时直接请求API
+func (wl *weightLoader) getConfigMap() (*v1.ConfigMap, error) {
+	wl.listerMu.RLock()
+	lister := wl.cmLister
+	wl.listerMu.RUnlock()
+
+	if lister != nil {
+		return lister.Get(wl.name)
+	}
+	return wl.client.CoreV1().ConfigMaps(wl.namespace).Get(
 		context.Background(),
-		configMapName,
+		wl.name,
 		metav1.GetOptions{},
 	)
+}
+
+// setLister 记录informer同步完成后的本地缓存读取器
+func (wl *weightLoader) setLister(lister corelisters.ConfigMapNamespaceLister) {
+	wl.listerMu.Lock()
+	defer wl.listerMu.Unlock()
+	wl.cmLister = lister
+}
+
+// 加载配置的完整流程
+func (wl *weightLoader) loadConfig() error {
+	cm, err := wl.getConfigMap()
 	if err != nil {
 		return fmt.Errorf("error fetching ConfigMap %s/%s: %v",
-			configMapNamespace, configMapName, err)
+			wl.namespace, wl.name, err)
 	}
 
 	// 解析JSON配置数据
 	configData, ok := cm.Data["config.json"]
 	if !ok {
 		return fmt.Errorf("ConfigMap %s/%s missing 'config.json' key",
-			configMapNamespace, configMapName)
+			wl.namespace, wl.name)
 	}
 
 	newArgs := &DynamicWeightArgs{}
@@ -87,22 +199,36 @@ func (wl *weightLoader) loadConfig() error {
 		return fmt.Errorf("error unmarshaling config: %v", err)
 	}
 
-	// 更新配置（加写锁）
-	wl.lock.Lock()
-	defer wl.lock.Unlock()
-	wl.args = newArgs
+	if err := newArgs.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	// 原子替换整份配置：旧指针的持有者继续读旧内容，无需加锁
+	wl.args.Store(newArgs)
+	wl.healthy.Store(true)
+	configHealthy.Set(1)
+	// 记录配置代次与生效时刻，供运维确认ConfigMap编辑已传播
+	configGeneration.Inc()
+	configLastReload.SetToCurrentTime()
 	return nil
 }
 
+// Healthy 报告最近一次配置加载/重载是否成功
+func (wl *weightLoader) Healthy() bool {
+	return wl.healthy.Load()
+}
+
 // 启动监听ConfigMap变更的后台协程
-func (wl *weightLoader) startInformer() {
+// ctx取消（插件关闭）时informer停止、本协程退出，不再泄漏
+func (wl *weightLoader) startInformer(ctx context.Context) {
 	// 创建Kubernetes Informer（监听指定ConfigMap）
+	args := wl.GetWeights()
 	factory := informers.NewSharedInformerFactoryWithOptions(
 		wl.client,
-		5*time.Minute, // 每5分钟全量同步一次
-		informers.WithNamespace(configMapNamespace),
+		jitteredPeriod(args.ResyncPeriod.Duration, args.ResyncJitterFactor),
+		informers.WithNamespace(wl.namespace),
 		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
-			options.FieldSelector = "metadata.name=" + configMapName
+			options.FieldSelector = "metadata.name=" + wl.name
 		}),
 	)
 
@@ -116,27 +242,105 @@ func (wl *weightLoader) startInformer() {
 			if oldCM.ResourceVersion == newCM.ResourceVersion {
 				return
 			}
-			klog.InfoS("ConfigMap updated, reloading weights")
-			if err := wl.loadConfig(); err != nil {
-				klog.ErrorS(err, "Failed to reload config")
-			}
+			// 去抖合并：编辑风暴/全量重同步期间只触发一次重载
+			// 重载时重新读取最新的ConfigMap，最终状态总是生效
+			wl.scheduleReload(ctx)
 		},
 	})
 
-	// 启动监听
-	stopCh := make(chan struct{})
-	defer close(stopCh)
-	factory.Start(stopCh)
-	factory.WaitForCacheSync(stopCh)
+	// 启动监听：informer的生命周期挂在插件ctx上
+	factory.Start(ctx.Done())
+	synced := factory.WaitForCacheSync(ctx.Done())
+
+	// 缓存同步完成后，后续重载改走本地lister，不再逐次请求API
+	allSynced := true
+	for _, ok := range synced {
+		allSynced = allSynced && ok
+	}
+	if allSynced {
+		wl.setLister(cmInformer.Lister().ConfigMaps(wl.namespace))
+	}
+
+	<-ctx.Done() // 阻塞直到插件关闭
+
+	// 关闭时取消未触发的去抖定时器
+	wl.timerMu.Lock()
+	if wl.reloadTimer != nil {
+		wl.reloadTimer.Stop()
+	}
+	wl.timerMu.Unlock()
+}
+
+// scheduleReload 按去抖窗口合并重载请求
+// 窗口内再次调用会重置定时器，静默满一个窗口后才执行真正的重载
+func (wl *weightLoader) scheduleReload(ctx context.Context) {
+	wl.timerMu.Lock()
+	defer wl.timerMu.Unlock()
 
-	<-stopCh // 阻塞直到进程退出
+	if wl.reloadTimer != nil {
+		wl.reloadTimer.Stop()
+	}
+	wl.reloadTimer = time.AfterFunc(wl.debounce, func() {
+		if ctx.Err() != nil {
+			return // 插件已关闭，放弃重载
+		}
+		klog.InfoS("ConfigMap updated, reloading weights")
+		if err := wl.loadConfig(); err != nil {
+			// 热更新失败不影响运行：loadConfig未通过校验不会替换wl.args，
+			// 继续使用上一份有效配置，仅记录失败指标供告警
+			configReloadFailures.Inc()
+			wl.healthy.Store(false)
+			configHealthy.Set(0)
+			klog.ErrorS(err, "Failed to reload config, keeping last-good config")
+		}
+	})
 }
 
 // DeepCopy 用于线程安全获取配置副本
 func (args *DynamicWeightArgs) DeepCopy() *DynamicWeightArgs {
 	newArgs := &DynamicWeightArgs{
-		DefaultWeights: make(map[string]float64),
-		LabelWeights:   make(map[string]map[string]float64),
+		TypeMeta:                args.TypeMeta,
+		DefaultWeights:          make(map[string]float64),
+		LabelWeights:            make(map[string]map[string]float64),
+		DiskDevice:              args.DiskDevice,
+		RateWindow:              args.RateWindow,
+		RefreshInterval:         args.RefreshInterval,
+		NetworkInterface:        args.NetworkInterface,
+		QueryTimeout:            args.QueryTimeout,
+		WarmupPeriod:            args.WarmupPeriod,
+		MetricsServerFallback:   args.MetricsServerFallback,
+		ExplainScores:           args.ExplainScores,
+		ScoreQuantization:       args.ScoreQuantization,
+		SmoothingEnabled:        args.SmoothingEnabled,
+		SmoothingWindow:         args.SmoothingWindow,
+		QueryMaxAttempts:        args.QueryMaxAttempts,
+		QueryRetryBaseDelay:     args.QueryRetryBaseDelay,
+		ReloadDebounce:          args.ReloadDebounce,
+		CacheTTL:                args.CacheTTL,
+		StalenessDecay:          args.StalenessDecay,
+		MaxDataAge:              args.MaxDataAge,
+		ResyncPeriod:            args.ResyncPeriod,
+		ResyncJitterFactor:      args.ResyncJitterFactor,
+		DebugAddress:            args.DebugAddress,
+		StrictLabelValidation:   args.StrictLabelValidation,
+		ObserveOnly:             args.ObserveOnly,
+		NeutralScore:            args.NeutralScore,
+		PredictedUsageThreshold: args.PredictedUsageThreshold,
+		InstanceMatcher:         args.InstanceMatcher,
+		InstanceLabelSource:     args.InstanceLabelSource,
+		ScoringCurve:            args.ScoringCurve,
+		Strategy:                args.Strategy,
+		WeightSource:            args.WeightSource,
+		PolicyNamespace:         args.PolicyNamespace,
+		PolicyName:              args.PolicyName,
+		ConfigMapNamespace:      args.ConfigMapNamespace,
+		ConfigMapName:           args.ConfigMapName,
+		PrometheusAddress:       args.PrometheusAddress,
+		TenantHeader:            args.TenantHeader,
+		CAFile:                  args.CAFile,
+		InsecureSkipVerify:      args.InsecureSkipVerify,
+		ClientCertFile:          args.ClientCertFile,
+		ClientKeyFile:           args.ClientKeyFile,
 	}
 
 	for k, v := range args.DefaultWeights {
@@ -151,5 +355,105 @@ func (args *DynamicWeightArgs) DeepCopy() *DynamicWeightArgs {
 		newArgs.LabelWeights[label] = newWeights
 	}
 
+	newArgs.ExcludeNodeSelector = args.ExcludeNodeSelector.DeepCopy()
+
+	if args.PackingBias != nil {
+		bias := *args.PackingBias
+		newArgs.PackingBias = &bias
+	}
+
+	if args.ScheduledWeights != nil {
+		newArgs.ScheduledWeights = make([]ScheduledWeight, len(args.ScheduledWeights))
+		for i, sw := range args.ScheduledWeights {
+			copied := sw
+			if sw.Days != nil {
+				copied.Days = make([]int, len(sw.Days))
+				copy(copied.Days, sw.Days)
+			}
+			if sw.Weights != nil {
+				copied.Weights = make(map[string]float64, len(sw.Weights))
+				for res, w := range sw.Weights {
+					copied.Weights[res] = w
+				}
+			}
+			newArgs.ScheduledWeights[i] = copied
+		}
+	}
+
+	if args.NamespaceWeights != nil {
+		newArgs.NamespaceWeights = make(map[string]map[string]float64)
+		for ns, weights := range args.NamespaceWeights {
+			newWeights := make(map[string]float64)
+			for res, w := range weights {
+				newWeights[res] = w
+			}
+			newArgs.NamespaceWeights[ns] = newWeights
+		}
+	}
+
+	if args.MaxUsage != nil {
+		newArgs.MaxUsage = make(map[string]float64)
+		for res, limit := range args.MaxUsage {
+			newArgs.MaxUsage[res] = limit
+		}
+	}
+
+	if args.AddressTypePreference != nil {
+		newArgs.AddressTypePreference = append([]string(nil), args.AddressTypePreference...)
+	}
+	if args.MetricScale != nil {
+		newArgs.MetricScale = make(map[string]float64)
+		for res, scale := range args.MetricScale {
+			newArgs.MetricScale[res] = scale
+		}
+	}
+	if args.ResourceQueryTimeouts != nil {
+		newArgs.ResourceQueryTimeouts = make(map[string]metav1.Duration)
+		for res, timeout := range args.ResourceQueryTimeouts {
+			newArgs.ResourceQueryTimeouts[res] = timeout
+		}
+	}
+	if args.FallbackUsage != nil {
+		newArgs.FallbackUsage = make(map[string]float64)
+		for res, v := range args.FallbackUsage {
+			newArgs.FallbackUsage[res] = v
+		}
+	}
+
+	if args.QueryHeaders != nil {
+		newArgs.QueryHeaders = make(map[string]string)
+		for name, value := range args.QueryHeaders {
+			newArgs.QueryHeaders[name] = value
+		}
+	}
+	if args.ResourceStrategies != nil {
+		newArgs.ResourceStrategies = make(map[string]string)
+		for res, strategy := range args.ResourceStrategies {
+			newArgs.ResourceStrategies[res] = strategy
+		}
+	}
+	if args.FailOpen != nil {
+		failOpen := *args.FailOpen
+		newArgs.FailOpen = &failOpen
+	}
+	if args.FilterRules != nil {
+		newArgs.FilterRules = make([]PodFilterRule, len(args.FilterRules))
+		copy(newArgs.FilterRules, args.FilterRules)
+	}
+
+	if args.QueryTemplates != nil {
+		newArgs.QueryTemplates = make(map[string]string)
+		for res, tpl := range args.QueryTemplates {
+			newArgs.QueryTemplates[res] = tpl
+		}
+	}
+
+	if args.ExtendedResources != nil {
+		newArgs.ExtendedResources = make(map[string]string)
+		for res, tpl := range args.ExtendedResources {
+			newArgs.ExtendedResources[res] = tpl
+		}
+	}
+
 	return newArgs
 }
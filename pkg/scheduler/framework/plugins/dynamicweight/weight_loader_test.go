@@ -0,0 +1,631 @@
+// pkg/scheduler/framework/plugins/dynamicweight/weight_loader_test.go
+package dynamicweight
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// newWeightConfigMap 构造承载权重配置的ConfigMap
+func newWeightConfigMap(configJSON string) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: configMapNamespace,
+			Name:      configMapName,
+		},
+		Data: map[string]string{"config.json": configJSON},
+	}
+}
+
+// 验证可从自定义命名空间/名称的ConfigMap加载配置
+func TestLoadConfigCustomConfigMap(t *testing.T) {
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.5,"memory":0.5}}`)
+	cm.Namespace = "team-a"
+	cm.Name = "team-a-weights"
+	cs := clientsetfake.NewSimpleClientset(cm)
+
+	wl := &weightLoader{
+		client:    cs,
+		namespace: "team-a",
+		name:      "team-a-weights",
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("从自定义ConfigMap加载失败: %v", err)
+	}
+	if got := wl.GetWeights().DefaultWeights["cpu"]; got != 0.5 {
+		t.Errorf("cpu权重 = %v, 期望 0.5", got)
+	}
+}
+
+// 验证热更新为非法配置时保留上一份有效配置
+func TestLoadConfigKeepsLastGood(t *testing.T) {
+	cs := clientsetfake.NewSimpleClientset(
+		newWeightConfigMap(`{"defaultWeights":{"cpu":0.6,"memory":0.4}}`))
+	wl := &weightLoader{
+		client:    cs,
+		namespace: configMapNamespace,
+		name:      configMapName,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("初始加载失败: %v", err)
+	}
+	if got := wl.GetWeights().DefaultWeights["cpu"]; got != 0.6 {
+		t.Fatalf("cpu权重 = %v, 期望 0.6", got)
+	}
+
+	// 场景1：JSON语法错误
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.6,`)
+	if _, err := cs.CoreV1().ConfigMaps(configMapNamespace).Update(
+		context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.loadConfig(); err == nil {
+		t.Error("期望非法JSON加载失败，实际通过")
+	}
+
+	// 场景2：校验不通过（权重和偏离1.0）
+	cm = newWeightConfigMap(`{"defaultWeights":{"cpu":3.0,"memory":2.0}}`)
+	if _, err := cs.CoreV1().ConfigMaps(configMapNamespace).Update(
+		context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.loadConfig(); err == nil {
+		t.Error("期望校验失败，实际通过")
+	}
+
+	// 两次失败后旧配置仍然生效
+	if got := wl.GetWeights().DefaultWeights["cpu"]; got != 0.6 {
+		t.Errorf("cpu权重 = %v, 期望保留上一份有效配置的 0.6", got)
+	}
+}
+
+// 验证成功重载时配置代次递增、生效时间戳前移
+func TestConfigReloadMetrics(t *testing.T) {
+	registerMetrics()
+
+	cs := clientsetfake.NewSimpleClientset(
+		newWeightConfigMap(`{"defaultWeights":{"cpu":0.6,"memory":0.4}}`))
+	wl := &weightLoader{
+		client:    cs,
+		namespace: configMapNamespace,
+		name:      configMapName,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	genBefore, err := testutil.GetGaugeMetricValue(configGeneration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("初始加载失败: %v", err)
+	}
+	gen1, err := testutil.GetGaugeMetricValue(configGeneration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gen1 != genBefore+1 {
+		t.Errorf("config_generation = %v, 期望 %v", gen1, genBefore+1)
+	}
+	ts1, err := testutil.GetGaugeMetricValue(configLastReload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts1 <= 0 {
+		t.Errorf("config_last_reload_timestamp_seconds = %v, 期望为正", ts1)
+	}
+
+	// 第二次重载：代次再加一，时间戳前移
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.7,"memory":0.3}}`)
+	if _, err := cs.CoreV1().ConfigMaps(configMapNamespace).Update(
+		context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond) // 保证时间戳可区分
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("重载失败: %v", err)
+	}
+	gen2, err := testutil.GetGaugeMetricValue(configGeneration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gen2 != gen1+1 {
+		t.Errorf("重载后config_generation = %v, 期望 %v", gen2, gen1+1)
+	}
+	ts2, err := testutil.GetGaugeMetricValue(configLastReload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts2 <= ts1 {
+		t.Errorf("重载后时间戳 %v 未超过首次加载的 %v", ts2, ts1)
+	}
+
+	// 重载失败不应推进代次与时间戳
+	cm = newWeightConfigMap(`{"defaultWeights":{"cpu":0.7,`)
+	if _, err := cs.CoreV1().ConfigMaps(configMapNamespace).Update(
+		context.Background(), cm, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wl.loadConfig(); err == nil {
+		t.Fatal("期望非法JSON加载失败，实际通过")
+	}
+	gen3, err := testutil.GetGaugeMetricValue(configGeneration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gen3 != gen2 {
+		t.Errorf("失败重载后config_generation = %v, 期望保持 %v", gen3, gen2)
+	}
+}
+
+// 验证权重来源选择：未配置ConfigMapName时使用插件args的静态配置
+func TestWeightLoaderForArgsStatic(t *testing.T) {
+	args := &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 0.6, "memory": 0.4},
+	}
+	wl, err := newWeightLoaderForArgs(context.Background(), clientsetfake.NewSimpleClientset(), nil, args)
+	if err != nil {
+		t.Fatalf("newWeightLoaderForArgs失败: %v", err)
+	}
+	if _, ok := wl.(*staticWeightLoader); !ok {
+		t.Fatalf("加载器类型 = %T, 期望静态加载器", wl)
+	}
+
+	got := wl.GetWeights()
+	if got.DefaultWeights["cpu"] != 0.6 {
+		t.Errorf("DefaultWeights = %v, 期望来自插件args", got.DefaultWeights)
+	}
+}
+
+// 验证显式配置ConfigMapName时仍走ConfigMap加载路径
+func TestWeightLoaderForArgsConfigMap(t *testing.T) {
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.3,"memory":0.7}}`)
+	cm.Name = "my-weights"
+	args := &DynamicWeightArgs{
+		ConfigMapName:  "my-weights",
+		DefaultWeights: map[string]float64{"cpu": 0.6, "memory": 0.4},
+	}
+	wl, err := newWeightLoaderForArgs(context.Background(), clientsetfake.NewSimpleClientset(cm), nil, args)
+	if err != nil {
+		t.Fatalf("newWeightLoaderForArgs失败: %v", err)
+	}
+	if wl.GetWeights().DefaultWeights["cpu"] != 0.3 {
+		t.Errorf("DefaultWeights = %v, 期望来自ConfigMap", wl.GetWeights().DefaultWeights)
+	}
+}
+
+// newWeightPolicy 构造DynamicWeightPolicy自定义资源测试对象
+func newWeightPolicy(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "scheduling.dynamicweight.io/v1alpha1",
+		"kind":       "DynamicWeightPolicy",
+		"metadata": map[string]interface{}{
+			"namespace": policyNamespace,
+			"name":      name,
+		},
+		"spec": spec,
+	}}
+}
+
+// newFakeDynamicClient 构造注册了策略资源的动态客户端桩
+func newFakeDynamicClient(objects ...runtime.Object) dynamic.Interface {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			dynamicWeightPolicyGVR: "DynamicWeightPolicyList",
+		},
+		objects...,
+	)
+}
+
+// 验证从自定义资源的结构化spec读取权重配置
+func TestCRDWeightLoader(t *testing.T) {
+	policy := newWeightPolicy(policyName, map[string]interface{}{
+		"defaultWeights": map[string]interface{}{"cpu": 0.4, "memory": 0.6},
+		"diskDevice":     "nvme.*",
+	})
+	wl, err := NewCRDWeightLoader(context.Background(), newFakeDynamicClient(policy), "", "")
+	if err != nil {
+		t.Fatalf("NewCRDWeightLoader失败: %v", err)
+	}
+
+	got := wl.GetWeights()
+	if got.DefaultWeights["cpu"] != 0.4 {
+		t.Errorf("DefaultWeights = %v, 期望来自策略spec", got.DefaultWeights)
+	}
+	if got.DiskDevice != "nvme.*" {
+		t.Errorf("DiskDevice = %s, 期望 nvme.*", got.DiskDevice)
+	}
+}
+
+// 验证spec未通过共享校验逻辑时初始化失败
+func TestCRDWeightLoaderInvalidSpec(t *testing.T) {
+	policy := newWeightPolicy(policyName, map[string]interface{}{
+		"defaultWeights": map[string]interface{}{"cpu": 0.4, "memory": 0.4}, // 权重和≠1.0
+	})
+	if _, err := NewCRDWeightLoader(context.Background(), newFakeDynamicClient(policy), "", ""); err == nil {
+		t.Error("非法的策略spec应使初始化失败")
+	}
+}
+
+// 验证WeightSource为crd时选择自定义资源后端
+func TestWeightLoaderForArgsCRD(t *testing.T) {
+	policy := newWeightPolicy(policyName, map[string]interface{}{
+		"defaultWeights": map[string]interface{}{"cpu": 0.7, "memory": 0.3},
+	})
+	args := &DynamicWeightArgs{WeightSource: "crd"}
+	wl, err := newWeightLoaderForArgs(context.Background(), clientsetfake.NewSimpleClientset(), newFakeDynamicClient(policy), args)
+	if err != nil {
+		t.Fatalf("newWeightLoaderForArgs失败: %v", err)
+	}
+	if _, ok := wl.(*crdWeightLoader); !ok {
+		t.Fatalf("加载器类型 = %T, 期望CRD加载器", wl)
+	}
+	if wl.GetWeights().DefaultWeights["cpu"] != 0.7 {
+		t.Errorf("DefaultWeights = %v, 期望来自自定义资源", wl.GetWeights().DefaultWeights)
+	}
+}
+
+// 验证ctx取消后监听协程退出（插件关闭时不泄漏协程）
+func TestStartInformerStopsOnContextCancel(t *testing.T) {
+	wl := &weightLoader{
+		client:    clientsetfake.NewSimpleClientset(newWeightConfigMap(`{}`)),
+		namespace: configMapNamespace,
+		name:      configMapName,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		wl.startInformer(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx取消后startInformer协程未退出")
+	}
+}
+
+// 验证去抖窗口内的多次变更只触发一次重载，且最终状态生效
+func TestReloadDebounce(t *testing.T) {
+	cs := clientsetfake.NewSimpleClientset(
+		newWeightConfigMap(`{"defaultWeights":{"cpu":0.3,"memory":0.7}}`))
+	wl := &weightLoader{
+		client:    cs,
+		namespace: configMapNamespace,
+		name:      configMapName,
+		debounce:  50 * time.Millisecond,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	// 短时间内触发多次变更
+	for i := 0; i < 5; i++ {
+		wl.scheduleReload(context.Background())
+	}
+
+	// 等待去抖窗口静默后重载执行
+	deadline := time.Now().Add(time.Second)
+	for wl.GetWeights().DefaultWeights["cpu"] != 0.3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := wl.GetWeights().DefaultWeights["cpu"]; got != 0.3 {
+		t.Fatalf("cpu权重 = %v, 期望重载后为 0.3", got)
+	}
+
+	// 合并后只应发生一次ConfigMap读取
+	var gets int
+	for _, action := range cs.Actions() {
+		if action.Matches("get", "configmaps") {
+			gets++
+		}
+	}
+	if gets != 1 {
+		t.Errorf("ConfigMap读取次数 = %d, 期望去抖合并为 1 次", gets)
+	}
+
+	// 已取消的ctx不再触发重载
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	wl.scheduleReload(ctx)
+	time.Sleep(100 * time.Millisecond)
+	var getsAfter int
+	for _, action := range cs.Actions() {
+		if action.Matches("get", "configmaps") {
+			getsAfter++
+		}
+	}
+	if getsAfter != gets {
+		t.Errorf("插件关闭后仍触发了重载: %d -> %d", gets, getsAfter)
+	}
+}
+
+// 验证informer缓存就绪后loadConfig走本地lister，不再请求API
+func TestLoadConfigUsesListerAfterSync(t *testing.T) {
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.8,"memory":0.2}}`)
+	cs := clientsetfake.NewSimpleClientset() // API端故意不放ConfigMap
+	wl := &weightLoader{
+		client:    cs,
+		namespace: configMapNamespace,
+		name:      configMapName,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	// 模拟informer同步完成：lister后端的本地缓存中已有ConfigMap
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(cm); err != nil {
+		t.Fatalf("填充本地缓存失败: %v", err)
+	}
+	wl.setLister(listersv1.NewConfigMapLister(indexer).ConfigMaps(configMapNamespace))
+
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("loadConfig失败: %v", err)
+	}
+	if got := wl.GetWeights().DefaultWeights["cpu"]; got != 0.8 {
+		t.Errorf("cpu权重 = %v, 期望来自lister的 0.8", got)
+	}
+	// 全程不应有对API的ConfigMap读取
+	for _, action := range cs.Actions() {
+		if action.Matches("get", "configmaps") {
+			t.Error("缓存就绪后不应直接请求API")
+		}
+	}
+}
+
+// 基准对比：原子指针直接返回 vs 旧的逐调用深拷贝
+func newBenchWeightArgs() *DynamicWeightArgs {
+	return &DynamicWeightArgs{
+		DefaultWeights: map[string]float64{"cpu": 0.25, "memory": 0.25, "diskio": 0.25, "netio": 0.25},
+		LabelWeights: map[string]map[string]float64{
+			"cpu-prefer": {"cpu": 0.7, "memory": 0.1, "diskio": 0.1, "netio": 0.1},
+			"mem-prefer": {"cpu": 0.1, "memory": 0.7, "diskio": 0.1, "netio": 0.1},
+		},
+		NamespaceWeights: map[string]map[string]float64{
+			"team-a": {"cpu": 0.5, "memory": 0.5},
+		},
+	}
+}
+
+func BenchmarkGetWeights(b *testing.B) {
+	wl := &staticWeightLoader{args: newBenchWeightArgs()}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = wl.GetWeights()
+	}
+}
+
+func BenchmarkGetWeightsDeepCopy(b *testing.B) {
+	wl := &staticWeightLoader{args: newBenchWeightArgs()}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = wl.GetWeights().DeepCopy()
+	}
+}
+
+// 验证健康状态随重载成败翻转
+func TestWeightLoaderHealthy(t *testing.T) {
+	cm := newWeightConfigMap(`{"defaultWeights":{"cpu":0.5,"memory":0.5}}`)
+	client := clientsetfake.NewSimpleClientset(cm)
+	wl := &weightLoader{
+		client:    client,
+		namespace: cm.Namespace,
+		name:      cm.Name,
+	}
+	wl.args.Store(&DynamicWeightArgs{})
+
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("loadConfig失败: %v", err)
+	}
+	if !wl.Healthy() {
+		t.Error("加载成功后Healthy()应为true")
+	}
+
+	// 模拟热更新失败路径：坏配置不替换，健康状态翻转为false
+	wl.healthy.Store(false)
+	if wl.Healthy() {
+		t.Error("重载失败后Healthy()应为false")
+	}
+
+	// 配置修好后下一次成功加载恢复健康
+	if err := wl.loadConfig(); err != nil {
+		t.Fatalf("loadConfig失败: %v", err)
+	}
+	if !wl.Healthy() {
+		t.Error("重新加载成功后Healthy()应恢复true")
+	}
+}
+
+// 验证手写DeepCopy覆盖全部字段且与原对象完全独立
+// 通过反射兜底：新增字段忘记在DeepCopy中补拷贝时本测试直接失败，
+// 防止配置热更新场景下新旧配置静默共享底层数据
+func TestDeepCopyCoversAllFields(t *testing.T) {
+	failOpen := true
+	packingBias := 0.5
+	original := &DynamicWeightArgs{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "kubescheduler.config.k8s.io/v1",
+			Kind:       "DynamicWeightArgs",
+		},
+		DefaultWeights:          map[string]float64{"cpu": 0.5, "memory": 0.5},
+		LabelWeights:            map[string]map[string]float64{"cpu-prefer": {"cpu": 0.7, "memory": 0.3}},
+		NamespaceWeights:        map[string]map[string]float64{"team-a": {"cpu": 0.6, "memory": 0.4}},
+		ScheduledWeights:        []ScheduledWeight{{StartHour: 9, EndHour: 18, Days: []int{1}, TimeZone: "Asia/Shanghai", Weights: map[string]float64{"cpu": 1.0}}},
+		DiskDevice:              "nvme.*",
+		NetworkInterface:        "ens.*",
+		RefreshInterval:         metav1.Duration{Duration: 15 * time.Second},
+		RateWindow:              metav1.Duration{Duration: 2 * time.Minute},
+		FallbackUsage:           map[string]float64{"diskio": 0.5},
+		MaxUsage:                map[string]float64{"cpu": 0.95},
+		QueryTimeout:            metav1.Duration{Duration: 3 * time.Second},
+		WarmupPeriod:            metav1.Duration{Duration: 2 * time.Minute},
+		MetricsServerFallback:   true,
+		ExplainScores:           true,
+		ScoreQuantization:       5,
+		AddressTypePreference:   []string{"InternalIP", "ExternalIP"},
+		ResourceQueryTimeouts:   map[string]metav1.Duration{"diskio": {Duration: 2 * time.Second}},
+		SmoothingEnabled:        true,
+		SmoothingWindow:         metav1.Duration{Duration: 5 * time.Minute},
+		QueryMaxAttempts:        3,
+		QueryRetryBaseDelay:     metav1.Duration{Duration: 100 * time.Millisecond},
+		QueryTemplates:          map[string]string{"cpu": "custom_cpu{instance=~\"{instance}\"}"},
+		ExtendedResources:       map[string]string{"nvidia.com/gpu": "avg(DCGM_FI_DEV_GPU_UTIL{instance=~\"{instance}\"}) / 100"},
+		InstanceMatcher:         `node="{value}"`,
+		InstanceLabelSource:     instanceSourceHostname,
+		ScoringCurve:            scoringCurveExponential,
+		Strategy:                strategyBinpack,
+		PackingBias:             &packingBias,
+		ExcludeNodeSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"node-role.kubernetes.io/control-plane": ""}},
+		ResourceStrategies:      map[string]string{"memory": strategySpread},
+		PredictedUsageThreshold: 0.8,
+		CacheTTL:                metav1.Duration{Duration: time.Minute},
+		StalenessDecay:          stalenessDecayLinear,
+		MaxDataAge:              metav1.Duration{Duration: 3 * time.Minute},
+		ResyncPeriod:            metav1.Duration{Duration: 10 * time.Minute},
+		ResyncJitterFactor:      0.2,
+		DebugAddress:            "127.0.0.1:10261",
+		ReloadDebounce:          metav1.Duration{Duration: 2 * time.Second},
+		WeightSource:            weightSourceConfigMap,
+		PolicyNamespace:         "kube-system",
+		PolicyName:              "policy",
+		ConfigMapNamespace:      "kube-system",
+		ConfigMapName:           "weights",
+		ObserveOnly:             true,
+		StrictLabelValidation:   true,
+		FilterRules:             []PodFilterRule{{PodLabel: "a", PodValue: "b", NodeLabel: "c", NodeValue: "d"}},
+		FailOpen:                &failOpen,
+		NeutralScore:            40,
+		QueryHeaders:            map[string]string{"X-Scope-OrgID": "tenant-a"},
+		MetricScale:             map[string]float64{"netrx": 0.125},
+		PrometheusAddress:       "http://prom.example:9090",
+		TenantHeader:            "tenant-a",
+		CAFile:                  "/etc/ca.crt",
+		InsecureSkipVerify:      true,
+		ClientCertFile:          "/etc/tls.crt",
+		ClientKeyFile:           "/etc/tls.key",
+	}
+
+	// 反射自检：本测试自身必须填满每个导出字段，
+	// 新增字段而未更新上面的字面量时先在这里失败
+	originalValue := reflect.ValueOf(*original)
+	for i := 0; i < originalValue.NumField(); i++ {
+		field := originalValue.Type().Field(i)
+		if field.PkgPath != "" {
+			continue // 非导出字段
+		}
+		if originalValue.Field(i).IsZero() {
+			t.Fatalf("测试数据未覆盖字段 %s，请为其补充非零值", field.Name)
+		}
+	}
+
+	copied := original.DeepCopy()
+
+	// 反射检查：拷贝后每个导出字段都应保留非零值（漏拷即为零值）
+	copiedValue := reflect.ValueOf(*copied)
+	for i := 0; i < copiedValue.NumField(); i++ {
+		field := copiedValue.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		if copiedValue.Field(i).IsZero() {
+			t.Errorf("DeepCopy遗漏字段 %s（拷贝后为零值）", field.Name)
+		}
+	}
+	if !reflect.DeepEqual(original, copied) {
+		t.Errorf("DeepCopy结果与原配置不一致:\n原 %+v\n拷 %+v", original, copied)
+	}
+
+	// 改动原对象的引用类型字段，拷贝不应跟着变
+	original.DefaultWeights["cpu"] = 0.9
+	original.LabelWeights["cpu-prefer"]["cpu"] = 0.9
+	original.NamespaceWeights["team-a"]["cpu"] = 0.9
+	original.FallbackUsage["diskio"] = 0.9
+	original.ResourceQueryTimeouts["diskio"] = metav1.Duration{Duration: time.Second}
+	original.MetricScale["netrx"] = 1.0
+	original.MaxUsage["cpu"] = 0.5
+	original.QueryTemplates["cpu"] = "changed"
+	original.ResourceStrategies["memory"] = strategyBinpack
+	original.QueryHeaders["X-Scope-OrgID"] = "tenant-b"
+	original.FilterRules[0].PodLabel = "changed"
+	*original.FailOpen = false
+	*original.PackingBias = -0.5
+
+	if copied.DefaultWeights["cpu"] != 0.5 ||
+		copied.LabelWeights["cpu-prefer"]["cpu"] != 0.7 ||
+		copied.NamespaceWeights["team-a"]["cpu"] != 0.6 ||
+		copied.FallbackUsage["diskio"] != 0.5 ||
+		copied.ResourceQueryTimeouts["diskio"].Duration != 2*time.Second ||
+		copied.MetricScale["netrx"] != 0.125 ||
+		copied.MaxUsage["cpu"] != 0.95 ||
+		copied.QueryTemplates["cpu"] != "custom_cpu{instance=~\"{instance}\"}" ||
+		copied.ResourceStrategies["memory"] != strategySpread ||
+		copied.QueryHeaders["X-Scope-OrgID"] != "tenant-a" ||
+		copied.FilterRules[0].PodLabel != "a" ||
+		*copied.PackingBias != 0.5 ||
+		!*copied.FailOpen {
+		t.Error("改动原配置影响了DeepCopy结果，存在共享底层数据")
+	}
+}
+
+// TestJitteredPeriod 验证抖动周期落在[基准, 基准×(1+factor))区间内
+// 且确有随机性（多副本的重同步/刷新时刻由此错开）
+func TestJitteredPeriod(t *testing.T) {
+	base := time.Minute
+	factor := 0.5
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		got := jitteredPeriod(base, factor)
+		if got < base || got >= time.Duration(float64(base)*(1+factor)) {
+			t.Fatalf("jitteredPeriod = %v, 应在[%v, %v)区间", got, base, time.Duration(float64(base)*(1+factor)))
+		}
+		seen[got] = true
+	}
+	if len(seen) < 2 {
+		t.Error("抖动周期应具有随机性，100次采样全部相同")
+	}
+
+	// 未配置时回落默认周期与默认抖动系数
+	got := jitteredPeriod(0, 0)
+	upper := time.Duration(float64(defaultResyncPeriod) * (1 + defaultResyncJitterFactor))
+	if got < defaultResyncPeriod || got >= upper {
+		t.Errorf("默认抖动周期 = %v, 应在[%v, %v)区间", got, defaultResyncPeriod, upper)
+	}
+}
+
+// TestResyncValidation 验证重同步周期与抖动系数的配置校验
+func TestResyncValidation(t *testing.T) {
+	args := &DynamicWeightArgs{ResyncPeriod: metav1.Duration{Duration: -time.Second}}
+	if err := args.Validate(); err == nil {
+		t.Error("负的resyncPeriod应校验失败")
+	}
+	args = &DynamicWeightArgs{ResyncJitterFactor: 1.5}
+	if err := args.Validate(); err == nil {
+		t.Error("超过1.0的resyncJitterFactor应校验失败")
+	}
+	args = &DynamicWeightArgs{
+		ResyncPeriod:       metav1.Duration{Duration: time.Minute},
+		ResyncJitterFactor: 0.3,
+	}
+	if err := args.Validate(); err != nil {
+		t.Errorf("合法的重同步配置不应校验失败: %v", err)
+	}
+}
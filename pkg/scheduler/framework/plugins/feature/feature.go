@@ -22,6 +22,7 @@ package feature
 type Features struct {
 	EnableDRAAdminAccess                         bool
 	EnableDynamicResourceAllocation              bool
+	EnableDynamicWeight                          bool
 	EnableVolumeCapacityPriority                 bool
 	EnableNodeInclusionPolicyInPodTopologySpread bool
 	EnableMatchLabelKeysInPodTopologySpread      bool
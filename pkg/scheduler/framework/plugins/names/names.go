@@ -21,7 +21,8 @@ const (
 	DefaultBinder                   = "DefaultBinder"
 	DefaultPreemption               = "DefaultPreemption"
 	DynamicResources                = "DynamicResources"
-	DynamicWeight                   = "DynamicWeight" //新注册
+	DynamicWeight                   = "DynamicWeight"          //新注册
+	DynamicWeightPodFilter          = "DynamicWeightPodFilter" //新注册
 	ImageLocality                   = "ImageLocality"
 	InterPodAffinity                = "InterPodAffinity"
 	NodeAffinity                    = "NodeAffinity"
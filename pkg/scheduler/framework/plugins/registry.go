@@ -49,6 +49,7 @@ func NewInTreeRegistry() runtime.Registry {
 	fts := plfeature.Features{
 		EnableDRAAdminAccess:                         feature.DefaultFeatureGate.Enabled(features.DRAAdminAccess),
 		EnableDynamicResourceAllocation:              feature.DefaultFeatureGate.Enabled(features.DynamicResourceAllocation),
+		EnableDynamicWeight:                          feature.DefaultFeatureGate.Enabled(features.DynamicWeight),
 		EnableVolumeCapacityPriority:                 feature.DefaultFeatureGate.Enabled(features.VolumeCapacityPriority),
 		EnableNodeInclusionPolicyInPodTopologySpread: feature.DefaultFeatureGate.Enabled(features.NodeInclusionPolicyInPodTopologySpread),
 		EnableMatchLabelKeysInPodTopologySpread:      feature.DefaultFeatureGate.Enabled(features.MatchLabelKeysInPodTopologySpread),
@@ -57,8 +58,6 @@ func NewInTreeRegistry() runtime.Registry {
 		EnableSchedulingQueueHint:                    feature.DefaultFeatureGate.Enabled(features.SchedulerQueueingHints),
 		EnableAsyncPreemption:                        feature.DefaultFeatureGate.Enabled(features.SchedulerAsyncPreemption),
 		EnablePodLevelResources:                      feature.DefaultFeatureGate.Enabled(features.PodLevelResources),
-		//在插件注册代码中误引用了不存在的特性开关（Feature Gate），而自定义调度插件通常不需要特性门控。
-		//EnableDynamicWeight:                          feature.DefaultFeatureGate.Enabled(features.DynamicWeight), //注册dynamicweight
 	}
 
 	registry := runtime.Registry{
@@ -81,9 +80,9 @@ func NewInTreeRegistry() runtime.Registry {
 		defaultbinder.Name:                   defaultbinder.New,
 		defaultpreemption.Name:               runtime.FactoryAdapter(fts, defaultpreemption.New),
 		schedulinggates.Name:                 runtime.FactoryAdapter(fts, schedulinggates.New),
-		dynamicweight.Name:                   runtime.PluginFactory(dynamicweight.New),
-		//dynamicweight.Name:                 runtime.FactoryAdapter(fts, dynamicweight.New), //注册dynamicweight
-		//Kubernetes 要求插件工厂函数必须符合 PluginFactoryWithFts 类型（接收 framework.Framework 参数），但当前实现的 dynamicweight.New 函数使用的是 framework.Handle 参数，导致签名不匹配。
+		//dynamicweight受DynamicWeight特性门控：关闭时工厂返回空操作实现
+		dynamicweight.Name:          runtime.FactoryAdapter(fts, dynamicweight.New),
+		dynamicweight.PodFilterName: dynamicweight.NewPodFilter, //注册dynamicweight的Pod过滤插件（不门控）
 	}
 
 	return registry
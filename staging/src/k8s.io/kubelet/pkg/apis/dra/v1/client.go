@@ -0,0 +1,122 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// The client and server stubs below are hand-written instead of generated:
+// the v1 messages are aliases of the v1beta1 ones, so all that is needed is
+// invoking resp. serving the same methods under the v1 service name.
+
+// DRAPluginClient is the client API for the v1 DRAPlugin service.
+type DRAPluginClient interface {
+	NodePrepareResources(ctx context.Context, in *NodePrepareResourcesRequest, opts ...grpc.CallOption) (*NodePrepareResourcesResponse, error)
+	NodeUnprepareResources(ctx context.Context, in *NodeUnprepareResourcesRequest, opts ...grpc.CallOption) (*NodeUnprepareResourcesResponse, error)
+}
+
+type draPluginClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewDRAPluginClient(cc *grpc.ClientConn) DRAPluginClient {
+	return &draPluginClient{cc}
+}
+
+func (c *draPluginClient) NodePrepareResources(ctx context.Context, in *NodePrepareResourcesRequest, opts ...grpc.CallOption) (*NodePrepareResourcesResponse, error) {
+	out := new(NodePrepareResourcesResponse)
+	err := c.cc.Invoke(ctx, "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodePrepareResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *draPluginClient) NodeUnprepareResources(ctx context.Context, in *NodeUnprepareResourcesRequest, opts ...grpc.CallOption) (*NodeUnprepareResourcesResponse, error) {
+	out := new(NodeUnprepareResourcesResponse)
+	err := c.cc.Invoke(ctx, "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodeUnprepareResources", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DRAPluginServer is the server API for the v1 DRAPlugin service.
+type DRAPluginServer interface {
+	NodePrepareResources(context.Context, *NodePrepareResourcesRequest) (*NodePrepareResourcesResponse, error)
+	NodeUnprepareResources(context.Context, *NodeUnprepareResourcesRequest) (*NodeUnprepareResourcesResponse, error)
+}
+
+func RegisterDRAPluginServer(s *grpc.Server, srv DRAPluginServer) {
+	s.RegisterService(&_DRAPlugin_serviceDesc, srv)
+}
+
+func _DRAPlugin_NodePrepareResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodePrepareResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DRAPluginServer).NodePrepareResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodePrepareResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DRAPluginServer).NodePrepareResources(ctx, req.(*NodePrepareResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DRAPlugin_NodeUnprepareResources_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeUnprepareResourcesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DRAPluginServer).NodeUnprepareResources(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin/NodeUnprepareResources",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DRAPluginServer).NodeUnprepareResources(ctx, req.(*NodeUnprepareResourcesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _DRAPlugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "k8s.io.kubelet.pkg.apis.dra.v1.DRAPlugin",
+	HandlerType: (*DRAPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "NodePrepareResources",
+			Handler:    _DRAPlugin_NodePrepareResources_Handler,
+		},
+		{
+			MethodName: "NodeUnprepareResources",
+			Handler:    _DRAPlugin_NodeUnprepareResources_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2025 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	drapbv1beta1 "k8s.io/kubelet/pkg/apis/dra/v1beta1"
+)
+
+const (
+	// DRAPluginService needs to be listed in the "supported versions"
+	// array during plugin registration by a DRA plugin which provides
+	// an implementation of the v1 DRAPlugin service.
+	DRAPluginService = "v1.DRAPlugin"
+)
+
+// The v1 messages are identical to their v1beta1 counterparts, both as Go
+// structs and on the wire. They are declared as aliases so that the protobuf
+// definitions do not have to be duplicated; only the gRPC service name
+// differs between the two versions.
+type (
+	NodePrepareResourcesRequest    = drapbv1beta1.NodePrepareResourcesRequest
+	NodePrepareResourcesResponse   = drapbv1beta1.NodePrepareResourcesResponse
+	NodePrepareResourceResponse    = drapbv1beta1.NodePrepareResourceResponse
+	NodeUnprepareResourcesRequest  = drapbv1beta1.NodeUnprepareResourcesRequest
+	NodeUnprepareResourcesResponse = drapbv1beta1.NodeUnprepareResourcesResponse
+	NodeUnprepareResourceResponse  = drapbv1beta1.NodeUnprepareResourceResponse
+	Claim                          = drapbv1beta1.Claim
+	Device                         = drapbv1beta1.Device
+)
@@ -62,7 +62,7 @@ var _ = SIGDescribe("Container Restart", feature.CriProxy, framework.WithSerial(
 
 		ginkgo.It("Container restart backs off.", func(ctx context.Context) {
 			// 0s, 0s, 10s, 30s, 70s, 150s, 310s
-			doTest(ctx, f, 3, containerName, 7)
+			doTest(ctx, f, 3, containerName, 7, 5*time.Minute)
 		})
 	})
 
@@ -86,26 +86,194 @@ var _ = SIGDescribe("Container Restart", feature.CriProxy, framework.WithSerial(
 
 		ginkgo.It("Alternate restart backs off.", func(ctx context.Context) {
 			// 0s, 0s, 10s, 30s, 60s, 90s, 120s, 150s, 180s, 210s, 240s, 270s, 300s
-			doTest(ctx, f, 3, containerName, 13)
+			doTest(ctx, f, 3, containerName, 13, 30*time.Second)
+		})
+	})
+
+	ginkgo.Context("Container restart backoff resets after a successful run", func() {
+
+		// The shortened 30s cap makes the backoff entry expire after a
+		// 90s successful run; with the default 5m cap the reset would
+		// only be observable after more than ten minutes.
+		tempSetCurrentKubeletConfig(f, func(ctx context.Context, initialConfig *kubeletconfig.KubeletConfiguration) {
+			initialConfig.CrashLoopBackOff.MaxContainerRestartPeriod = &metav1.Duration{Duration: time.Duration(30 * time.Second)}
+			initialConfig.FeatureGates = map[string]bool{"KubeletCrashLoopBackOffMax": true}
+		})
+
+		ginkgo.BeforeEach(func() {
+			if err := resetCRIProxyInjector(e2eCriProxy); err != nil {
+				ginkgo.Skip("Skip the test since the CRI Proxy is undefined.")
+			}
+		})
+
+		ginkgo.AfterEach(func() {
+			err := resetCRIProxyInjector(e2eCriProxy)
+			framework.ExpectNoError(err)
+		})
+
+		ginkgo.It("Backoff restarts from the base delay after a successful run.", func(ctx context.Context) {
+			const failuresBeforeSuccess = 3
+			// Longer than twice the 30s max restart period, so the
+			// backoff entry expires while the container is running.
+			successRun := 90 * time.Second
+
+			pod := newFlippablePod(failuresBeforeSuccess, successRun)
+			pod = e2epod.NewPodClient(f).Create(ctx, pod)
+
+			// Wait past the failing phase, the successful run and two
+			// more failures so the post-reset delays are observable.
+			starts, podErr := collectRestartTimes(ctx, f, pod.Name, containerName, 10*time.Minute, failuresBeforeSuccess+3)
+			gomega.Expect(podErr).ShouldNot(gomega.HaveOccurred(), "Expected container to keep restarting after the successful run")
+
+			// Run failuresBeforeSuccess is the successful one; the run
+			// after it includes the 90s success in its interval. Without
+			// the reset the backoff would add the full 30s on top, with
+			// it the container restarts almost immediately.
+			resetIdx := failuresBeforeSuccess + 1
+			gomega.Expect(len(starts)).Should(gomega.BeNumerically(">", resetIdx), "Expected to observe a restart after the successful run")
+			if !starts[resetIdx].IsZero() && !starts[resetIdx-1].IsZero() {
+				interval := starts[resetIdx].Sub(starts[resetIdx-1].Time)
+				gomega.Expect(interval).Should(gomega.BeNumerically("<", successRun+20*time.Second),
+					fmt.Sprintf("delay after the successful run was %v, expected the backoff to restart from the base delay", interval-successRun))
+			}
+
+			// The following delay continues the fresh curve (~10s)
+			// instead of the pre-reset 30s cap.
+			if len(starts) > resetIdx+1 && !starts[resetIdx+1].IsZero() && !starts[resetIdx].IsZero() {
+				interval := starts[resetIdx+1].Sub(starts[resetIdx].Time)
+				gomega.Expect(interval).Should(gomega.BeNumerically("<", 25*time.Second),
+					fmt.Sprintf("second delay after the reset was %v, expected the curve to restart near 10s", interval))
+			}
+		})
+	})
+
+	ginkgo.Context("Init container restart backs off", func() {
+
+		ginkgo.BeforeEach(func() {
+			if err := resetCRIProxyInjector(e2eCriProxy); err != nil {
+				ginkgo.Skip("Skip the test since the CRI Proxy is undefined.")
+			}
+		})
+
+		ginkgo.AfterEach(func() {
+			err := resetCRIProxyInjector(e2eCriProxy)
+			framework.ExpectNoError(err)
+		})
+
+		ginkgo.It("Init container restart backs off.", func(ctx context.Context) {
+			// Same default backoff curve as a regular container:
+			// 0s, 0s, 10s, 30s, 70s, 150s, 310s
+			pod := newFailAlwaysPod(failAlwaysPodOptions{asInit: true})
+			doPodTest(ctx, f, pod, 3, containerName, 7, 5*time.Minute)
+		})
+	})
+
+	ginkgo.Context("Sidecar container restart backs off", func() {
+
+		ginkgo.BeforeEach(func() {
+			if err := resetCRIProxyInjector(e2eCriProxy); err != nil {
+				ginkgo.Skip("Skip the test since the CRI Proxy is undefined.")
+			}
+		})
+
+		ginkgo.AfterEach(func() {
+			err := resetCRIProxyInjector(e2eCriProxy)
+			framework.ExpectNoError(err)
+		})
+
+		ginkgo.It("Sidecar container restart backs off.", func(ctx context.Context) {
+			// Restartable sidecars follow the same default backoff curve:
+			// 0s, 0s, 10s, 30s, 70s, 150s, 310s
+			always := v1.ContainerRestartPolicyAlways
+			pod := newFailAlwaysPod(failAlwaysPodOptions{asInit: true, restartPolicy: &always})
+			doPodTest(ctx, f, pod, 3, containerName, 7, 5*time.Minute)
 		})
 	})
 })
 
-func doTest(ctx context.Context, f *framework.Framework, targetRestarts int, containerName string, maxRestarts int) {
+func doTest(ctx context.Context, f *framework.Framework, targetRestarts int, containerName string, maxRestarts int, maxBackoff time.Duration) {
+	doPodTest(ctx, f, newFailAlwaysPod(failAlwaysPodOptions{}), targetRestarts, containerName, maxRestarts, maxBackoff)
+}
+
+func doPodTest(ctx context.Context, f *framework.Framework, pod *v1.Pod, targetRestarts int, containerName string, maxRestarts int, maxBackoff time.Duration) {
 
-	pod := e2epod.NewPodClient(f).Create(ctx, newFailAlwaysPod())
-	podErr := e2epod.WaitForPodContainerToFail(ctx, f.ClientSet, f.Namespace.Name, pod.Name, 0, "CrashLoopBackOff", 1*time.Minute)
-	gomega.Expect(podErr).To(gomega.HaveOccurred())
+	// CrashLoopBackOff is only reported through the regular container
+	// statuses; for init containers and sidecars the restart count check
+	// below covers the same ground.
+	isInit := len(pod.Spec.InitContainers) > 0
 
-	// Hard wait 30 seconds for targetRestarts in the best case; longer timeout later will handle if infra was slow.
-	time.Sleep(30 * time.Second)
-	podErr = waitForContainerRestartedNTimes(ctx, f, f.Namespace.Name, pod.Name, containerName, 5*time.Minute, targetRestarts)
+	pod = e2epod.NewPodClient(f).Create(ctx, pod)
+	if !isInit {
+		podErr := e2epod.WaitForPodContainerToFail(ctx, f.ClientSet, f.Namespace.Name, pod.Name, 0, "CrashLoopBackOff", 1*time.Minute)
+		gomega.Expect(podErr).To(gomega.HaveOccurred())
+	}
+
+	// Collecting the start times has to happen while the restarts are
+	// still going on, the Pod status only remembers the most recent ones.
+	starts, podErr := collectRestartTimes(ctx, f, pod.Name, containerName, 6*time.Minute, targetRestarts)
 	gomega.Expect(podErr).ShouldNot(gomega.HaveOccurred(), "Expected container to repeatedly back off container failures")
 
 	r, err := extractObservedBackoff(ctx, f, pod.Name, containerName)
 	framework.ExpectNoError(err)
 
+	// Too many restarts means the backoff grew too fast, too few means the
+	// observed count was not read back correctly.
 	gomega.Expect(r).Should(gomega.BeNumerically("<=", maxRestarts))
+	gomega.Expect(r).Should(gomega.BeNumerically(">=", targetRestarts))
+
+	checkBackoffIntervals(starts, maxBackoff)
+}
+
+// checkBackoffIntervals verifies that the delays between consecutive restarts
+// follow the expected progression: after the two immediate restarts the
+// backoff doubles from 10s until it reaches maxBackoff. Timestamps have
+// second granularity and include the container runtime overhead, so only a
+// generous lower bound is asserted; growing too slowly is what this catches.
+func checkBackoffIntervals(starts []metav1.Time, maxBackoff time.Duration) {
+	expected := 10 * time.Second
+	for i := 2; i < len(starts); i++ {
+		if starts[i].IsZero() || starts[i-1].IsZero() {
+			continue
+		}
+		interval := starts[i].Sub(starts[i-1].Time)
+		lowerBound := expected / 2
+		gomega.Expect(interval).Should(gomega.BeNumerically(">=", lowerBound),
+			fmt.Sprintf("delay before restart %d was %v, expected roughly %v", i, interval, expected))
+		expected *= 2
+		if expected > maxBackoff {
+			expected = maxBackoff
+		}
+	}
+}
+
+// collectRestartTimes polls the Pod status until the container has restarted
+// target times and returns the observed start time of each run, indexed by
+// restart count. Runs which were never observed have a zero timestamp.
+func collectRestartTimes(ctx context.Context, f *framework.Framework, podName string, containerName string, timeout time.Duration, target int) ([]metav1.Time, error) {
+	starts := make(map[int32]metav1.Time)
+	conditionDesc := fmt.Sprintf("A container in pod %s restarted at least %d times", podName, target)
+	err := e2epod.WaitForPodCondition(ctx, f.ClientSet, f.Namespace.Name, podName, conditionDesc, timeout, func(pod *v1.Pod) (bool, error) {
+		cs, found := findContainerStatus(pod, containerName)
+		if !found {
+			return false, fmt.Errorf("could not find container %s in  pod %s", containerName, podName)
+		}
+		if t := cs.State.Terminated; t != nil {
+			starts[cs.RestartCount] = t.StartedAt
+		}
+		if t := cs.LastTerminationState.Terminated; t != nil && cs.RestartCount > 0 {
+			starts[cs.RestartCount-1] = t.StartedAt
+		}
+		return cs.RestartCount >= int32(target), nil
+	})
+	var series []metav1.Time
+	for i := int32(0); ; i++ {
+		start, observed := starts[i]
+		if !observed {
+			break
+		}
+		series = append(series, start)
+	}
+	return series, err
 }
 
 func extractObservedBackoff(ctx context.Context, f *framework.Framework, podName string, containerName string) (int32, error) {
@@ -124,32 +292,79 @@ func extractObservedBackoff(ctx context.Context, f *framework.Framework, podName
 	return r, errors.Errorf("Could not find container status for container %s in pod %s", containerName, podName)
 }
 
-func newFailAlwaysPod() *v1.Pod {
+// failAlwaysPodOptions controls where the always-failing container is placed
+// in the pod and how it behaves.
+type failAlwaysPodOptions struct {
+	// command overrides the container entrypoint. The default (none)
+	// exits immediately, which is what drives the restart backoff.
+	command []string
+	// asInit places the failing container in the init containers instead
+	// of the regular ones.
+	asInit bool
+	// restartPolicy is the container-level restart policy. Combined with
+	// asInit it turns the failing container into a restartable sidecar.
+	restartPolicy *v1.ContainerRestartPolicy
+}
+
+func newFailAlwaysPod(opts failAlwaysPodOptions) *v1.Pod {
 	podName := "container-restart" + string(uuid.NewUUID())
+	failing := v1.Container{
+		Name:            containerName,
+		Image:           imageutils.GetE2EImage(imageutils.BusyBox),
+		ImagePullPolicy: v1.PullIfNotPresent,
+		Command:         opts.command,
+		RestartPolicy:   opts.restartPolicy,
+	}
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: podName,
 		},
-		Spec: v1.PodSpec{
-			Containers: []v1.Container{
-				{
-					Name:            containerName,
-					Image:           imageutils.GetE2EImage(imageutils.BusyBox),
-					ImagePullPolicy: v1.PullIfNotPresent,
-				},
+	}
+	if opts.asInit {
+		// The pod still needs a regular container; one that just sleeps
+		// keeps the focus on the failing init container resp. sidecar.
+		pod.Spec.InitContainers = []v1.Container{failing}
+		pod.Spec.Containers = []v1.Container{
+			{
+				Name:            "main",
+				Image:           imageutils.GetE2EImage(imageutils.BusyBox),
+				ImagePullPolicy: v1.PullIfNotPresent,
+				Command:         []string{"sleep", "3600"},
 			},
-		},
+		}
+	} else {
+		pod.Spec.Containers = []v1.Container{failing}
 	}
 	return pod
 }
 
-func waitForContainerRestartedNTimes(ctx context.Context, f *framework.Framework, namespace string, podName string, containerName string, timeout time.Duration, target int) error {
-	conditionDesc := fmt.Sprintf("A container in pod %s restarted at least %d times", podName, target)
-	return e2epod.WaitForPodCondition(ctx, f.ClientSet, namespace, podName, conditionDesc, timeout, func(pod *v1.Pod) (bool, error) {
-		cs, found := podv1util.GetContainerStatus(pod.Status.ContainerStatuses, containerName)
-		if !found {
-			return false, fmt.Errorf("could not find container %s in  pod %s", containerName, podName)
-		}
-		return cs.RestartCount >= int32(target), nil
+// newFlippablePod returns a pod whose container fails failCount times, then
+// runs successfully for successRun before exiting cleanly, and fails again on
+// every run after that. The attempt counter lives on an emptyDir volume so it
+// survives container restarts.
+func newFlippablePod(failCount int, successRun time.Duration) *v1.Pod {
+	script := fmt.Sprintf(
+		"count=$(cat /data/count 2>/dev/null || echo 0); count=$((count+1)); echo $count > /data/count; "+
+			"if [ $count -le %d ]; then exit 1; fi; "+
+			"if [ $count -eq %d ]; then sleep %d; exit 0; fi; "+
+			"exit 1",
+		failCount, failCount+1, int(successRun.Seconds()))
+	pod := newFailAlwaysPod(failAlwaysPodOptions{command: []string{"sh", "-c", script}})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, v1.Volume{
+		Name:         "state",
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, v1.VolumeMount{
+		Name:      "state",
+		MountPath: "/data",
 	})
+	return pod
+}
+
+func findContainerStatus(pod *v1.Pod, containerName string) (v1.ContainerStatus, bool) {
+	cs, found := podv1util.GetContainerStatus(pod.Status.ContainerStatuses, containerName)
+	if !found {
+		cs, found = podv1util.GetContainerStatus(pod.Status.InitContainerStatuses, containerName)
+	}
+	return cs, found
 }